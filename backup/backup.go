@@ -0,0 +1,283 @@
+// Package backup splits files into chunks that are put into separate QR codes,
+// prints them on PDF pages and restores files from chunks given in any order
+//
+// Encode builds the matrices for PDF, Restorer reads chunks back from scanned pages with package scan;
+// cmd/qr is the command line tool doing both
+package backup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"image"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+	"github.com/rinnothing/qr-tools/scan"
+)
+
+var (
+	WrongFormatError    = errors.New("data is not a backup chunk")
+	WrongLevelError     = errors.New("backups need ErrorCorrectionLevel Q or H")
+	WrongChunkSizeError = errors.New("chunk size is too small")
+	TooManyChunksError  = errors.New("file needs too many chunks")
+	OtherFileError      = errors.New("chunk belongs to other file")
+	MissingChunksError  = errors.New("some chunks are missing")
+	HashMismatchError   = errors.New("restored file doesn't match its hash")
+)
+
+const (
+	// Level is the recommended ErrorCorrectionLevel for paper backups
+	Level = qr_tools.Q
+
+	formatVersion = 1
+	maxChunks     = 1<<16 - 1
+	// HeaderSize is the size of chunk header: format version, file id, index, total and SHA-256 of the file
+	HeaderSize = 1 + 8 + 2 + 2 + sha256.Size
+)
+
+// Chunk is a piece of file together with the information needed to restore it
+// Index starts with 0
+type Chunk struct {
+	FileID [8]byte
+	Index  int
+	Total  int
+	Hash   [sha256.Size]byte
+	Data   []byte
+}
+
+// MarshalBinary returns header followed by the data
+func (c Chunk) MarshalBinary() ([]byte, error) {
+	if c.Total < 1 || c.Total > maxChunks || c.Index < 0 || c.Index >= c.Total {
+		return nil, WrongFormatError
+	}
+
+	data := make([]byte, 0, HeaderSize+len(c.Data))
+	data = append(data, formatVersion)
+	data = append(data, c.FileID[:]...)
+	data = binary.BigEndian.AppendUint16(data, uint16(c.Index))
+	data = binary.BigEndian.AppendUint16(data, uint16(c.Total))
+	data = append(data, c.Hash[:]...)
+	return append(data, c.Data...), nil
+}
+
+// ParseChunk parses chunk made by MarshalBinary
+func ParseChunk(data []byte) (Chunk, error) {
+	if len(data) < HeaderSize || data[0] != formatVersion {
+		return Chunk{}, WrongFormatError
+	}
+
+	c := Chunk{
+		Index: int(binary.BigEndian.Uint16(data[9:11])),
+		Total: int(binary.BigEndian.Uint16(data[11:13])),
+		Data:  data[HeaderSize:],
+	}
+	copy(c.FileID[:], data[1:9])
+	copy(c.Hash[:], data[13:HeaderSize])
+
+	if c.Total < 1 || c.Index >= c.Total {
+		return Chunk{}, WrongFormatError
+	}
+	return c, nil
+}
+
+// Split splits file into chunks with at most chunkSize bytes of data
+// file id is taken from the hash, so the same file always gets the same id
+func Split(file []byte, chunkSize int) ([]Chunk, error) {
+	if chunkSize < 1 {
+		return nil, WrongChunkSizeError
+	}
+
+	total := max((len(file)+chunkSize-1)/chunkSize, 1)
+	if total > maxChunks {
+		return nil, TooManyChunksError
+	}
+
+	hash := sha256.Sum256(file)
+	chunks := make([]Chunk, total)
+	for i := range chunks {
+		chunks[i] = Chunk{Index: i, Total: total, Hash: hash, Data: file[i*chunkSize : min((i+1)*chunkSize, len(file))]}
+		copy(chunks[i].FileID[:], hash[:8])
+	}
+
+	return chunks, nil
+}
+
+// ChunkSize returns the greatest size of chunk data that fits chosen version and ErrorCorrectionLevel in byte mode
+func ChunkSize(lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) (int, error) {
+	capacity, err := qr_tools.DataCapacity(lvl, ver)
+	if err != nil {
+		return 0, err
+	}
+	// mode indicator and character count indicator
+	overhead, err := qr_tools.NewByteSegment(nil).BitLength(ver)
+	if err != nil {
+		return 0, err
+	}
+
+	size := int(capacity-overhead)/8 - HeaderSize
+	if size < 1 {
+		return 0, WrongChunkSizeError
+	}
+	return size, nil
+}
+
+// split splits file into chunks that fit chosen version and ErrorCorrectionLevel
+// throws WrongLevelError if lvl is lower than Level
+func split(file []byte, lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) ([]Chunk, error) {
+	if lvl < Level {
+		return nil, WrongLevelError
+	}
+
+	size, err := ChunkSize(lvl, ver)
+	if err != nil {
+		return nil, err
+	}
+	return Split(file, size)
+}
+
+// marshal marshals every chunk with ByteMarshaler
+func marshal(chunks []Chunk, lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) ([][]byte, error) {
+	marshaler := qr_tools.NewByteMarshaler(lvl, ver)
+	codes := make([][]byte, len(chunks))
+	for i, c := range chunks {
+		data, err := c.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		if codes[i], err = marshaler.MarshalBytes(data); err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
+
+// Marshal splits file into chunks and marshals every one of them with ByteMarshaler
+// throws WrongLevelError if lvl is lower than Level
+func Marshal(file []byte, lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) ([][]byte, error) {
+	chunks, err := split(file, lvl, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(chunks, lvl, ver)
+}
+
+// Encode splits file into chunks and encodes every one of them into the matrix, matrices[i] is the matrix of chunks[i]
+// throws WrongLevelError if lvl is lower than Level
+func Encode(file []byte, lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) (chunks []Chunk, matrices [][][]bool, err error) {
+	if chunks, err = split(file, lvl, ver); err != nil {
+		return nil, nil, err
+	}
+	codes, err := marshal(chunks, lvl, ver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	matrices = make([][][]bool, len(codes))
+	for i, data := range codes {
+		if matrices[i], err = qr_tools.Matrix(data, lvl, ver); err != nil {
+			return nil, nil, err
+		}
+	}
+	return chunks, matrices, nil
+}
+
+// A Restorer collects chunks of a single file in any order
+type Restorer struct {
+	fileID [8]byte
+	hash   [sha256.Size]byte
+	chunks [][]byte
+}
+
+// NewRestorer returns empty Restorer, the first added chunk chooses the file
+func NewRestorer() *Restorer {
+	return &Restorer{}
+}
+
+// Add adds chunk, repeated chunks are ignored
+// throws WrongFormatError if index or total are out of range
+// and OtherFileError if chunk doesn't belong to the file of previous chunks
+func (r *Restorer) Add(c Chunk) error {
+	if c.Total < 1 || c.Total > maxChunks || c.Index < 0 || c.Index >= c.Total {
+		return WrongFormatError
+	}
+	if r.chunks == nil {
+		r.fileID, r.hash = c.FileID, c.Hash
+		r.chunks = make([][]byte, c.Total)
+	}
+	if c.FileID != r.fileID || c.Hash != r.hash || c.Total != len(r.chunks) {
+		return OtherFileError
+	}
+
+	if r.chunks[c.Index] == nil {
+		r.chunks[c.Index] = bytes.Clone(c.Data)
+		if r.chunks[c.Index] == nil {
+			r.chunks[c.Index] = []byte{}
+		}
+	}
+	return nil
+}
+
+// AddMarshaled unmarshals data made by Marshal and adds the chunk
+func (r *Restorer) AddMarshaled(data []byte, ver qr_tools.QRVersion) error {
+	payload, err := qr_tools.NewQRUnmarshaler(ver).UnmarshalToBytes(data)
+	if err != nil {
+		return err
+	}
+
+	c, err := ParseChunk(payload)
+	if err != nil {
+		return err
+	}
+	return r.Add(c)
+}
+
+// AddImage scans the image, for example a photo of backup page, and adds chunks of all the codes found
+// returns the number of codes read, the codes that aren't chunks of the file are reported by the error
+// throws scan.NoCodeError if there are no codes in the image
+func (r *Restorer) AddImage(img image.Image) (int, error) {
+	codes, err := scan.Scan(img)
+	if err != nil {
+		return 0, err
+	}
+
+	var first error
+	for _, c := range codes {
+		if err := r.AddMarshaled(c.Data, c.Version); err != nil && first == nil {
+			first = err
+		}
+	}
+	return len(codes), first
+}
+
+// Missing returns sorted indexes of chunks that weren't added yet
+// nil is returned if no chunks were added at all, since the total is unknown
+func (r *Restorer) Missing() []int {
+	if r.chunks == nil {
+		return nil
+	}
+
+	missing := make([]int, 0)
+	for i, c := range r.chunks {
+		if c == nil {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// File joins the chunks and verifies the hash of the result
+// throws MissingChunksError if not all chunks were added
+func (r *Restorer) File() ([]byte, error) {
+	if r.chunks == nil || len(r.Missing()) != 0 {
+		return nil, MissingChunksError
+	}
+
+	file := bytes.Join(r.chunks, nil)
+	if sha256.Sum256(file) != r.hash {
+		return nil, HashMismatchError
+	}
+	return file, nil
+}
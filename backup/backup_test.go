@@ -0,0 +1,245 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"reflect"
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+	"github.com/rinnothing/qr-tools/render"
+	"github.com/rinnothing/qr-tools/scan"
+)
+
+func testFile(n int) []byte {
+	file := make([]byte, n)
+	for i := range file {
+		file[i] = byte(i * 7)
+	}
+	return file
+}
+
+func TestChunk_MarshalBinary(t *testing.T) {
+	chunks, err := Split(testFile(10), 4)
+	if err != nil {
+		t.Fatalf("Failed to split: %v", err)
+	}
+	if len(chunks) != 3 || len(chunks[2].Data) != 2 {
+		t.Fatalf("File is split into %d chunks", len(chunks))
+	}
+
+	for _, c := range chunks {
+		data, err := c.MarshalBinary()
+		if err != nil || len(data) != HeaderSize+len(c.Data) {
+			t.Fatalf("Failed to marshal %v: %v", c, err)
+		}
+
+		parsed, err := ParseChunk(data)
+		if err != nil || !reflect.DeepEqual(parsed, c) {
+			t.Errorf("Parsed %v instead of %v: %v", parsed, c, err)
+		}
+	}
+
+	for _, data := range [][]byte{nil, make([]byte, HeaderSize), append([]byte{1}, make([]byte, HeaderSize)...)} {
+		if _, err := ParseChunk(data); err != WrongFormatError {
+			t.Errorf("Wrong chunk %x is parsed with %v", data, err)
+		}
+	}
+}
+
+func TestChunkSize(t *testing.T) {
+	// version 10 with level Q holds 154 codewords, 3 of them go to mode and length
+	if size, err := ChunkSize(qr_tools.Q, 10); err != nil || size != 151-HeaderSize {
+		t.Errorf("Chunk size is %d instead of %d: %v", size, 151-HeaderSize, err)
+	}
+
+	if _, err := ChunkSize(qr_tools.H, 2); err != WrongChunkSizeError {
+		t.Errorf("Version too small for header is accepted with %v", err)
+	}
+}
+
+func TestRestorer(t *testing.T) {
+	file := testFile(1000)
+	codes, err := Marshal(file, Level, 10)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	r := NewRestorer()
+	if r.Missing() != nil {
+		t.Errorf("Empty restorer knows missing chunks")
+	}
+
+	// chunks come in reverse order and the last one is missing
+	for i := len(codes) - 1; i > 0; i-- {
+		if err := r.AddMarshaled(codes[i], 10); err != nil {
+			t.Fatalf("Failed to add chunk %d: %v", i, err)
+		}
+	}
+	if missing := r.Missing(); !reflect.DeepEqual(missing, []int{0}) {
+		t.Errorf("Missing chunks are %v instead of [0]", missing)
+	}
+	if _, err := r.File(); err != MissingChunksError {
+		t.Errorf("Incomplete file is restored with %v", err)
+	}
+
+	// repeated chunks are fine
+	for _, code := range codes[:2] {
+		if err := r.AddMarshaled(code, 10); err != nil {
+			t.Fatalf("Failed to add chunk: %v", err)
+		}
+	}
+	restored, err := r.File()
+	if err != nil || !bytes.Equal(restored, file) {
+		t.Errorf("Restored file differs: %v", err)
+	}
+
+	other, _ := Split([]byte("other"), 100)
+	if err := r.Add(other[0]); err != OtherFileError {
+		t.Errorf("Chunk of other file is added with %v", err)
+	}
+
+	if _, err := Marshal(file, qr_tools.M, 10); err != WrongLevelError {
+		t.Errorf("File is marshaled with level M: %v", err)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	file := testFile(300)
+	chunks, matrices, err := Encode(file, Level, 5)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	if len(chunks) != len(matrices) || len(matrices[0]) != 37 {
+		t.Fatalf("Got %d chunks and %d matrices of %d modules", len(chunks), len(matrices), len(matrices[0]))
+	}
+
+	r := NewRestorer()
+	for i, m := range matrices {
+		data, _, ver, err := qr_tools.Decode(m)
+		if err != nil {
+			t.Fatalf("Failed to decode matrix %d: %v", i, err)
+		}
+		if err := r.AddMarshaled(data, ver); err != nil {
+			t.Fatalf("Failed to add chunk %d: %v", i, err)
+		}
+	}
+	if restored, err := r.File(); err != nil || !bytes.Equal(restored, file) {
+		t.Errorf("Restored file differs: %v", err)
+	}
+
+	if _, _, err := Encode(file, qr_tools.L, 5); err != WrongLevelError {
+		t.Errorf("File is encoded with level L: %v", err)
+	}
+}
+
+// pageImage renders the codes with their captions onto the page, columns codes in a row
+func pageImage(t *testing.T, chunks []Chunk, matrices [][][]bool, columns int) *image.RGBA {
+	t.Helper()
+	opts := render.DefaultOptions()
+	opts.Scale = 3
+
+	var images []*image.RGBA
+	for i, m := range matrices {
+		opts.Caption = chunks[i].Caption()
+		img, err := render.Image(m, opts)
+		if err != nil {
+			t.Fatalf("Failed to render chunk %d: %v", i, err)
+		}
+		images = append(images, img)
+	}
+
+	w, h := images[0].Bounds().Dx(), images[0].Bounds().Dy()
+	rows := (len(images) + columns - 1) / columns
+	page := image.NewRGBA(image.Rect(0, 0, w*columns, h*rows))
+	draw.Draw(page, page.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for i, img := range images {
+		draw.Draw(page, img.Bounds().Add(image.Pt(w*(i%columns), h*(i/columns))), img, image.Point{}, draw.Src)
+	}
+	return page
+}
+
+func TestRestorer_AddImage(t *testing.T) {
+	file := testFile(200)
+	chunks, matrices, err := Encode(file, Level, 6)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+
+	// two pages, the second one is scanned first
+	r := NewRestorer()
+	half := len(chunks) / 2
+	for _, page := range []*image.RGBA{pageImage(t, chunks[half:], matrices[half:], 3), pageImage(t, chunks[:half], matrices[:half], 3)} {
+		if _, err := r.AddImage(page); err != nil {
+			t.Fatalf("Failed to add page: %v", err)
+		}
+	}
+	if restored, err := r.File(); err != nil || !bytes.Equal(restored, file) {
+		t.Errorf("Restored file differs: %v", err)
+	}
+
+	otherChunks, otherMatrices, _ := Encode([]byte("other"), Level, 6)
+	if n, err := r.AddImage(pageImage(t, otherChunks, otherMatrices, 1)); n != 1 || err != OtherFileError {
+		t.Errorf("Code of other file is added with %d, %v", n, err)
+	}
+	if _, err := r.AddImage(image.NewGray(image.Rect(0, 0, 50, 50))); !errors.Is(err, scan.NoCodeError) {
+		t.Errorf("Blank page is added with %v", err)
+	}
+}
+
+func TestRestorer_WrongChunk(t *testing.T) {
+	chunks, _ := Split(testFile(10), 4)
+
+	r := NewRestorer()
+	for _, broken := range []func(c *Chunk){
+		func(c *Chunk) { c.Index = c.Total },
+		func(c *Chunk) { c.Index = -1 },
+		func(c *Chunk) { c.Total = 0 },
+		func(c *Chunk) { c.Total = -3 },
+	} {
+		c := chunks[0]
+		broken(&c)
+		if err := r.Add(c); err != WrongFormatError {
+			t.Errorf("Chunk %d/%d is added with %v", c.Index, c.Total, err)
+		}
+	}
+
+	// wrong chunks don't choose the file
+	for _, c := range chunks {
+		if err := r.Add(c); err != nil {
+			t.Fatalf("Failed to add chunk %d: %v", c.Index, err)
+		}
+	}
+	if file, err := r.File(); err != nil || !bytes.Equal(file, testFile(10)) {
+		t.Errorf("Restored file differs: %v", err)
+	}
+}
+
+func TestRestorer_HashMismatch(t *testing.T) {
+	chunks, _ := Split(testFile(10), 4)
+	chunks[1].Data = []byte{0, 0, 0, 0}
+
+	r := NewRestorer()
+	for _, c := range chunks {
+		_ = r.Add(c)
+	}
+	if _, err := r.File(); err != HashMismatchError {
+		t.Errorf("Corrupted file is restored with %v", err)
+	}
+}
+
+func TestSplit_Empty(t *testing.T) {
+	chunks, err := Split(nil, 10)
+	if err != nil || len(chunks) != 1 {
+		t.Fatalf("Empty file is split into %v: %v", chunks, err)
+	}
+
+	r := NewRestorer()
+	_ = r.Add(chunks[0])
+	if file, err := r.File(); err != nil || len(file) != 0 {
+		t.Errorf("Empty file is restored as %x: %v", file, err)
+	}
+}
@@ -0,0 +1,50 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rinnothing/qr-tools/render"
+)
+
+var (
+	MatricesCountError = errors.New("number of matrices doesn't match number of chunks")
+)
+
+// pageMargin is the margin around the grid of codes on backup pages in millimetres
+const pageMargin = 15
+
+// Caption returns the text printed under the code of the chunk: file id and chunk number counted from 1
+func (c Chunk) Caption() string {
+	return fmt.Sprintf("%x %d/%d", c.FileID, c.Index+1, c.Total)
+}
+
+// PageSheet returns sheet of columns × rows codes on the page with 15 mm margins
+func PageSheet(page render.Page, columns, rows int) render.Sheet {
+	w, h := (page.Width-2*pageMargin)/float64(columns), (page.Height-2*pageMargin)/float64(rows)
+	return render.Sheet{
+		Page: page, Left: pageMargin, Top: pageMargin,
+		Width: w, Height: h, PitchX: w, PitchY: h,
+		Columns: columns, Rows: rows,
+	}
+}
+
+// DefaultPDFOptions returns options with render.DefaultOptions and 3 × 4 codes on the page
+func DefaultPDFOptions(page render.Page) render.PDFOptions {
+	return render.PDFOptions{Options: render.DefaultOptions(), Sheet: PageSheet(page, 3, 4)}
+}
+
+// PDF prints codes of the chunks row by row with their captions, matrices[i] is the matrix of chunks[i]
+// throws MatricesCountError if there are not as many matrices as chunks
+func PDF(chunks []Chunk, matrices [][][]bool, opts render.PDFOptions) ([]byte, error) {
+	if len(chunks) != len(matrices) {
+		return nil, MatricesCountError
+	}
+
+	labels := make([]render.Label, len(chunks))
+	for i, c := range chunks {
+		labels[i] = render.Label{Modules: matrices[i], Caption: c.Caption()}
+	}
+
+	return render.PDF(labels, opts)
+}
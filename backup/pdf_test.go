@@ -0,0 +1,39 @@
+package backup
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rinnothing/qr-tools/render"
+)
+
+func TestChunk_Caption(t *testing.T) {
+	chunks, _ := Split(testFile(10), 4)
+	chunks[1].FileID = [8]byte{0xde, 0xad, 0xbe, 0xef, 0, 1, 2, 3}
+	if caption := chunks[1].Caption(); caption != "deadbeef00010203 2/3" {
+		t.Errorf("Caption is %q instead of %q", caption, "deadbeef00010203 2/3")
+	}
+}
+
+func TestPDF(t *testing.T) {
+	chunks, matrices, err := Encode(testFile(190), Level, 5)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	if len(chunks) != 13 {
+		t.Fatalf("File is split into %d chunks instead of 13", len(chunks))
+	}
+
+	// 13 codes don't fit a single page of 12
+	pdf, err := PDF(chunks, matrices, DefaultPDFOptions(render.A4))
+	if err != nil {
+		t.Fatalf("Failed to print: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) || !bytes.Contains(pdf, []byte("/Count 2")) {
+		t.Errorf("Backup isn't printed on 2 pages")
+	}
+
+	if _, err := PDF(chunks, matrices[1:], DefaultPDFOptions(render.Letter)); err != MatricesCountError {
+		t.Errorf("Chunks without matrices are printed with %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// Package base45 implements Base45 encoding (RFC 9285)
+// that turns binary data into characters of QR alphanumeric mode
+package base45
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	WrongCharacterError = errors.New("character is not in base45 alphabet")
+	WrongLengthError    = errors.New("wrong length of base45 string")
+	OverflowError       = errors.New("base45 chunk is too big")
+)
+
+// alphabet is the same as the character set of QR alphanumeric mode
+const alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// EncodedLen returns length of base45 encoding of n bytes
+func EncodedLen(n int) int {
+	return n/2*3 + n%2*2
+}
+
+// Encode encodes src, every two bytes are turned into three characters, the last single byte into two
+func Encode(src []byte) string {
+	sb := strings.Builder{}
+	sb.Grow(EncodedLen(len(src)))
+
+	for i := 0; i+1 < len(src); i += 2 {
+		n := int(src[i])<<8 | int(src[i+1])
+		sb.WriteByte(alphabet[n%45])
+		sb.WriteByte(alphabet[n/45%45])
+		sb.WriteByte(alphabet[n/45/45])
+	}
+	if len(src)%2 == 1 {
+		n := int(src[len(src)-1])
+		sb.WriteByte(alphabet[n%45])
+		sb.WriteByte(alphabet[n/45])
+	}
+
+	return sb.String()
+}
+
+// Decode decodes base45 string
+// throws WrongLengthError if length of str leaves a single character at the end
+func Decode(str string) ([]byte, error) {
+	if len(str)%3 == 1 {
+		return nil, WrongLengthError
+	}
+
+	dst := make([]byte, 0, len(str)/3*2+len(str)%3/2)
+	for i := 0; i < len(str); i += 3 {
+		end := min(i+3, len(str))
+
+		n, weight := 0, 1
+		for j := i; j < end; j++ {
+			v := strings.IndexByte(alphabet, str[j])
+			if v == -1 {
+				return nil, WrongCharacterError
+			}
+			n += v * weight
+			weight *= 45
+		}
+
+		if end-i == 3 {
+			if n > 0xffff {
+				return nil, OverflowError
+			}
+			dst = append(dst, byte(n>>8), byte(n))
+		} else {
+			if n > 0xff {
+				return nil, OverflowError
+			}
+			dst = append(dst, byte(n))
+		}
+	}
+
+	return dst, nil
+}
@@ -0,0 +1,52 @@
+package base45
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	// examples from RFC 9285
+	cases := []struct {
+		src      string
+		expected string
+	}{
+		{"AB", "BB8"},
+		{"Hello!!", "%69 VD92EX0"},
+		{"base-45", "UJCLQE7W581"},
+		{"ietf!", "QED8WEX0"},
+		{"", ""},
+	}
+
+	for _, c := range cases {
+		if s := Encode([]byte(c.src)); s != c.expected {
+			t.Errorf("Encoded %q as %q instead of %q", c.src, s, c.expected)
+		}
+		if len(Encode([]byte(c.src))) != EncodedLen(len(c.src)) {
+			t.Errorf("EncodedLen of %q is wrong", c.src)
+		}
+
+		decoded, err := Decode(c.expected)
+		if err != nil || !bytes.Equal(decoded, []byte(c.src)) {
+			t.Errorf("Decoded %q as %q instead of %q: %v", c.expected, decoded, c.src, err)
+		}
+	}
+}
+
+func TestDecode_Wrong(t *testing.T) {
+	cases := []struct {
+		str      string
+		expected error
+	}{
+		{"GGW", OverflowError},
+		{"ZZZ", OverflowError},
+		{"a12", WrongCharacterError},
+		{"BB8B", WrongLengthError},
+	}
+
+	for _, c := range cases {
+		if _, err := Decode(c.str); err != c.expected {
+			t.Errorf("Decoding %q returned %v instead of %v", c.str, err, c.expected)
+		}
+	}
+}
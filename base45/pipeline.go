@@ -0,0 +1,116 @@
+package base45
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strings"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+var (
+	WrongPrefixError   = errors.New("data doesn't start with the prefix")
+	NotCompressedError = errors.New("data isn't zlib compressed")
+	TooLongError       = errors.New("packed data doesn't fit into chosen qr version")
+)
+
+// Pack compresses data with zlib and encodes it with base45
+// prefix (e.g. "HC1:") is put before the encoded data, it should consist of alphanumeric mode characters
+func Pack(prefix string, data []byte) (string, error) {
+	buf := bytes.Buffer{}
+	w, err := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err = w.Write(data); err != nil {
+		return "", err
+	}
+	if err = w.Close(); err != nil {
+		return "", err
+	}
+
+	return prefix + Encode(buf.Bytes()), nil
+}
+
+// decode cuts prefix from str and decodes the rest with base45
+func decode(prefix string, str string) ([]byte, error) {
+	str, found := strings.CutPrefix(str, prefix)
+	if !found {
+		return nil, WrongPrefixError
+	}
+
+	return Decode(str)
+}
+
+// inflate decompresses zlib data
+// throws NotCompressedError if data doesn't start with zlib header
+func inflate(compressed []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, NotCompressedError
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// Unpack reverses Pack
+// throws NotCompressedError if the data isn't zlib compressed
+func Unpack(prefix string, str string) ([]byte, error) {
+	compressed, err := decode(prefix, str)
+	if err != nil {
+		return nil, err
+	}
+
+	return inflate(compressed)
+}
+
+// UnpackOptional is Unpack that returns data without zlib header as it is,
+// it's meant for formats where compression is optional (e.g. HC1)
+func UnpackOptional(prefix string, str string) ([]byte, error) {
+	data, err := decode(prefix, str)
+	if err != nil {
+		return nil, err
+	}
+
+	inflated, err := inflate(data)
+	if err == NotCompressedError {
+		return data, nil
+	}
+	return inflated, err
+}
+
+// Marshal packs data and marshals it with AlphanumericMarshaler
+// throws TooLongError if packed data doesn't fit into chosen version and ErrorCorrectionLevel
+func Marshal(prefix string, data []byte, lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) ([]byte, error) {
+	str, err := Pack(prefix, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// version and level are validated first, so that SmallestVersion fails only on too long data
+	if _, err = qr_tools.DataCapacity(lvl, ver); err != nil {
+		return nil, err
+	}
+	segment, err := qr_tools.NewAlphanumericSegment(str)
+	if err != nil {
+		return nil, err
+	}
+	if smallest, err := qr_tools.SmallestVersion([]qr_tools.Segment{segment}, lvl); err != nil || smallest > ver {
+		return nil, TooLongError
+	}
+
+	return qr_tools.NewAlphanumericMarshaler(lvl, ver).MarshalString(str)
+}
+
+// Unmarshal unmarshals data made by Marshal and unpacks it
+func Unmarshal(prefix string, data []byte, ver qr_tools.QRVersion) ([]byte, error) {
+	str, err := qr_tools.NewQRUnmarshaler(ver).UnmarshalToString(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return Unpack(prefix, str)
+}
@@ -0,0 +1,73 @@
+package base45
+
+import (
+	"bytes"
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+func TestPack(t *testing.T) {
+	data := bytes.Repeat([]byte("\xd2\x84\x43\xa1\x01\x26\xa0"), 40)
+
+	str, err := Pack("HC1:", data)
+	if err != nil {
+		t.Fatalf("Failed to pack: %v", err)
+	}
+	if len(str) >= EncodedLen(len(data)) {
+		t.Errorf("Packed string takes %d characters, without compression it takes %d", len(str), EncodedLen(len(data)))
+	}
+
+	unpacked, err := Unpack("HC1:", str)
+	if err != nil || !bytes.Equal(unpacked, data) {
+		t.Errorf("Unpacked %x instead of %x: %v", unpacked, data, err)
+	}
+
+	// compression is optional only when it's asked for
+	if _, err = Unpack("HC1:", "HC1:"+Encode([]byte("ietf!"))); err != NotCompressedError {
+		t.Errorf("Uncompressed data is unpacked with %v", err)
+	}
+	unpacked, err = UnpackOptional("HC1:", "HC1:"+Encode([]byte("ietf!")))
+	if err != nil || string(unpacked) != "ietf!" {
+		t.Errorf("Uncompressed data is unpacked as %q: %v", unpacked, err)
+	}
+	unpacked, err = UnpackOptional("HC1:", str)
+	if err != nil || !bytes.Equal(unpacked, data) {
+		t.Errorf("Optionally unpacked %x instead of %x: %v", unpacked, data, err)
+	}
+
+	if _, err = Unpack("HC1:", str[4:]); err != WrongPrefixError {
+		t.Errorf("Data without prefix is unpacked with %v", err)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	data := bytes.Repeat([]byte("\xd2\x84\x43\xa1\x01\x26\xa0"), 40)
+
+	str, _ := Pack("HC1:", data)
+	packed, err := qr_tools.NewAlphanumericSegment(str)
+	if err != nil {
+		t.Fatalf("Packed data doesn't fit alphanumeric mode: %v", err)
+	}
+	ver, _ := qr_tools.SmallestVersion([]qr_tools.Segment{packed}, qr_tools.M)
+	if byteVer, _ := qr_tools.SmallestVersion([]qr_tools.Segment{qr_tools.NewByteSegment(data)}, qr_tools.M); byteVer <= ver {
+		t.Errorf("Packed data takes version %d, but byte mode takes %d", ver, byteVer)
+	}
+
+	marshaled, err := Marshal("HC1:", data, qr_tools.M, ver)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	unmarshaled, err := Unmarshal("HC1:", marshaled, ver)
+	if err != nil || !bytes.Equal(unmarshaled, data) {
+		t.Errorf("Unmarshaled %x instead of %x: %v", unmarshaled, data, err)
+	}
+
+	if _, err = Marshal("HC1:", data, qr_tools.M, ver-1); err != TooLongError {
+		t.Errorf("Data is marshaled into too small version with %v", err)
+	}
+	if _, err = Marshal("HC1:", data, qr_tools.M, 41); err == nil {
+		t.Errorf("Data is marshaled into version 41")
+	}
+}
@@ -0,0 +1,209 @@
+package qr_tools
+
+import (
+	"errors"
+)
+
+var (
+	bitBeyondError  = errors.New("bit number goes beyond number of bytes")
+	bitOverrunError = errors.New("not enough bits left to read")
+)
+
+// BitWriter is a structure that
+// helps to connect long sequences of bits
+//
+// note that n should always be not greater than len(data) * 8
+// and bits of the last byte beyond n are always zero
+type BitWriter struct {
+	data []byte
+	n    uint
+}
+
+// NewBitWriter creates empty BitWriter
+func NewBitWriter() *BitWriter {
+	return &BitWriter{data: make([]byte, 0), n: 0}
+}
+
+// WriteBits appends n lowest bits of value, starting from the most significant of them
+// if n is greater than 64 WriteBits appends just 64 bits
+func (bw *BitWriter) WriteBits(value uint64, n uint) {
+	if n == 0 {
+		return
+	}
+	n = min(n, 64)
+	if n < 64 {
+		value &= 1<<n - 1
+	}
+
+	// filling the rest of the last byte
+	if free := (8 - bw.n%8) % 8; free != 0 {
+		take := min(free, n)
+		n -= take
+		bw.data[len(bw.data)-1] |= byte(value>>n) << (free - take)
+		bw.n += take
+	}
+
+	// putting whole bytes, value is masked so there is no need to mask them
+	for ; n >= 8; bw.n += 8 {
+		n -= 8
+		bw.data = append(bw.data, byte(value>>n))
+	}
+
+	if n > 0 {
+		bw.data = append(bw.data, byte(value<<(8-n)))
+		bw.n += n
+	}
+}
+
+// WriteBytes appends all the bytes of data
+// it's a lot faster when BitWriter is aligned to byte
+func (bw *BitWriter) WriteBytes(data []byte) {
+	if bw.n%8 == 0 {
+		bw.data = append(bw.data, data...)
+		bw.n += uint(len(data)) * 8
+		return
+	}
+
+	for _, b := range data {
+		bw.WriteBits(uint64(b), 8)
+	}
+}
+
+// AlignToByte appends zero bits until the length is a multiple of 8
+func (bw *BitWriter) AlignToByte() {
+	bw.WriteBits(0, (8-bw.n%8)%8)
+}
+
+// Len returns the number of bits written
+func (bw *BitWriter) Len() uint {
+	return bw.n
+}
+
+// Bytes gives out written data, the last byte is padded with zeroes
+func (bw *BitWriter) Bytes() []byte {
+	return bw.getData()
+}
+
+// appendByte appends n bits to the sequence of bits inside BitWriter
+// if n is greater than 8 appendByte append just 8 bits
+func (bw *BitWriter) appendByte(data byte, n uint) {
+	n = min(n, 8)
+	bw.WriteBits(uint64(data>>(8-n)), n)
+}
+
+// append appends n bits to the sequence that is already in BitWriter
+// note that n shall not be greater than len(date) * 8, otherwise you'll get a bitBeyondError
+// but previous data would be written
+func (bw *BitWriter) append(data []byte, n uint) error {
+	if n%8 == 0 && n/8 <= uint(len(data)) {
+		bw.WriteBytes(data[:n/8])
+		return nil
+	}
+
+	if n == 0 {
+		return nil
+	}
+
+	times := (n-1)/8 + 1
+
+	for i := 0; times > 0; times, n, i = times-1, n-8, i+1 {
+		if i >= len(data) {
+			return bitBeyondError
+		}
+
+		bw.appendByte(data[i], n)
+	}
+
+	return nil
+}
+
+// appendUint16 appends n highest bits of data
+// if n is greater than 16 appendUint16 appends just 16 bits
+func (bw *BitWriter) appendUint16(data uint16, n uint) {
+	n = min(n, 16)
+	bw.WriteBits(uint64(data>>(16-n)), n)
+}
+
+// getData gives out written data, cutting all not important stuff away
+func (bw *BitWriter) getData() []byte {
+	return bw.data[:(bw.n+7)/8]
+}
+
+// BitReader is a structure that
+// helps to read sequences of bits of any length from bytes
+type BitReader struct {
+	data []byte
+	pos  uint
+	n    uint
+}
+
+// NewBitReader creates BitReader reading all the bits of data
+func NewBitReader(data []byte) *BitReader {
+	return &BitReader{data: data, n: uint(len(data)) * 8}
+}
+
+// Remaining returns the number of bits left to read
+func (br *BitReader) Remaining() uint {
+	return br.n - br.pos
+}
+
+// PeekBits returns next n bits as the lowest bits of value without moving forward
+// n shall not be greater than 64, otherwise just 64 bits are read
+// throws bitOverrunError if there are less than n bits left
+func (br *BitReader) PeekBits(n uint) (uint64, error) {
+	n = min(n, 64)
+	if n > br.Remaining() {
+		return 0, bitOverrunError
+	}
+
+	var value uint64
+	pos := br.pos
+	for left := n; left > 0; {
+		// taking as many bits from the current byte as possible
+		offset := pos % 8
+		take := min(8-offset, left)
+		b := br.data[pos/8] >> (8 - offset - take) & (1<<take - 1)
+
+		value = value<<take | uint64(b)
+		pos += take
+		left -= take
+	}
+
+	return value, nil
+}
+
+// ReadBits returns next n bits as the lowest bits of value
+// n shall not be greater than 64, otherwise just 64 bits are read
+// throws bitOverrunError if there are less than n bits left
+func (br *BitReader) ReadBits(n uint) (uint64, error) {
+	value, err := br.PeekBits(n)
+	if err != nil {
+		return 0, err
+	}
+
+	br.pos += min(n, 64)
+	return value, nil
+}
+
+// ReadBytes reads next n bytes (they're not required to be aligned)
+// throws bitOverrunError if there are less than n bytes left
+func (br *BitReader) ReadBytes(n int) ([]byte, error) {
+	if uint(n)*8 > br.Remaining() {
+		return nil, bitOverrunError
+	}
+
+	if br.pos%8 == 0 {
+		data := make([]byte, n)
+		copy(data, br.data[br.pos/8:])
+		br.pos += uint(n) * 8
+		return data, nil
+	}
+
+	data := make([]byte, 0, n)
+	for i := 0; i < n; i++ {
+		b, _ := br.ReadBits(8)
+		data = append(data, byte(b))
+	}
+
+	return data, nil
+}
@@ -0,0 +1,230 @@
+package qr_tools
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestNewBitWriter(t *testing.T) {
+	ba := NewBitWriter()
+
+	if ba.n != 0 || len(ba.data) != 0 {
+		t.Errorf("Newly created bit writer is not empty")
+	}
+}
+
+func TestBitWriterAppendByte(t *testing.T) {
+	allOnes := byte(math.MaxUint8)
+	ba := NewBitWriter()
+
+	ln := uint(0)
+	for i := uint(0); i <= 100; i++ {
+		ba.appendByte(byte(i)<<(8-min(i, 8)), i)
+
+		prLn := ln
+		ln += min(i, 8)
+		if ln != ba.n {
+			t.Errorf("Bit writer length %d does not match actual number of bits appended %d", ba.n, ln)
+		}
+
+		var extr byte
+		if i == 0 {
+			extr = 0
+		} else if ba.n/8 == prLn/8 {
+			extr = ba.data[len(ba.data)-1] >> ((8 - ba.n%8) % 8)
+			extr &= allOnes >> (8 - (ba.n - prLn))
+		} else {
+			extr = ba.data[len(ba.data)-1] >> ((8 - ba.n%8) % 8)
+			if ba.n%8 != 0 {
+				extr |= ba.data[len(ba.data)-2] << (ba.n % 8)
+			}
+			extr &= allOnes >> (8 - (ba.n - prLn))
+		}
+
+		actual := byte(i) & (allOnes >> (8 - min(i, 8)))
+		if extr != actual {
+			t.Errorf("Extracted bites %b aren't equal with actual bites %b, i = %d", extr, actual, i)
+		}
+	}
+}
+
+func TestBitWriterAppendUint16(t *testing.T) {
+	ba1 := NewBitWriter()
+	ba2 := NewBitWriter()
+
+	for i, num := 0, uint16(math.MaxUint8+1); i < 100; i, num = i+1, num+1 {
+		bits := i % 25
+
+		ba1.appendUint16(num, uint(bits))
+
+		ba2.appendByte(byte(num>>8), uint(min(bits, 8)))
+		ba2.appendByte(byte(num), uint(max(bits-8, 0)))
+
+		if ba1.n != ba2.n || !bytes.Equal(ba1.data, ba2.data) {
+			t.Errorf("Arrays aren't equal after adding %d bits from %d", bits, i)
+		}
+	}
+}
+
+func TestBitWriterAppend(t *testing.T) {
+	ba1 := NewBitWriter()
+	ba2 := NewBitWriter()
+
+	data := make([]byte, 0, 12)
+	for i := 0; i < 12; i += 4 {
+		r := rand.Uint32()
+		data = append(data, byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
+	}
+
+	for i := 0; i <= 12*8+100; i++ {
+		err := ba1.append(data, uint(i))
+		if err != nil && i <= 12*8 {
+			t.Errorf("Got sudden error after adding %d bits", i)
+		} else if err == nil && i > 12*8 {
+			t.Errorf("Didn't get error after adding %d bits", i)
+		}
+
+		for j, y := min(i, 12*8), 0; j > 0; j, y = j-8, y+1 {
+			ba2.appendByte(data[y], uint(min(j, 8)))
+		}
+
+		if ba1.n != ba2.n || ba1.n > 0 && !bytes.Equal(ba1.data, ba2.data) {
+			t.Errorf("Arrays aren't equal after adding %d bits", i)
+		}
+	}
+}
+
+func TestBitWriterGetData(t *testing.T) {
+	ba := NewBitWriter()
+	data := make([]byte, 0, 12)
+	for i := 0; i < 12; i += 4 {
+		r := rand.Uint32()
+		data = append(data, byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
+	}
+
+	bits := uint(12*5 + 6)
+
+	// bits beyond the written ones should be zero
+	expected := append([]byte(nil), data[:(bits-1)/8+1]...)
+	expected[len(expected)-1] &= allOnes << (8 - bits%8)
+
+	_ = ba.append(data, bits)
+	if ba.n != bits || !bytes.Equal(ba.getData(), expected) {
+		t.Errorf("getData's value and data aren't equal")
+	}
+}
+
+func TestBitWriter_WriteBits(t *testing.T) {
+	bw := NewBitWriter()
+	ba := NewBitWriter()
+
+	for i := uint(0); i <= 100; i++ {
+		value := rand.Uint64()
+		bw.WriteBits(value, i)
+
+		// appending the same bits by bytes starting from the most significant
+		n := min(i, 64)
+		for left := n; left > 0; left -= min(left, 8) {
+			take := min(left, 8)
+			ba.appendByte(byte(value>>(left-take))<<(8-take), take)
+		}
+
+		if bw.Len() != ba.n || !bytes.Equal(bw.Bytes(), ba.getData()) {
+			t.Errorf("Writing %d bits gave different result", i)
+		}
+		if bw.n%8 != 0 && bw.data[len(bw.data)-1]&(allOnes>>(bw.n%8)) != 0 {
+			t.Errorf("Bits beyond written ones aren't zero after writing %d bits", i)
+		}
+	}
+}
+
+func TestBitWriter_WriteBytes(t *testing.T) {
+	data := []byte{0xde, 0xad, 0xbe, 0xef}
+	for shift := uint(0); shift < 8; shift++ {
+		bw := NewBitWriter()
+		bw.WriteBits(0b1, shift)
+		bw.WriteBytes(data)
+
+		ba := NewBitWriter()
+		ba.WriteBits(0b1, shift)
+		for _, b := range data {
+			ba.WriteBits(uint64(b), 8)
+		}
+
+		if bw.Len() != shift+32 || !bytes.Equal(bw.Bytes(), ba.Bytes()) {
+			t.Errorf("Writing bytes after %d bits gave different result", shift)
+		}
+
+		bw.AlignToByte()
+		if bw.Len()%8 != 0 || bw.Len() < shift+32 {
+			t.Errorf("BitWriter of length %d isn't aligned properly", bw.Len())
+		}
+	}
+}
+
+func TestBitReader(t *testing.T) {
+	bw := NewBitWriter()
+	values := make([]uint64, 0, 65)
+	for i := uint(0); i <= 64; i++ {
+		value := rand.Uint64()
+		if i < 64 {
+			value &= 1<<i - 1
+		}
+		values = append(values, value)
+		bw.WriteBits(value, i)
+	}
+
+	br := NewBitReader(bw.Bytes())
+	if br.Remaining() != uint(len(bw.Bytes()))*8 {
+		t.Errorf("BitReader has %d bits instead of %d", br.Remaining(), len(bw.Bytes())*8)
+	}
+
+	for i, value := range values {
+		peeked, err := br.PeekBits(uint(i))
+		if err != nil {
+			t.Fatalf("Failed to peek %d bits: %v", i, err)
+		}
+		read, err := br.ReadBits(uint(i))
+		if err != nil {
+			t.Fatalf("Failed to read %d bits: %v", i, err)
+		}
+
+		if peeked != value || read != value {
+			t.Errorf("Read %x and peeked %x instead of %x", read, peeked, value)
+		}
+	}
+
+	left := br.Remaining()
+	if left >= 8 {
+		t.Errorf("%d bits are left, but less than 8 expected", left)
+	}
+	if _, err := br.ReadBits(left + 1); !errors.Is(err, bitOverrunError) {
+		t.Errorf("Reading beyond the end doesn't give an error")
+	}
+	if br.Remaining() != left {
+		t.Errorf("Failed read moved the reader")
+	}
+}
+
+func TestBitReader_ReadBytes(t *testing.T) {
+	data := []byte{0xde, 0xad, 0xbe, 0xef}
+	for shift := uint(0); shift < 8; shift++ {
+		bw := NewBitWriter()
+		bw.WriteBits(0, shift)
+		bw.WriteBytes(data)
+
+		br := NewBitReader(bw.Bytes())
+		_, _ = br.ReadBits(shift)
+		read, err := br.ReadBytes(len(data))
+		if err != nil || !bytes.Equal(read, data) {
+			t.Errorf("Failed to read bytes after %d bits: %x, %v", shift, read, err)
+		}
+
+		if _, err := br.ReadBytes(1); !errors.Is(err, bitOverrunError) {
+			t.Errorf("Reading bytes beyond the end doesn't give an error")
+		}
+	}
+}
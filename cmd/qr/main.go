@@ -0,0 +1,171 @@
+// Command qr backs files up on paper and restores them from the scans
+//
+//	qr backup [-level Q|H] [-version N] [-page A4|Letter] [-o FILE.pdf] FILE
+//	qr restore [-o FILE] IMAGE...
+//
+// backup prints the file as QR codes on PDF pages, restore reads the codes from PNG, JPEG or GIF scans
+// of the pages given in any order, reports missing chunks and verifies the hash of the file
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"strings"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+	"github.com/rinnothing/qr-tools/backup"
+	"github.com/rinnothing/qr-tools/render"
+)
+
+var (
+	usageError = errors.New("usage: qr backup [-level Q|H] [-version N] [-page A4|Letter] [-o FILE.pdf] FILE\n" +
+		"       qr restore [-o FILE] IMAGE...")
+	wrongLevelError = errors.New("level must be Q or H")
+	wrongPageError  = errors.New("page must be A4 or Letter")
+)
+
+// defaultVersion keeps modules of 3 × 4 codes on A4 page larger than a millimetre
+const defaultVersion = 10
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "qr:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command, stdout gets the restored file if no output file is given
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return usageError
+	}
+
+	switch args[0] {
+	case "backup":
+		return runBackup(args[1:], stderr)
+	case "restore":
+		return runRestore(args[1:], stdout, stderr)
+	}
+	return usageError
+}
+
+func runBackup(args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	level := flags.String("level", "Q", "error correction level, Q or H")
+	version := flags.Int("version", defaultVersion, "QR version of the codes")
+	page := flags.String("page", "A4", "page size, A4 or Letter")
+	output := flags.String("o", "", "output PDF, FILE.pdf by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError
+	}
+
+	var lvl qr_tools.ErrorCorrectionLevel
+	switch strings.ToUpper(*level) {
+	case "Q":
+		lvl = qr_tools.Q
+	case "H":
+		lvl = qr_tools.H
+	default:
+		return wrongLevelError
+	}
+	var size render.Page
+	switch strings.ToLower(*page) {
+	case "a4":
+		size = render.A4
+	case "letter":
+		size = render.Letter
+	default:
+		return wrongPageError
+	}
+
+	name := flags.Arg(0)
+	if *output == "" {
+		*output = name + ".pdf"
+	}
+	file, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	chunks, matrices, err := backup.Encode(file, lvl, qr_tools.QRVersion(*version))
+	if err != nil {
+		return err
+	}
+	pdf, err := backup.PDF(chunks, matrices, backup.DefaultPDFOptions(size))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*output, pdf, 0o644); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stderr, "%s: %d codes of file %x\n", *output, len(chunks), chunks[0].FileID)
+	return nil
+}
+
+func runRestore(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("o", "", "restored file, standard output by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return usageError
+	}
+
+	r := backup.NewRestorer()
+	for _, name := range flags.Args() {
+		img, err := readImage(name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		// codes of other files and unreadable scans are reported, the rest of the scans may have their chunks
+		n, err := r.AddImage(img)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		}
+		fmt.Fprintf(stderr, "%s: %d codes\n", name, n)
+	}
+
+	if missing := r.Missing(); len(missing) != 0 {
+		numbers := make([]string, len(missing))
+		for i, m := range missing {
+			numbers[i] = fmt.Sprint(m + 1)
+		}
+		fmt.Fprintf(stderr, "missing codes: %s\n", strings.Join(numbers, ", "))
+	}
+	file, err := r.File()
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = stdout.Write(file)
+		return err
+	}
+	return os.WriteFile(*output, file, 0o644)
+}
+
+// readImage decodes PNG, JPEG or GIF file
+func readImage(name string) (image.Image, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	return img, err
+}
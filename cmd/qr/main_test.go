@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rinnothing/qr-tools/backup"
+	"github.com/rinnothing/qr-tools/render"
+)
+
+func testFile(n int) []byte {
+	file := make([]byte, n)
+	for i := range file {
+		file[i] = byte(i * 7)
+	}
+	return file
+}
+
+// writeScans writes every code of the file into its own PNG, like scans of cut pages
+func writeScans(t *testing.T, dir string, file []byte) []string {
+	t.Helper()
+	chunks, matrices, err := backup.Encode(file, backup.Level, 6)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+
+	names := make([]string, len(chunks))
+	for i, m := range matrices {
+		opts := render.DefaultOptions()
+		opts.Scale, opts.Caption = 3, chunks[i].Caption()
+
+		var buf bytes.Buffer
+		if err := render.WritePNG(&buf, m, opts); err != nil {
+			t.Fatalf("Failed to render chunk %d: %v", i, err)
+		}
+		names[i] = filepath.Join(dir, fmt.Sprintf("%d.png", i))
+		if err := os.WriteFile(names[i], buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return names
+}
+
+func TestRun_Backup(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "file.bin")
+	if err := os.WriteFile(name, testFile(2000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stderr bytes.Buffer
+	if err := run([]string{"backup", "-page", "letter", name}, nil, &stderr); err != nil {
+		t.Fatalf("Failed to back up: %v", err)
+	}
+	pdf, err := os.ReadFile(name + ".pdf")
+	if err != nil || !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		t.Errorf("Backup isn't written: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "19 codes") {
+		t.Errorf("Backup reports %q", stderr.String())
+	}
+
+	for _, args := range [][]string{
+		{"backup", "-level", "M", name},
+		{"backup", "-page", "A3", name},
+		{"backup"},
+		{"print", name},
+		{},
+	} {
+		if err := run(args, nil, &stderr); err == nil {
+			t.Errorf("%q is run", args)
+		}
+	}
+}
+
+func TestRun_Restore(t *testing.T) {
+	dir := t.TempDir()
+	file := testFile(200)
+	scans := writeScans(t, dir, file)
+
+	var stdout, stderr bytes.Buffer
+	// scans come in any order
+	args := append([]string{"restore"}, scans[3:]...)
+	if err := run(append(args, scans[:3]...), &stdout, &stderr); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if !bytes.Equal(stdout.Bytes(), file) {
+		t.Errorf("Restored file differs")
+	}
+
+	output := filepath.Join(dir, "restored")
+	stderr.Reset()
+	err := run([]string{"restore", "-o", output, scans[0], scans[2]}, nil, &stderr)
+	if err != backup.MissingChunksError || !strings.Contains(stderr.String(), "missing codes: 2, 4") {
+		t.Errorf("Incomplete file is restored with %v, reported %q", err, stderr.String())
+	}
+	if _, err := os.Stat(output); err == nil {
+		t.Errorf("Incomplete file is written")
+	}
+}
@@ -0,0 +1,98 @@
+package qr_tools
+
+import (
+	"math/bits"
+)
+
+// maxFormatDistance is the number of wrong bits format information can be read with,
+// codewords of format information differ in 7 bits at least
+const maxFormatDistance = 3
+
+// readFormat returns ErrorCorrectionLevel and mask of the matrix: the format information codeword
+// closest to either of its copies
+// throws wrongFormatError if both copies are damaged too much
+func readFormat(modules [][]bool) (ErrorCorrectionLevel, Mask, error) {
+	first, second := formatPositions(len(modules))
+	var read [2]uint
+	for i := range first {
+		if modules[first[i].y][first[i].x] {
+			read[0] |= 1 << i
+		}
+		if modules[second[i].y][second[i].x] {
+			read[1] |= 1 << i
+		}
+	}
+
+	best, bestLvl, bestMask := maxFormatDistance+1, ErrorCorrectionLevel(0), Mask(0)
+	for lvl := ErrorCorrectionLevel(L); lvl <= H; lvl++ {
+		for mask := Mask(0); mask < MaskCount; mask++ {
+			format := formatBits(lvl, mask)
+			for _, r := range read {
+				if d := bits.OnesCount(r ^ format); d < best {
+					best, bestLvl, bestMask = d, lvl, mask
+				}
+			}
+		}
+	}
+	if best > maxFormatDistance {
+		return 0, 0, wrongFormatError
+	}
+
+	return bestLvl, bestMask, nil
+}
+
+// Decode reads format information of the matrix, unmasks it, gathers the blocks of codewords
+// and corrects their errors with Reed–Solomon code
+// returns data codewords (QRUnmarshaler of the version turns them into payload), level and version of the code
+// throws wrongQRVersionError if the matrix isn't a square of any version size,
+// wrongFormatError if format information can't be read and corruptedDataError if errors can't be corrected
+func Decode(modules [][]bool) ([]byte, ErrorCorrectionLevel, QRVersion, error) {
+	size := len(modules)
+	if size < 21 || (size-17)%4 != 0 || size > 177 {
+		return nil, 0, 0, wrongQRVersionError
+	}
+	for _, row := range modules {
+		if len(row) != size {
+			return nil, 0, 0, wrongQRVersionError
+		}
+	}
+	ver := QRVersion((size - 17) / 4)
+
+	lvl, mask, err := readFormat(modules)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	kinds, err := FunctionPatterns(ver)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	blocks, err := ECBlocks(lvl, ver)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	full := make([][]byte, len(blocks))
+	for i, b := range blocks {
+		full[i] = make([]byte, b.DataCodewords+b.ECCodewords)
+	}
+	order := codewordOrder(blocks)
+	for bit, p := range placement(kinds) {
+		if bit/8 >= len(order) {
+			break
+		}
+		if modules[p.y][p.x] != mask.inverts(p.x, p.y) {
+			ref := order[bit/8]
+			full[ref.Block][ref.Index] |= 1 << (7 - bit%8)
+		}
+	}
+
+	data := make([]byte, 0, codewordsCapacities[lvl][ver-1])
+	for i, b := range blocks {
+		if _, err := rsCorrect(full[i], b.ECCodewords); err != nil {
+			return nil, 0, 0, err
+		}
+		data = append(data, full[i][:b.DataCodewords]...)
+	}
+
+	return data, lvl, ver, nil
+}
@@ -0,0 +1,85 @@
+package qr_tools
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		name string
+		lvl  ErrorCorrectionLevel
+		ver  QRVersion
+		text string
+	}{
+		{"single block", M, 1, "HELLO WORLD"},
+		{"blocks of different length", Q, 5, "https://example.com/some/long/path"},
+		{"version information", H, 7, "hello, world!"},
+		{"many blocks", L, 40, "the end"},
+	}
+
+	for _, c := range cases {
+		segment := NewByteSegment([]byte(c.text))
+		data, _ := EncodeSegments([]Segment{segment}, c.lvl, c.ver)
+		modules, err := Encode([]Segment{segment}, c.lvl, c.ver)
+		if err != nil {
+			t.Fatalf("Failed to encode %s: %v", c.name, err)
+		}
+
+		decoded, lvl, ver, err := Decode(modules)
+		if err != nil {
+			t.Errorf("Failed to decode %s: %v", c.name, err)
+			continue
+		}
+		if lvl != c.lvl || ver != c.ver || !reflect.DeepEqual(decoded, data) {
+			t.Errorf("Code of %s is decoded as %v %d-%d instead of %v %d-%d", c.name, decoded, ver, lvl, data, c.ver, c.lvl)
+		}
+		if str, _ := NewQRUnmarshaler(ver).UnmarshalToString(decoded); str != c.text {
+			t.Errorf("Code of %s holds %q instead of %q", c.name, str, c.text)
+		}
+	}
+}
+
+func TestDecode_Damage(t *testing.T) {
+	segment := NewByteSegment([]byte("https://example.com/damaged"))
+	modules, _ := Encode([]Segment{segment}, H, 4)
+	flip := func(x0, y0, x1, y1 int) [][]bool {
+		damaged := make([][]bool, len(modules))
+		for y := range modules {
+			damaged[y] = append([]bool(nil), modules[y]...)
+			for x := range damaged[y] {
+				if x >= x0 && x < x1 && y >= y0 && y < y1 {
+					damaged[y][x] = !damaged[y][x]
+				}
+			}
+		}
+		return damaged
+	}
+
+	cases := []struct {
+		name     string
+		modules  [][]bool
+		expected error
+	}{
+		{"center", flip(13, 13, 20, 20), nil},
+		{"first copy of format information", flip(0, 8, 9, 9), nil},
+		{"half of the code", flip(0, 10, 33, 33), corruptedDataError},
+		{"both copies of format information", flip(0, 8, 33, 9), wrongFormatError},
+		{"wrong size", modules[1:], wrongQRVersionError},
+	}
+
+	for _, c := range cases {
+		data, _, ver, err := Decode(c.modules)
+		if !errors.Is(err, c.expected) {
+			t.Errorf("Decoding with damaged %s gives %v instead of %v", c.name, err, c.expected)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if str, _ := NewQRUnmarshaler(ver).UnmarshalToString(data); str != "https://example.com/damaged" {
+			t.Errorf("Code with damaged %s holds %q", c.name, str)
+		}
+	}
+}
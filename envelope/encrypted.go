@@ -0,0 +1,141 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"strings"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+	"github.com/rinnothing/qr-tools/base45"
+	"github.com/rinnothing/qr-tools/internal/scrypt"
+)
+
+var (
+	WrongPassphraseError = errors.New("wrong passphrase or corrupted envelope")
+)
+
+// EncryptedPrefix starts every encrypted envelope
+const EncryptedPrefix = "ENC1:"
+
+const (
+	saltSize = 16
+	// scrypt parameters, cost is stored in the envelope as log2 of n
+	// so that it can be raised later without breaking old backups
+	defaultLogN = 15
+	// maxLogN bounds the cost read from the unauthenticated header before the key is derived,
+	// scrypt takes 128*r*n bytes, so it's 128 MiB
+	maxLogN = 17
+	scryptR = 8
+	scryptP = 1
+	// AES-256 key
+	keySize = 32
+)
+
+// deriveKey derives AES key from passphrase with scrypt
+func deriveKey(passphrase, salt []byte, logN byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, 1<<logN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encrypt returns binary envelope: cost, salt, nonce and AES-GCM ciphertext
+// cost and salt are authenticated as additional data
+func encrypt(plaintext, passphrase []byte, logN byte) ([]byte, error) {
+	header := make([]byte, 1+saltSize)
+	header[0] = logN
+	if _, err := rand.Read(header[1:]); err != nil {
+		return nil, err
+	}
+
+	aead, err := deriveKey(passphrase, header[1:], logN)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	envelope := append(header, nonce...)
+	return aead.Seal(envelope, nonce, plaintext, header), nil
+}
+
+// decrypt opens binary envelope made by encrypt
+func decrypt(envelope, passphrase []byte) ([]byte, error) {
+	headerSize := 1 + saltSize
+	if len(envelope) < headerSize || envelope[0] > maxLogN {
+		return nil, WrongFormatError
+	}
+
+	aead, err := deriveKey(passphrase, envelope[1:headerSize], envelope[0])
+	if err != nil {
+		return nil, WrongFormatError
+	}
+	if len(envelope) < headerSize+aead.NonceSize()+aead.Overhead() {
+		return nil, WrongFormatError
+	}
+
+	nonce := envelope[headerSize : headerSize+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, envelope[headerSize+aead.NonceSize():], envelope[:headerSize])
+	if err != nil {
+		return nil, WrongPassphraseError
+	}
+	return plaintext, nil
+}
+
+// Encrypt encrypts plaintext with AES-GCM using key derived from passphrase with scrypt
+// ciphertext is encoded with base45 (not compressed, since it won't get any smaller)
+func Encrypt(plaintext, passphrase []byte) (string, error) {
+	envelope, err := encrypt(plaintext, passphrase, defaultLogN)
+	if err != nil {
+		return "", err
+	}
+
+	return EncryptedPrefix + base45.Encode(envelope), nil
+}
+
+// Decrypt decrypts envelope made by Encrypt
+// throws WrongPassphraseError if passphrase doesn't match
+func Decrypt(str string, passphrase []byte) ([]byte, error) {
+	str, found := strings.CutPrefix(str, EncryptedPrefix)
+	if !found {
+		return nil, WrongFormatError
+	}
+
+	envelope, err := base45.Decode(str)
+	if err != nil {
+		return nil, WrongFormatError
+	}
+
+	return decrypt(envelope, passphrase)
+}
+
+// MarshalEncrypted encrypts plaintext and marshals encrypted envelope in alphanumeric mode
+func MarshalEncrypted(plaintext, passphrase []byte, lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) ([]byte, error) {
+	str, err := Encrypt(plaintext, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return qr_tools.NewAlphanumericMarshaler(lvl, ver).MarshalString(str)
+}
+
+// UnmarshalEncrypted unmarshals encrypted envelope and decrypts it with passphrase
+func UnmarshalEncrypted(data []byte, ver qr_tools.QRVersion, passphrase []byte) ([]byte, error) {
+	str, err := qr_tools.NewQRUnmarshaler(ver).UnmarshalToString(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return Decrypt(str, passphrase)
+}
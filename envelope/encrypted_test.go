@@ -0,0 +1,66 @@
+package envelope
+
+import (
+	"bytes"
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+	"github.com/rinnothing/qr-tools/base45"
+)
+
+func TestEncrypt(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+
+	str, err := Encrypt(secret, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if _, err = qr_tools.NewAlphanumericSegment(str); err != nil {
+		t.Errorf("Encrypted envelope %q doesn't fit alphanumeric mode", str)
+	}
+
+	decrypted, err := Decrypt(str, []byte("passphrase"))
+	if err != nil || !bytes.Equal(decrypted, secret) {
+		t.Errorf("Decrypted %q instead of %q: %v", decrypted, secret, err)
+	}
+
+	if _, err = Decrypt(str, []byte("Passphrase")); err != WrongPassphraseError {
+		t.Errorf("Wrong passphrase is accepted with %v", err)
+	}
+
+	// changing the cost is detected too, since it's authenticated
+	envelope, _ := base45.Decode(str[len(EncryptedPrefix):])
+	envelope[0]--
+	if _, err = Decrypt(EncryptedPrefix+base45.Encode(envelope), []byte("passphrase")); err != WrongPassphraseError {
+		t.Errorf("Tampered envelope is accepted with %v", err)
+	}
+
+	// too costly header is rejected before scrypt allocates its memory
+	costly := append([]byte{maxLogN + 1}, envelope[1:]...)
+	if _, err = Decrypt(EncryptedPrefix+base45.Encode(costly), []byte("passphrase")); err != WrongFormatError {
+		t.Errorf("Envelope with cost %d is decrypted with %v", costly[0], err)
+	}
+
+	for _, s := range []string{"", "SIG1:" + str[len(EncryptedPrefix):], EncryptedPrefix + "a", EncryptedPrefix + base45.Encode([]byte{4, 1, 2})} {
+		if _, err := Decrypt(s, []byte("passphrase")); err != WrongFormatError {
+			t.Errorf("Wrong envelope %q is decrypted with %v", s, err)
+		}
+	}
+}
+
+func TestMarshalEncrypted(t *testing.T) {
+	// cheap cost keeps the test fast
+	envelope, err := encrypt([]byte("secret"), []byte("pass"), 4)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	data, err := qr_tools.NewAlphanumericMarshaler(qr_tools.Q, 5).MarshalString(EncryptedPrefix + base45.Encode(envelope))
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	decrypted, err := UnmarshalEncrypted(data, 5, []byte("pass"))
+	if err != nil || string(decrypted) != "secret" {
+		t.Errorf("Unmarshaled %q: %v", decrypted, err)
+	}
+}
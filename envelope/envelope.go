@@ -0,0 +1,14 @@
+// Package envelope wraps QR contents into signed or encrypted envelopes
+//
+// envelopes are binary, they're encoded with base45 without compression,
+// so they can be marshaled in alphanumeric mode
+package envelope
+
+import (
+	"errors"
+)
+
+var (
+	WrongFormatError = errors.New("data is not an envelope")
+	WrongKeyError    = errors.New("wrong key")
+)
@@ -0,0 +1,150 @@
+package envelope
+
+import (
+	"crypto/ed25519"
+	"strings"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+	"github.com/rinnothing/qr-tools/base45"
+)
+
+// SignedPrefix starts every signed envelope
+const SignedPrefix = "SIG1:"
+
+// maxKeyIDLen is the longest key id, its length is stored in one byte
+const maxKeyIDLen = 255
+
+// VerificationStatus is enum that
+// shows the result of signature verification
+type VerificationStatus int
+
+const (
+	// Verified means the signature is made by the key with given id
+	Verified VerificationStatus = iota
+	// UnknownKey means there is no key with given id in the KeySet
+	UnknownKey
+	// BadSignature means the content or the key id was changed after signing
+	BadSignature
+)
+
+func (s VerificationStatus) String() string {
+	switch s {
+	case Verified:
+		return "verified"
+	case UnknownKey:
+		return "unknown key"
+	case BadSignature:
+		return "bad signature"
+	default:
+		return "unknown status"
+	}
+}
+
+// KeySet maps key ids to public keys that are trusted
+type KeySet map[string]ed25519.PublicKey
+
+// Verification is the result of opening signed envelope
+// Payload is returned even if signature isn't verified, Status must be checked before trusting it
+type Verification struct {
+	Payload []byte
+	KeyID   string
+	Status  VerificationStatus
+}
+
+// signedMessage returns the message that is signed, key id is signed together with the payload
+func signedMessage(keyID string, payload []byte) []byte {
+	msg := make([]byte, 0, 1+len(keyID)+len(payload))
+	msg = append(msg, byte(len(keyID)))
+	msg = append(msg, keyID...)
+	return append(msg, payload...)
+}
+
+// seal returns binary envelope: key id length, key id, signature and payload
+func seal(payload []byte, keyID string, key ed25519.PrivateKey) ([]byte, error) {
+	if len(keyID) > maxKeyIDLen || len(key) != ed25519.PrivateKeySize {
+		return nil, WrongKeyError
+	}
+
+	msg := signedMessage(keyID, payload)
+	sig := ed25519.Sign(key, msg)
+
+	envelope := make([]byte, 0, len(msg)+len(sig))
+	envelope = append(envelope, msg[:1+len(keyID)]...)
+	envelope = append(envelope, sig...)
+	return append(envelope, payload...), nil
+}
+
+// open splits binary envelope and verifies it
+func open(envelope []byte, keys KeySet) (*Verification, error) {
+	if len(envelope) < 1 || len(envelope) < 1+int(envelope[0])+ed25519.SignatureSize {
+		return nil, WrongFormatError
+	}
+
+	idEnd := 1 + int(envelope[0])
+	v := &Verification{
+		KeyID:   string(envelope[1:idEnd]),
+		Payload: envelope[idEnd+ed25519.SignatureSize:],
+	}
+	sig := envelope[idEnd : idEnd+ed25519.SignatureSize]
+
+	key, ok := keys[v.KeyID]
+	switch {
+	case !ok:
+		v.Status = UnknownKey
+	case len(key) != ed25519.PublicKeySize || !ed25519.Verify(key, signedMessage(v.KeyID, v.Payload), sig):
+		v.Status = BadSignature
+	default:
+		v.Status = Verified
+	}
+
+	return v, nil
+}
+
+// Sign signs payload with Ed25519 key and packs it into signed envelope
+// envelope is encoded with base45 (not compressed, since signature won't get any smaller)
+// throws WrongKeyError if key id is longer than 255 bytes or key is malformed
+func Sign(payload []byte, keyID string, key ed25519.PrivateKey) (string, error) {
+	envelope, err := seal(payload, keyID, key)
+	if err != nil {
+		return "", err
+	}
+
+	return SignedPrefix + base45.Encode(envelope), nil
+}
+
+// Verify unpacks signed envelope and verifies it against keys
+// error is returned only if str is not a signed envelope, failed verification is shown by the status
+func Verify(str string, keys KeySet) (*Verification, error) {
+	str, found := strings.CutPrefix(str, SignedPrefix)
+	if !found {
+		return nil, WrongFormatError
+	}
+
+	envelope, err := base45.Decode(str)
+	if err != nil {
+		return nil, WrongFormatError
+	}
+
+	return open(envelope, keys)
+}
+
+// MarshalSigned signs payload and marshals signed envelope in alphanumeric mode
+func MarshalSigned(payload []byte, keyID string, key ed25519.PrivateKey,
+	lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) ([]byte, error) {
+	str, err := Sign(payload, keyID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return qr_tools.NewAlphanumericMarshaler(lvl, ver).MarshalString(str)
+}
+
+// UnmarshalSigned unmarshals signed envelope and verifies it against keys
+func UnmarshalSigned(data []byte, ver qr_tools.QRVersion, keys KeySet) (*Verification, error) {
+	str, err := qr_tools.NewQRUnmarshaler(ver).UnmarshalToString(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return Verify(str, keys)
+}
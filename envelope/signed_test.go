@@ -0,0 +1,83 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+	"github.com/rinnothing/qr-tools/base45"
+)
+
+func testKey(seed byte) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(bytes.Repeat([]byte{seed}, ed25519.SeedSize))
+}
+
+func TestSign(t *testing.T) {
+	key, other := testKey(1), testKey(2)
+	keys := KeySet{"label-2024": key.Public().(ed25519.PublicKey), "other": other.Public().(ed25519.PublicKey)}
+	payload := []byte("LOT 4711, best before 2025-01-01")
+
+	str, err := Sign(payload, "label-2024", key)
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	if _, err = qr_tools.NewAlphanumericSegment(str); err != nil {
+		t.Errorf("Signed envelope %q doesn't fit alphanumeric mode", str)
+	}
+	// key id length, key id, signature and payload aren't compressed
+	if expected := len(SignedPrefix) + base45.EncodedLen(1+10+ed25519.SignatureSize+len(payload)); len(str) != expected {
+		t.Errorf("Signed envelope is %d characters long instead of %d", len(str), expected)
+	}
+
+	cases := []struct {
+		str    string
+		keys   KeySet
+		status VerificationStatus
+	}{
+		{str, keys, Verified},
+		{str, KeySet{"other": keys["other"]}, UnknownKey},
+		{str, KeySet{"label-2024": keys["other"]}, BadSignature},
+	}
+	for _, c := range cases {
+		v, err := Verify(c.str, c.keys)
+		if err != nil {
+			t.Errorf("Failed to verify: %v", err)
+		} else if v.Status != c.status || v.KeyID != "label-2024" || !bytes.Equal(v.Payload, payload) {
+			t.Errorf("Verified as %v (%s, %q) instead of %v", v.Status, v.KeyID, v.Payload, c.status)
+		}
+	}
+
+	// changing a single byte of payload breaks the signature
+	envelope, _ := base45.Decode(str[len(SignedPrefix):])
+	envelope[len(envelope)-1] ^= 1
+	tampered := SignedPrefix + base45.Encode(envelope)
+	if v, err := Verify(tampered, keys); err != nil || v.Status != BadSignature {
+		t.Errorf("Tampered envelope is verified as %v: %v", v, err)
+	}
+
+	for _, s := range []string{"", "SIG1:", "HC1:" + str[len(SignedPrefix):], "SIG1:A", SignedPrefix + base45.Encode([]byte{10, 'a'})} {
+		if _, err := Verify(s, keys); err != WrongFormatError {
+			t.Errorf("Wrong envelope %q is verified with %v", s, err)
+		}
+	}
+
+	if _, err := Sign(payload, string(make([]byte, 256)), key); err != WrongKeyError {
+		t.Errorf("Too long key id is accepted with %v", err)
+	}
+}
+
+func TestMarshalSigned(t *testing.T) {
+	key := testKey(1)
+	payload := []byte("LOT 4711")
+
+	data, err := MarshalSigned(payload, "k", key, qr_tools.M, 10)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	v, err := UnmarshalSigned(data, 10, KeySet{"k": key.Public().(ed25519.PublicKey)})
+	if err != nil || v.Status != Verified || !bytes.Equal(v.Payload, payload) {
+		t.Errorf("Unmarshaled %v: %v", v, err)
+	}
+}
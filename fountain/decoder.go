@@ -0,0 +1,241 @@
+package fountain
+
+import (
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+	"github.com/rinnothing/qr-tools/scan"
+)
+
+// pending is received frame that still has more than one unknown block
+type pending struct {
+	blocks  map[int]bool
+	payload []byte
+}
+
+// A Decoder collects frames in any order and restores the data with belief propagation
+type Decoder struct {
+	dataLen   uint32
+	blockSize uint16
+	checksum  uint32
+	cdf       []float64
+
+	blocks  [][]byte
+	decoded int
+	pending []*pending
+	seen    map[uint32]bool
+}
+
+// NewDecoder returns empty Decoder, the first valid frame chooses the data
+// frames of untrusted streams should go to NewDecoderFor, so that a stray frame can't choose it
+func NewDecoder() *Decoder {
+	return &Decoder{}
+}
+
+// NewDecoderFor returns Decoder that accepts only frames with expected data length and block size,
+// the first of them chooses the checksum
+// throws TooManyBlocksError if data takes more than MaxBlocks blocks
+func NewDecoderFor(dataLen uint32, blockSize uint16) (*Decoder, error) {
+	if blockSize == 0 {
+		return nil, WrongBlockSizeError
+	}
+	if blockCount(dataLen, blockSize) > MaxBlocks {
+		return nil, TooManyBlocksError
+	}
+
+	d := &Decoder{}
+	d.setup(dataLen, blockSize)
+	return d, nil
+}
+
+// setup chooses data length and block size
+func (d *Decoder) setup(dataLen uint32, blockSize uint16) {
+	d.dataLen, d.blockSize = dataLen, blockSize
+	k := blockCount(dataLen, blockSize)
+	d.blocks = make([][]byte, k)
+	d.cdf = solitonCDF(k)
+}
+
+// Add adds frame and decodes all the blocks it makes known
+// repeated frames are ignored
+// throws WrongFormatError if frame is malformed, TooManyBlocksError if its data takes more than MaxBlocks blocks
+// and OtherDataError if frame doesn't belong to the data of previous frames
+func (d *Decoder) Add(f Frame) error {
+	// frame is checked before it can choose the data
+	if f.BlockSize == 0 || len(f.Payload) != int(f.BlockSize) {
+		return WrongFormatError
+	}
+	if blockCount(f.DataLen, f.BlockSize) > MaxBlocks {
+		return TooManyBlocksError
+	}
+
+	if d.blocks == nil {
+		d.setup(f.DataLen, f.BlockSize)
+	}
+	if f.DataLen != d.dataLen || f.BlockSize != d.blockSize {
+		return OtherDataError
+	}
+	if d.seen == nil {
+		d.checksum = f.Checksum
+		d.seen = make(map[uint32]bool)
+	}
+	if f.Checksum != d.checksum {
+		return OtherDataError
+	}
+	if d.seen[f.Seed] || d.Done() {
+		return nil
+	}
+	d.seen[f.Seed] = true
+
+	p := &pending{blocks: make(map[int]bool), payload: append([]byte(nil), f.Payload...)}
+	for _, i := range neighbours(f.Seed, len(d.blocks), d.cdf) {
+		p.blocks[i] = true
+	}
+	d.reduce(p)
+
+	queue := []*pending{p}
+	for len(queue) > 0 {
+		p, queue = queue[0], queue[1:]
+		if len(p.blocks) != 1 {
+			if len(p.blocks) > 1 {
+				d.pending = append(d.pending, p)
+			}
+			continue
+		}
+
+		var i int
+		for i = range p.blocks {
+			break
+		}
+		if d.blocks[i] != nil {
+			continue
+		}
+		d.blocks[i] = p.payload
+		d.decoded++
+
+		// new block may leave other frames with a single unknown block
+		rest := d.pending[:0]
+		for _, other := range d.pending {
+			d.reduce(other)
+			if len(other.blocks) > 1 {
+				rest = append(rest, other)
+			} else {
+				queue = append(queue, other)
+			}
+		}
+		d.pending = rest
+	}
+
+	return nil
+}
+
+// reduce XORs known blocks out of p
+func (d *Decoder) reduce(p *pending) {
+	for i := range p.blocks {
+		if d.blocks[i] == nil {
+			continue
+		}
+		for j, b := range d.blocks[i] {
+			p.payload[j] ^= b
+		}
+		delete(p.blocks, i)
+	}
+}
+
+// AddMarshaled unmarshals data made by Encoder.Marshal and adds the frame
+func (d *Decoder) AddMarshaled(data []byte, ver qr_tools.QRVersion) error {
+	payload, err := qr_tools.NewQRUnmarshaler(ver).UnmarshalToBytes(data)
+	if err != nil {
+		return err
+	}
+
+	f, err := ParseFrame(payload)
+	if err != nil {
+		return err
+	}
+	return d.Add(f)
+}
+
+// AddImage scans the image, for example a camera shot of the animation, and adds frames of all the codes found
+// returns the number of codes read, the codes that aren't frames of the data are reported by the error
+// throws scan.NoCodeError if there are no codes in the image
+func (d *Decoder) AddImage(img image.Image) (int, error) {
+	codes, err := scan.Scan(img)
+	if err != nil {
+		return 0, err
+	}
+
+	var first error
+	for _, c := range codes {
+		if err := d.AddMarshaled(c.Data, c.Version); err != nil && first == nil {
+			first = err
+		}
+	}
+	return len(codes), first
+}
+
+// AddGIF reads the animation, for example the one made by render.WriteGIF, and adds the frames of every image of it
+// until the data is decoded, images without codes are skipped as lost frames
+// returns the number of codes read, the codes that aren't frames of the data are reported by the error
+// throws scan.NoCodeError if there are no codes in the whole animation
+func (d *Decoder) AddGIF(r io.Reader) (int, error) {
+	anim, err := gif.DecodeAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	// images may cover only a part of the previous ones
+	canvas := image.NewRGBA(image.Rect(0, 0, anim.Config.Width, anim.Config.Height))
+	count := 0
+	var first error
+	for _, frame := range anim.Image {
+		if d.Done() {
+			break
+		}
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		n, err := d.AddImage(canvas)
+		count += n
+		if err != nil && err != scan.NoCodeError && first == nil {
+			first = err
+		}
+	}
+	if count == 0 {
+		return 0, scan.NoCodeError
+	}
+	return count, first
+}
+
+// Progress returns the number of decoded blocks and the total number of blocks
+// total is 0 until the first frame is added to Decoder made by NewDecoder
+func (d *Decoder) Progress() (decoded, total int) {
+	return d.decoded, len(d.blocks)
+}
+
+// Done tells if all the blocks are decoded
+func (d *Decoder) Done() bool {
+	return d.blocks != nil && d.decoded == len(d.blocks)
+}
+
+// Data joins decoded blocks and verifies the checksum
+// throws NotDecodedError if more frames are needed
+func (d *Decoder) Data() ([]byte, error) {
+	if !d.Done() {
+		return nil, NotDecodedError
+	}
+
+	data := make([]byte, 0, len(d.blocks)*int(d.blockSize))
+	for _, b := range d.blocks {
+		data = append(data, b...)
+	}
+	data = data[:d.dataLen]
+
+	if crc32.ChecksumIEEE(data) != d.checksum {
+		return nil, ChecksumError
+	}
+	return data, nil
+}
@@ -0,0 +1,87 @@
+package fountain
+
+import (
+	"hash/crc32"
+	"math"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+// An Encoder makes any number of frames from the data
+type Encoder struct {
+	blocks    [][]byte
+	dataLen   uint32
+	blockSize uint16
+	checksum  uint32
+	cdf       []float64
+}
+
+// NewEncoder returns Encoder that splits data into blocks of blockSize bytes
+// the last block is padded with zeroes
+// throws TooManyBlocksError if data takes more than MaxBlocks blocks
+func NewEncoder(data []byte, blockSize int) (*Encoder, error) {
+	if blockSize < 1 || blockSize > math.MaxUint16 {
+		return nil, WrongBlockSizeError
+	}
+	if int64(len(data)) > math.MaxUint32 {
+		return nil, WrongFormatError
+	}
+	if blockCount(uint32(len(data)), uint16(blockSize)) > MaxBlocks {
+		return nil, TooManyBlocksError
+	}
+
+	e := &Encoder{
+		dataLen:   uint32(len(data)),
+		blockSize: uint16(blockSize),
+		checksum:  crc32.ChecksumIEEE(data),
+	}
+
+	k := blockCount(e.dataLen, e.blockSize)
+	e.blocks = make([][]byte, k)
+	for i := range e.blocks {
+		e.blocks[i] = make([]byte, blockSize)
+		copy(e.blocks[i], data[min(i*blockSize, len(data)):])
+	}
+	e.cdf = solitonCDF(k)
+
+	return e, nil
+}
+
+// BlockCount returns the number of source blocks, the receiver needs at least as many frames
+func (e *Encoder) BlockCount() int {
+	return len(e.blocks)
+}
+
+// Frame returns frame with chosen seed
+// seeds going one after another should be used
+func (e *Encoder) Frame(seed uint32) Frame {
+	payload := make([]byte, e.blockSize)
+	for _, i := range neighbours(seed, len(e.blocks), e.cdf) {
+		for j, b := range e.blocks[i] {
+			payload[j] ^= b
+		}
+	}
+
+	return Frame{DataLen: e.dataLen, BlockSize: e.blockSize, Seed: seed, Checksum: e.checksum, Payload: payload}
+}
+
+// Marshal marshals frame with chosen seed with ByteMarshaler
+func (e *Encoder) Marshal(seed uint32, lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) ([]byte, error) {
+	data, err := e.Frame(seed).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return qr_tools.NewByteMarshaler(lvl, ver).MarshalBytes(data)
+}
+
+// Matrix encodes frame with chosen seed into the matrix, matrices of seeds going one after another
+// are the frames of render.WriteGIF animation
+func (e *Encoder) Matrix(seed uint32, lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) ([][]bool, error) {
+	data, err := e.Marshal(seed, lvl, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	return qr_tools.Matrix(data, lvl, ver)
+}
@@ -0,0 +1,184 @@
+// Package fountain implements LT fountain codes for transferring data
+// that doesn't fit one QR code as a stream of frames
+//
+// the receiver needs slightly more frames than there are blocks,
+// but it can take any of them in any order, so lost frames don't have to be resent
+//
+// matrices of frames made by Encoder.Matrix can be animated with render.WriteGIF,
+// the receiver reads them back with Decoder.AddGIF or Decoder.AddImage for camera images
+package fountain
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+var (
+	WrongFormatError    = errors.New("data is not a fountain frame")
+	WrongBlockSizeError = errors.New("wrong block size")
+	TooManyBlocksError  = errors.New("data takes too many blocks")
+	OtherDataError      = errors.New("frame belongs to other data")
+	NotDecodedError     = errors.New("not enough frames to decode data")
+	ChecksumError       = errors.New("decoded data doesn't match its checksum")
+)
+
+const (
+	formatVersion = 1
+	// HeaderSize is the size of frame header: format version, data length, block size, seed and CRC-32 of data
+	HeaderSize = 1 + 4 + 2 + 4 + 4
+	// MaxBlocks is the greatest number of source blocks, it bounds the memory
+	// a decoder takes for the parameters of untrusted frames
+	MaxBlocks = 1 << 16
+
+	// robust soliton distribution parameters
+	solitonC     = 0.1
+	solitonDelta = 0.05
+)
+
+// Frame is a single encoded block together with the information needed to decode it
+type Frame struct {
+	DataLen   uint32
+	BlockSize uint16
+	// Seed chooses source blocks that are XORed into Payload
+	Seed     uint32
+	Checksum uint32
+	Payload  []byte
+}
+
+// MarshalBinary returns header followed by the payload
+func (f Frame) MarshalBinary() ([]byte, error) {
+	if f.BlockSize == 0 || len(f.Payload) != int(f.BlockSize) {
+		return nil, WrongFormatError
+	}
+
+	data := make([]byte, 0, HeaderSize+len(f.Payload))
+	data = append(data, formatVersion)
+	data = binary.BigEndian.AppendUint32(data, f.DataLen)
+	data = binary.BigEndian.AppendUint16(data, f.BlockSize)
+	data = binary.BigEndian.AppendUint32(data, f.Seed)
+	data = binary.BigEndian.AppendUint32(data, f.Checksum)
+	return append(data, f.Payload...), nil
+}
+
+// ParseFrame parses frame made by MarshalBinary
+func ParseFrame(data []byte) (Frame, error) {
+	if len(data) < HeaderSize || data[0] != formatVersion {
+		return Frame{}, WrongFormatError
+	}
+
+	f := Frame{
+		DataLen:   binary.BigEndian.Uint32(data[1:5]),
+		BlockSize: binary.BigEndian.Uint16(data[5:7]),
+		Seed:      binary.BigEndian.Uint32(data[7:11]),
+		Checksum:  binary.BigEndian.Uint32(data[11:15]),
+		Payload:   data[HeaderSize:],
+	}
+	if f.BlockSize == 0 || len(f.Payload) != int(f.BlockSize) {
+		return Frame{}, WrongFormatError
+	}
+	return f, nil
+}
+
+// blockCount returns the number of source blocks
+func blockCount(dataLen uint32, blockSize uint16) int {
+	return max(int((int64(dataLen)+int64(blockSize)-1)/int64(blockSize)), 1)
+}
+
+// random is xorshift32 generator, it's used instead of math/rand
+// so that the sequence never changes between Go versions
+type random struct {
+	state uint32
+}
+
+func newRandom(seed uint32) *random {
+	r := &random{state: seed*0x9e3779b9 + 0x7f4a7c15}
+	if r.state == 0 {
+		r.state = 1
+	}
+	return r
+}
+
+func (r *random) next() uint32 {
+	r.state ^= r.state << 13
+	r.state ^= r.state >> 17
+	r.state ^= r.state << 5
+	return r.state
+}
+
+// float returns number in [0, 1)
+func (r *random) float() float64 {
+	return float64(r.next()) / (1 << 32)
+}
+
+// solitonCDF returns cumulative robust soliton distribution of degrees 1..k
+func solitonCDF(k int) []float64 {
+	rho := make([]float64, k+1)
+	rho[1] = 1 / float64(k)
+	for d := 2; d <= k; d++ {
+		rho[d] = 1 / float64(d*(d-1))
+	}
+
+	s := solitonC * math.Log(float64(k)/solitonDelta) * math.Sqrt(float64(k))
+	spike := max(min(int(float64(k)/s), k), 1)
+	tau := make([]float64, k+1)
+	for d := 1; d < spike; d++ {
+		tau[d] = s / float64(k*d)
+	}
+	tau[spike] = s * math.Log(s/solitonDelta) / float64(k)
+
+	cdf := make([]float64, k+1)
+	for d := 1; d <= k; d++ {
+		cdf[d] = cdf[d-1] + rho[d] + max(tau[d], 0)
+	}
+	for d := range cdf {
+		cdf[d] /= cdf[k]
+	}
+	return cdf
+}
+
+// neighbours returns indexes of source blocks that frame with seed consists of
+// the first k seeds carry source blocks as they are, so the lossless transfer needs exactly k frames
+func neighbours(seed uint32, k int, cdf []float64) []int {
+	if seed < uint32(k) {
+		return []int{int(seed)}
+	}
+
+	r := newRandom(seed)
+	u := r.float()
+	degree := 1
+	for degree < k && cdf[degree] <= u {
+		degree++
+	}
+
+	chosen := make(map[int]bool, degree)
+	result := make([]int, 0, degree)
+	for len(result) < degree {
+		i := int(r.next() % uint32(k))
+		if !chosen[i] {
+			chosen[i] = true
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// BlockSize returns the greatest block size that fits chosen version and ErrorCorrectionLevel in byte mode
+func BlockSize(lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) (int, error) {
+	capacity, err := qr_tools.DataCapacity(lvl, ver)
+	if err != nil {
+		return 0, err
+	}
+	overhead, err := qr_tools.NewByteSegment(nil).BitLength(ver)
+	if err != nil {
+		return 0, err
+	}
+
+	size := min(int(capacity-overhead)/8-HeaderSize, math.MaxUint16)
+	if size < 1 {
+		return 0, WrongBlockSizeError
+	}
+	return size, nil
+}
@@ -0,0 +1,234 @@
+package fountain
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"math"
+	"reflect"
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+	"github.com/rinnothing/qr-tools/render"
+	"github.com/rinnothing/qr-tools/scan"
+)
+
+func TestFrame_MarshalBinary(t *testing.T) {
+	f := Frame{DataLen: 10, BlockSize: 4, Seed: 7, Checksum: 0xdeadbeef, Payload: []byte{1, 2, 3, 4}}
+
+	data, err := f.MarshalBinary()
+	if err != nil || len(data) != HeaderSize+4 {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if parsed, err := ParseFrame(data); err != nil || !reflect.DeepEqual(parsed, f) {
+		t.Errorf("Parsed %v instead of %v: %v", parsed, f, err)
+	}
+
+	for _, data := range [][]byte{nil, data[:len(data)-1], append([]byte{2}, data[1:]...)} {
+		if _, err := ParseFrame(data); err != WrongFormatError {
+			t.Errorf("Wrong frame %x is parsed with %v", data, err)
+		}
+	}
+}
+
+func TestSolitonCDF(t *testing.T) {
+	for _, k := range []int{1, 2, 10, 1000} {
+		cdf := solitonCDF(k)
+		for d := 1; d <= k; d++ {
+			if cdf[d] < cdf[d-1] {
+				t.Fatalf("Distribution of %d blocks decreases at degree %d", k, d)
+			}
+		}
+		if cdf[k] != 1 {
+			t.Errorf("Distribution of %d blocks sums to %f", k, cdf[k])
+		}
+	}
+}
+
+func TestDecoder_Lossless(t *testing.T) {
+	data := []byte("The quick brown fox jumps over the lazy dog, again and again and again")
+	e, err := NewEncoder(data, 8)
+	if err != nil {
+		t.Fatalf("Failed to make encoder: %v", err)
+	}
+
+	d := NewDecoder()
+	if decoded, total := d.Progress(); decoded != 0 || total != 0 {
+		t.Errorf("Empty decoder progress is %d/%d", decoded, total)
+	}
+
+	// the first frames carry source blocks, so exactly BlockCount of them are needed
+	for seed := e.BlockCount() - 1; seed >= 0; seed-- {
+		if _, err := d.Data(); err != NotDecodedError {
+			t.Errorf("Data is returned with %v before all the frames", err)
+		}
+		if err := d.Add(e.Frame(uint32(seed))); err != nil {
+			t.Fatalf("Failed to add frame %d: %v", seed, err)
+		}
+	}
+
+	if decoded, total := d.Progress(); decoded != total || total != e.BlockCount() {
+		t.Errorf("Progress is %d/%d after all the frames", decoded, total)
+	}
+	if decodedData, err := d.Data(); err != nil || string(decodedData) != string(data) {
+		t.Errorf("Decoded %q: %v", decodedData, err)
+	}
+}
+
+func TestDecoder_Lossy(t *testing.T) {
+	data := make([]byte, 5000)
+	for i := range data {
+		data[i] = byte(i * 31)
+	}
+	e, _ := NewEncoder(data, 50)
+
+	// none of the source blocks come through, only the encoded ones
+	d := NewDecoder()
+	seed := uint32(e.BlockCount())
+	for ; !d.Done(); seed++ {
+		if seed > uint32(4*e.BlockCount()) {
+			decoded, total := d.Progress()
+			t.Fatalf("Only %d/%d blocks are decoded after %d frames", decoded, total, seed)
+		}
+		if err := d.Add(e.Frame(seed)); err != nil {
+			t.Fatalf("Failed to add frame: %v", err)
+		}
+	}
+
+	if decoded, err := d.Data(); err != nil || string(decoded) != string(data) {
+		t.Errorf("Decoded data differs: %v", err)
+	}
+
+	other, _ := NewEncoder([]byte("other"), 50)
+	if err := d.Add(other.Frame(0)); err != OtherDataError {
+		t.Errorf("Frame of other data is added with %v", err)
+	}
+}
+
+func TestDecoder_Untrusted(t *testing.T) {
+	data := []byte("The quick brown fox jumps over the lazy dog")
+	e, _ := NewEncoder(data, 8)
+	frame := e.Frame(0)
+
+	// malformed and too costly frames don't choose the data
+	d := NewDecoder()
+	if err := d.Add(Frame{DataLen: frame.DataLen, BlockSize: 8, Payload: []byte{1}}); err != WrongFormatError {
+		t.Errorf("Malformed frame is added with %v", err)
+	}
+	if err := d.Add(Frame{DataLen: math.MaxUint32, BlockSize: 1, Payload: []byte{1}}); err != TooManyBlocksError {
+		t.Errorf("Frame of %d blocks is added with %v", uint32(math.MaxUint32), err)
+	}
+	if _, total := d.Progress(); total != 0 {
+		t.Errorf("Rejected frames choose data of %d blocks", total)
+	}
+
+	// expected parameters are given up front
+	d, err := NewDecoderFor(uint32(len(data)), 8)
+	if err != nil {
+		t.Fatalf("Failed to make decoder: %v", err)
+	}
+	if _, total := d.Progress(); total != e.BlockCount() {
+		t.Errorf("Decoder expects %d blocks instead of %d", total, e.BlockCount())
+	}
+	other, _ := NewEncoder([]byte("other"), 8)
+	if err := d.Add(other.Frame(0)); err != OtherDataError {
+		t.Errorf("Frame of other data is added with %v", err)
+	}
+	for seed := 0; seed < e.BlockCount(); seed++ {
+		if err := d.Add(e.Frame(uint32(seed))); err != nil {
+			t.Fatalf("Failed to add frame %d: %v", seed, err)
+		}
+	}
+	if decoded, err := d.Data(); err != nil || string(decoded) != string(data) {
+		t.Errorf("Decoded %q: %v", decoded, err)
+	}
+
+	if _, err := NewDecoderFor(math.MaxUint32, 1); err != TooManyBlocksError {
+		t.Errorf("Decoder for %d blocks is made with %v", uint32(math.MaxUint32), err)
+	}
+	if _, err := NewDecoderFor(10, 0); err != WrongBlockSizeError {
+		t.Errorf("Decoder for empty blocks is made with %v", err)
+	}
+	if _, err := NewEncoder(make([]byte, MaxBlocks+1), 1); err != TooManyBlocksError {
+		t.Errorf("Encoder for %d blocks is made with %v", MaxBlocks+1, err)
+	}
+}
+
+func TestEncoder_Marshal(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	size, err := BlockSize(qr_tools.M, 10)
+	if err != nil {
+		t.Fatalf("Failed to get block size: %v", err)
+	}
+	e, _ := NewEncoder(data, size)
+
+	d := NewDecoder()
+	for seed := uint32(1); !d.Done(); seed++ {
+		code, err := e.Marshal(seed, qr_tools.M, 10)
+		if err != nil {
+			t.Fatalf("Failed to marshal: %v", err)
+		}
+		if err := d.AddMarshaled(code, 10); err != nil {
+			t.Fatalf("Failed to unmarshal: %v", err)
+		}
+	}
+
+	if decoded, err := d.Data(); err != nil || string(decoded) != string(data) {
+		t.Errorf("Decoded data differs: %v", err)
+	}
+}
+
+func TestDecoder_AddGIF(t *testing.T) {
+	data := make([]byte, 600)
+	for i := range data {
+		data[i] = byte(i * 3)
+	}
+
+	size, err := BlockSize(qr_tools.M, 5)
+	if err != nil {
+		t.Fatalf("Failed to get block size: %v", err)
+	}
+	e, _ := NewEncoder(data, size)
+
+	// four times as many frames as blocks, every third one is lost
+	matrices := make([][][]bool, 0)
+	for seed := uint32(0); seed < uint32(4*e.BlockCount()); seed++ {
+		if seed%3 == 1 {
+			continue
+		}
+		m, err := e.Matrix(seed, qr_tools.M, 5)
+		if err != nil {
+			t.Fatalf("Failed to encode frame %d: %v", seed, err)
+		}
+		matrices = append(matrices, m)
+	}
+	opts := render.DefaultOptions()
+	opts.Scale = 3
+
+	var buf bytes.Buffer
+	if err := render.WriteGIF(&buf, matrices, opts, 10); err != nil {
+		t.Fatalf("Failed to animate: %v", err)
+	}
+
+	d := NewDecoder()
+	n, err := d.AddGIF(&buf)
+	if err != nil || n == 0 {
+		t.Fatalf("Failed to read animation: %d, %v", n, err)
+	}
+	if decoded, err := d.Data(); err != nil || !bytes.Equal(decoded, data) {
+		t.Errorf("Decoded data differs: %v", err)
+	}
+
+	blank := &gif.GIF{Image: []*image.Paletted{image.NewPaletted(image.Rect(0, 0, 50, 50), color.Palette{color.White})}, Delay: []int{10}}
+	buf.Reset()
+	_ = gif.EncodeAll(&buf, blank)
+	if _, err := NewDecoder().AddGIF(&buf); !errors.Is(err, scan.NoCodeError) {
+		t.Errorf("Animation without codes is read with %v", err)
+	}
+}
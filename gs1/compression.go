@@ -0,0 +1,236 @@
+package gs1
+
+import (
+	"math/big"
+	"math/bits"
+	"strings"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+// base64URL is the alphabet of compressed digital links, a character takes 6 bits
+const base64URL = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// encoding is enum that
+// shows how characters of alphanumeric value are compressed, it takes 3 bits
+type encoding uint64
+
+const (
+	digitsEncoding encoding = iota
+	lowerHexEncoding
+	upperHexEncoding
+	base64Encoding
+	asciiEncoding
+)
+
+// encodingBits is the length of encoding indicator
+const encodingBits = 3
+
+// alphabets are characters of encodings, ASCII has no alphabet and takes 7 bits per character
+var alphabets = map[encoding]string{
+	lowerHexEncoding: "0123456789abcdef",
+	upperHexEncoding: "0123456789ABCDEF",
+	base64Encoding:   base64URL,
+}
+
+// characterBits are the bits taken by a character of encoding, digits are encoded together
+var characterBits = map[encoding]uint{lowerHexEncoding: 4, upperHexEncoding: 4, base64Encoding: 6, asciiEncoding: 7}
+
+// chooseEncoding returns the cheapest encoding that can hold value
+func chooseEncoding(value string) encoding {
+	if isDigits(value) {
+		return digitsEncoding
+	}
+	for _, e := range []encoding{lowerHexEncoding, upperHexEncoding, base64Encoding} {
+		fits := true
+		for i := 0; i < len(value) && fits; i++ {
+			fits = strings.IndexByte(alphabets[e], value[i]) != -1
+		}
+		if fits {
+			return e
+		}
+	}
+	return asciiEncoding
+}
+
+// digitsBits returns the number of bits n digits take as a binary number: ceil(n * log2(10))
+func digitsBits(n int) uint {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	return uint(limit.Sub(limit, big.NewInt(1)).BitLen())
+}
+
+// lengthBits returns the length of length indicator of value that is at most maxLen characters long
+func lengthBits(maxLen int) uint {
+	return uint(bits.Len(uint(maxLen)))
+}
+
+// writeDigits writes digits as a binary number of digitsBits(len(digits)) bits
+func writeDigits(bw *qr_tools.BitWriter, digits string) {
+	value, _ := new(big.Int).SetString("0"+digits, 10)
+	for i := int(digitsBits(len(digits))) - 1; i >= 0; i-- {
+		bw.WriteBits(uint64(value.Bit(i)), 1)
+	}
+}
+
+// readDigits reads n digits written by writeDigits
+func readDigits(br *qr_tools.BitReader, n int) (string, error) {
+	value := new(big.Int)
+	for i := uint(0); i < digitsBits(n); i++ {
+		bit, err := br.ReadBits(1)
+		if err != nil {
+			return "", WrongURIError
+		}
+		value.Lsh(value, 1).SetBit(value, 0, uint(bit))
+	}
+
+	digits := value.String()
+	if len(digits) > n {
+		return "", WrongURIError
+	}
+	return strings.Repeat("0", n-len(digits)) + digits, nil
+}
+
+// writeElement writes application identifier as 4 bit digits followed by its value:
+// numeric values are binary numbers, variable length ones are preceded by length indicator,
+// alphanumeric values are preceded by encoding indicator too
+func writeElement(bw *qr_tools.BitWriter, ai, value string) {
+	for i := 0; i < len(ai); i++ {
+		bw.WriteBits(uint64(ai[i]-'0'), 4)
+	}
+
+	f := knownAIs[ai]
+	if f.set == numeric {
+		if f.minLen != f.maxLen {
+			bw.WriteBits(uint64(len(value)), lengthBits(f.maxLen))
+		}
+		writeDigits(bw, value)
+		return
+	}
+
+	e := chooseEncoding(value)
+	bw.WriteBits(uint64(e), encodingBits)
+	bw.WriteBits(uint64(len(value)), lengthBits(f.maxLen))
+	if e == digitsEncoding {
+		writeDigits(bw, value)
+		return
+	}
+	for i := 0; i < len(value); i++ {
+		ch := uint64(value[i])
+		if e != asciiEncoding {
+			ch = uint64(strings.IndexByte(alphabets[e], value[i]))
+		}
+		bw.WriteBits(ch, characterBits[e])
+	}
+}
+
+// readElement reads application identifier and its value written by writeElement
+func readElement(br *qr_tools.BitReader) (string, string, error) {
+	ai := ""
+	var f aiFormat
+	for found := false; !found; {
+		// application identifiers are 2 to 4 digits long
+		if len(ai) == 4 {
+			return "", "", UnknownAIError
+		}
+		digit, err := br.ReadBits(4)
+		if err != nil || digit > 9 {
+			return "", "", WrongURIError
+		}
+		ai += string(rune('0' + digit))
+		f, found = knownAIs[ai]
+		found = found && len(ai) >= 2
+	}
+
+	if f.set == numeric {
+		n := f.maxLen
+		if f.minLen != f.maxLen {
+			length, err := br.ReadBits(lengthBits(f.maxLen))
+			if err != nil {
+				return "", "", WrongURIError
+			}
+			n = int(length)
+		}
+		value, err := readDigits(br, n)
+		return ai, value, err
+	}
+
+	header, err := br.ReadBits(encodingBits + lengthBits(f.maxLen))
+	if err != nil {
+		return "", "", WrongURIError
+	}
+	e, n := encoding(header>>lengthBits(f.maxLen)), int(header&(1<<lengthBits(f.maxLen)-1))
+	switch {
+	case e == digitsEncoding:
+		value, err := readDigits(br, n)
+		return ai, value, err
+	case e > asciiEncoding:
+		return "", "", WrongURIError
+	}
+
+	sb := strings.Builder{}
+	for i := 0; i < n; i++ {
+		ch, err := br.ReadBits(characterBits[e])
+		if err != nil {
+			return "", "", WrongURIError
+		}
+		if e != asciiEncoding {
+			ch = uint64(alphabets[e][ch])
+		}
+		sb.WriteByte(byte(ch))
+	}
+	return ai, sb.String(), nil
+}
+
+// compress returns application identifiers in chosen order packed into bits and written with base64url alphabet,
+// bits are padded with zeroes to a multiple of 6
+func compress(ais []string, values map[string]string) string {
+	bw := qr_tools.NewBitWriter()
+	for _, ai := range ais {
+		writeElement(bw, ai, values[ai])
+	}
+	bw.WriteBits(0, (6-bw.Len()%6)%6)
+
+	br := qr_tools.NewBitReader(bw.Bytes())
+	sb := strings.Builder{}
+	for i := uint(0); i < bw.Len()/6; i++ {
+		ch, _ := br.ReadBits(6)
+		sb.WriteByte(base64URL[ch])
+	}
+	return sb.String()
+}
+
+// decompress reverses compress
+// throws WrongURIError if str isn't compressed digital link
+func decompress(str string) (map[string]string, error) {
+	bw := qr_tools.NewBitWriter()
+	for i := 0; i < len(str); i++ {
+		ch := strings.IndexByte(base64URL, str[i])
+		if ch == -1 {
+			return nil, WrongURIError
+		}
+		bw.WriteBits(uint64(ch), 6)
+	}
+
+	br := qr_tools.NewBitReader(bw.Bytes())
+	// the last byte may be padded more than the last character
+	left := func() int {
+		return int(br.Remaining()) - (len(bw.Bytes())*8 - int(bw.Len()))
+	}
+	ais := make(map[string]string)
+	// the shortest element takes more than padding of the last character
+	for left() >= 8 {
+		ai, value, err := readElement(br)
+		if err != nil {
+			return nil, err
+		}
+		if left() < 0 {
+			return nil, WrongURIError
+		}
+		ais[ai] = value
+	}
+	if padding, _ := br.ReadBits(br.Remaining()); padding != 0 || len(ais) == 0 {
+		return nil, WrongURIError
+	}
+
+	return ais, nil
+}
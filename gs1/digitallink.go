@@ -0,0 +1,296 @@
+package gs1
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+// DefaultDomain is the domain used by DigitalLink when none is given
+const DefaultDomain = "id.gs1.org"
+
+var (
+	NoPrimaryKeyError = errors.New("no primary key in digital link")
+	WrongURIError     = errors.New("uri is not a digital link")
+)
+
+// primary keys and the order of their qualifiers in URI path
+var primaryKeys = map[string][]string{
+	"01":   {"22", "10", "21"}, // GTIN
+	"00":   {},                 // SSCC
+	"414":  {"254"},            // GLN
+	"415":  {"8020"},           // PAY TO
+	"8004": {},                 // GIAI
+}
+
+// A DigitalLink can build GS1 Digital Link URI
+// from the map of application identifiers to their values
+type DigitalLink struct {
+	// Domain is the resolver domain, DefaultDomain is used when empty
+	Domain string
+	AIs    map[string]string
+}
+
+// NewDigitalLink returns DigitalLink with DefaultDomain
+// and chosen application identifiers
+func NewDigitalLink(ais map[string]string) *DigitalLink {
+	return &DigitalLink{Domain: DefaultDomain, AIs: ais}
+}
+
+// split validates AIs and splits them into path (primary key with qualifiers) and attributes
+func (dl *DigitalLink) split() (path []string, attrs []string, err error) {
+	for ai, value := range dl.AIs {
+		if err := Validate(ai, value); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// choosing the first primary key in fixed order, so that result is always the same
+	var qualifiers []string
+	for _, pk := range []string{"01", "00", "414", "415", "8004"} {
+		if _, ok := dl.AIs[pk]; ok {
+			path = []string{pk}
+			qualifiers = primaryKeys[pk]
+			break
+		}
+	}
+	if path == nil {
+		return nil, nil, NoPrimaryKeyError
+	}
+
+	inPath := map[string]bool{path[0]: true}
+	for _, q := range qualifiers {
+		if _, ok := dl.AIs[q]; ok {
+			path = append(path, q)
+			inPath[q] = true
+		}
+	}
+
+	for ai := range dl.AIs {
+		if !inPath[ai] {
+			attrs = append(attrs, ai)
+		}
+	}
+	sort.Strings(attrs)
+
+	return path, attrs, nil
+}
+
+func (dl *DigitalLink) domain() string {
+	if dl.Domain == "" {
+		return DefaultDomain
+	}
+	return dl.Domain
+}
+
+// URI returns canonical Digital Link URI
+// primary key and its qualifiers go to the path, other application identifiers go to the query
+func (dl *DigitalLink) URI() (string, error) {
+	path, attrs, err := dl.split()
+	if err != nil {
+		return "", err
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("https://")
+	sb.WriteString(dl.domain())
+	for _, ai := range path {
+		sb.WriteByte('/')
+		sb.WriteString(ai)
+		sb.WriteByte('/')
+		sb.WriteString(url.PathEscape(dl.AIs[ai]))
+	}
+
+	for i, ai := range attrs {
+		if i == 0 {
+			sb.WriteByte('?')
+		} else {
+			sb.WriteByte('&')
+		}
+		sb.WriteString(ai)
+		sb.WriteByte('=')
+		sb.WriteString(url.QueryEscape(dl.AIs[ai]))
+	}
+
+	return sb.String(), nil
+}
+
+// isURIAlphanumeric tells if ch can be put into URI as it is and is also alphanumeric QR character
+func isURIAlphanumeric(ch byte) bool {
+	return ch >= '0' && ch <= '9' || ch >= 'A' && ch <= 'Z' || ch == '-' || ch == '.'
+}
+
+// alphanumericEscape percent-encodes all the characters that aren't alphanumeric in QR terms
+// percent-encoding uses uppercase hex digits, so it doesn't break alphanumeric mode
+func alphanumericEscape(str string) string {
+	const hex = "0123456789ABCDEF"
+
+	sb := strings.Builder{}
+	for i := 0; i < len(str); i++ {
+		if isURIAlphanumeric(str[i]) {
+			sb.WriteByte(str[i])
+		} else {
+			sb.WriteByte('%')
+			sb.WriteByte(hex[str[i]>>4])
+			sb.WriteByte(hex[str[i]&15])
+		}
+	}
+
+	return sb.String()
+}
+
+// CompressedURI returns Digital Link URI in the compressed form of GS1 Digital Link compression:
+// application identifiers are written as 4 bit digits, numeric values as binary numbers,
+// alphanumeric values with the cheapest of digits, hex, base64url and 7 bit ASCII encodings,
+// and the bits are put into the path with base64url alphabet; optimisation codes aren't used
+func (dl *DigitalLink) CompressedURI() (string, error) {
+	path, attrs, err := dl.split()
+	if err != nil {
+		return "", err
+	}
+
+	return "https://" + dl.domain() + "/" + compress(append(path, attrs...), dl.AIs), nil
+}
+
+// marshaledBits returns the number of bits str takes when marshaled in alphanumeric mode, or in byte mode if it can't be
+func marshaledBits(str string) int {
+	if _, err := qr_tools.NewAlphanumericSegment(str); err == nil {
+		return (11*len(str) + 1) / 2
+	}
+	return 8 * len(str)
+}
+
+// AlphanumericURI returns Digital Link URI that is the cheapest to marshal:
+// compressed one (see CompressedURI) or uncompressed one, whichever takes less bits
+//
+// scheme and domain are case-insensitive, so when there are no attributes and no lowercase letters
+// in values the uncompressed URI is uppercased and percent-encoded to fit alphanumeric mode,
+// otherwise canonical URI is taken
+func (dl *DigitalLink) AlphanumericURI() (string, error) {
+	uncompressed, err := dl.alphanumericURI()
+	if err != nil {
+		return "", err
+	}
+	compressed, err := dl.CompressedURI()
+	if err != nil {
+		return "", err
+	}
+
+	if marshaledBits(compressed) < marshaledBits(uncompressed) {
+		return compressed, nil
+	}
+	return uncompressed, nil
+}
+
+// alphanumericURI returns uncompressed URI that fits alphanumeric mode if it's possible, or canonical URI
+func (dl *DigitalLink) alphanumericURI() (string, error) {
+	path, attrs, err := dl.split()
+	if err != nil {
+		return "", err
+	}
+	// query separators aren't alphanumeric, so there is no gain
+	if len(attrs) != 0 {
+		return dl.URI()
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("HTTPS://")
+	sb.WriteString(strings.ToUpper(dl.domain()))
+	for _, ai := range path {
+		value := dl.AIs[ai]
+		if strings.ToUpper(value) != value {
+			return dl.URI()
+		}
+
+		sb.WriteByte('/')
+		sb.WriteString(ai)
+		sb.WriteByte('/')
+		sb.WriteString(alphanumericEscape(value))
+	}
+
+	return sb.String(), nil
+}
+
+// validateParsed checks the value of application identifier found in URI,
+// unknown application identifiers are taken as generic ones of up to 90 characters of set 82
+func validateParsed(ai, value string) error {
+	if _, ok := knownAIs[ai]; ok {
+		return Validate(ai, value)
+	}
+	if len(ai) < 2 || len(ai) > 4 || !isDigits(ai) {
+		return UnknownAIError
+	}
+	return variableAlphanumeric(90).validate(value)
+}
+
+// ParseDigitalLink turns decoded Digital Link URI back into the map of application identifiers to their values
+// both uncompressed and compressed URIs are parsed, all the values are validated,
+// unknown application identifiers are kept as generic ones and GTIN shorter than 14 digits is padded with zeroes
+func ParseDigitalLink(uri string) (map[string]string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, WrongURIError
+	}
+
+	// path can have any custom prefix, so looking for the primary key from the start
+	segments := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	start := -1
+	for i := 0; i+1 < len(segments); i++ {
+		if _, ok := primaryKeys[segments[i]]; ok {
+			start = i
+			break
+		}
+	}
+
+	var ais map[string]string
+	if start == -1 {
+		// compressed path is the last segment without primary key before it
+		if ais, err = decompress(segments[len(segments)-1]); err != nil {
+			return nil, NoPrimaryKeyError
+		}
+		found := false
+		for pk := range primaryKeys {
+			_, ok := ais[pk]
+			found = found || ok
+		}
+		if !found {
+			return nil, NoPrimaryKeyError
+		}
+	} else {
+		segments = segments[start:]
+		if len(segments)%2 != 0 {
+			return nil, WrongURIError
+		}
+
+		ais = make(map[string]string)
+		for i := 0; i < len(segments); i += 2 {
+			value, err := url.PathUnescape(segments[i+1])
+			if err != nil {
+				return nil, WrongURIError
+			}
+			ais[segments[i]] = value
+		}
+	}
+
+	// non numeric keys are not application identifiers, they're just skipped
+	for key, values := range u.Query() {
+		if isDigits(key) && len(values) > 0 {
+			ais[key] = values[0]
+		}
+	}
+
+	if gtin, ok := ais["01"]; ok && len(gtin) < 14 {
+		ais["01"] = strings.Repeat("0", 14-len(gtin)) + gtin
+	}
+
+	for ai, value := range ais {
+		if err := validateParsed(ai, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return ais, nil
+}
@@ -0,0 +1,121 @@
+package gs1
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDigitalLink_URI(t *testing.T) {
+	dl := NewDigitalLink(map[string]string{"01": "09506000134352", "21": "XYZ/1", "10": "ABC", "17": "251231", "3103": "000750"})
+	uri, err := dl.URI()
+	if err != nil {
+		t.Fatalf("Failed to build URI: %v", err)
+	}
+
+	expected := "https://id.gs1.org/01/09506000134352/10/ABC/21/XYZ%2F1?17=251231&3103=000750"
+	if uri != expected {
+		t.Errorf("Built URI %s instead of %s", uri, expected)
+	}
+
+	if _, err := NewDigitalLink(map[string]string{"10": "ABC"}).URI(); err != NoPrimaryKeyError {
+		t.Errorf("URI without primary key is built")
+	}
+	if _, err := NewDigitalLink(map[string]string{"01": "09506000134353"}).URI(); err != CheckDigitError {
+		t.Errorf("URI with wrong GTIN is built")
+	}
+}
+
+func TestDigitalLink_AlphanumericURI(t *testing.T) {
+	cases := []struct {
+		ais      map[string]string
+		expected string
+	}{
+		{map[string]string{"01": "09506000134352", "10": "AB_C"}, "HTTPS://ID.GS1.ORG/01/09506000134352/10/AB%5FC"},
+		// lowercase letters and query make compressed URI cheaper
+		{map[string]string{"01": "09506000134352", "10": "abc"}, "https://id.gs1.org/ARFKk4XBoCBHV4"},
+		{map[string]string{"01": "09506000134352", "17": "251231"}, "https://id.gs1.org/ARFKk4XBoC56q-"},
+	}
+
+	for _, c := range cases {
+		uri, err := NewDigitalLink(c.ais).AlphanumericURI()
+		if err != nil {
+			t.Errorf("Failed to build URI: %v", err)
+		} else if uri != c.expected {
+			t.Errorf("Built alphanumeric URI %s instead of %s", uri, c.expected)
+		}
+	}
+}
+
+func TestDigitalLink_CompressedURI(t *testing.T) {
+	// numeric values of fixed and variable length and alphanumeric ones of every encoding
+	ais := map[string]string{
+		"01": "09506000134352", "3103": "000750", "30": "12",
+		"10": "123", "21": "0f3a", "22": "0F3A", "250": "Ab-_9", "400": "A/B%C",
+	}
+	uri, err := NewDigitalLink(ais).CompressedURI()
+	if err != nil {
+		t.Fatalf("Failed to build URI: %v", err)
+	}
+	// 01 with GTIN goes first: 0000 0001 and 47 bits of the number
+	if !strings.HasPrefix(uri, "https://id.gs1.org/ARFKk4XBo") {
+		t.Errorf("Compressed URI %s doesn't start with GTIN", uri)
+	}
+
+	parsed, err := ParseDigitalLink(uri)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", uri, err)
+	}
+	if !reflect.DeepEqual(parsed, ais) {
+		t.Errorf("Parsed %v instead of %v", parsed, ais)
+	}
+
+	for _, uri := range []string{"https://id.gs1.org/ARFKk4XBo", "https://id.gs1.org/ARFKk4XBoCBHV4!", "https://id.gs1.org/EBFKk4XBoCBHV4"} {
+		if _, err := ParseDigitalLink(uri); err == nil {
+			t.Errorf("Broken compressed URI %s is parsed without error", uri)
+		}
+	}
+}
+
+func TestParseDigitalLink(t *testing.T) {
+	ais := map[string]string{"01": "09506000134352", "21": "XYZ/1", "10": "AB_C", "17": "251231"}
+	dl := NewDigitalLink(ais)
+
+	for _, build := range []func() (string, error){dl.URI, dl.AlphanumericURI, dl.CompressedURI} {
+		uri, _ := build()
+		parsed, err := ParseDigitalLink(uri)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", uri, err)
+		}
+
+		if len(parsed) != len(ais) {
+			t.Errorf("Parsed %d elements instead of %d", len(parsed), len(ais))
+		}
+		for ai, value := range ais {
+			if parsed[ai] != value {
+				t.Errorf("Parsed (%s)%s instead of (%s)%s", ai, parsed[ai], ai, value)
+			}
+		}
+	}
+
+	parsed, err := ParseDigitalLink("https://example.com/some/prefix/01/9506000134352?foo=bar")
+	if err != nil {
+		t.Fatalf("Failed to parse URI with custom prefix: %v", err)
+	}
+	if parsed["01"] != "09506000134352" || len(parsed) != 1 {
+		t.Errorf("URI with custom prefix is parsed wrong: %v", parsed)
+	}
+
+	// unknown application identifiers are generic
+	parsed, err = ParseDigitalLink("https://id.gs1.org/01/09506000134352?7240=ABC&4300=Name")
+	if err != nil || parsed["7240"] != "ABC" || parsed["4300"] != "Name" {
+		t.Errorf("URI with unknown application identifiers is parsed as %v: %v", parsed, err)
+	}
+
+	for _, uri := range []string{"not a uri", "https://id.gs1.org/10/ABC", "https://id.gs1.org/01/09506000134353",
+		"https://id.gs1.org/01/09506000134352?12345=A", "https://id.gs1.org/01/09506000134352?7240=%01"} {
+		if _, err := ParseDigitalLink(uri); err == nil {
+			t.Errorf("Wrong URI %s is parsed without error", uri)
+		}
+	}
+}
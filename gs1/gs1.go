@@ -0,0 +1,310 @@
+// Package gs1 helps to build and parse GS1 element strings
+// that are put into QR codes with FNC1 in first position
+package gs1
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+var (
+	UnknownAIError    = errors.New("unknown application identifier")
+	WrongValueError   = errors.New("value doesn't match application identifier format")
+	CheckDigitError   = errors.New("wrong check digit")
+	WrongDateError    = errors.New("wrong date")
+	UnterminatedError = errors.New("data ends in the middle of element")
+)
+
+// charset tells what characters value can consist of
+type charset int
+
+const (
+	numeric charset = iota
+	// characters from GS1 encodable character set 82
+	alphanumeric
+)
+
+// aiFormat describes value of application identifier
+type aiFormat struct {
+	set charset
+	// minimum and maximum length of the value, they're equal for fixed length values
+	minLen, maxLen int
+	// value ends with mod 10 check digit
+	checkDigit bool
+	// value is a YYMMDD date
+	date bool
+}
+
+func fixedNumeric(n int) aiFormat {
+	return aiFormat{set: numeric, minLen: n, maxLen: n}
+}
+
+func variableNumeric(n int) aiFormat {
+	return aiFormat{set: numeric, minLen: 1, maxLen: n}
+}
+
+func variableAlphanumeric(n int) aiFormat {
+	return aiFormat{set: alphanumeric, minLen: 1, maxLen: n}
+}
+
+var (
+	checked = aiFormat{set: numeric, minLen: 14, maxLen: 14, checkDigit: true}
+	date    = aiFormat{set: numeric, minLen: 6, maxLen: 6, date: true}
+	gln     = aiFormat{set: numeric, minLen: 13, maxLen: 13, checkDigit: true}
+
+	// most used application identifiers, others are to be added when needed
+	// (taken from https://www.gs1.org/standards/barcodes/application-identifiers)
+	knownAIs = map[string]aiFormat{
+		"00":   {set: numeric, minLen: 18, maxLen: 18, checkDigit: true}, // SSCC
+		"01":   checked,                                                  // GTIN
+		"02":   checked,                                                  // CONTENT
+		"10":   variableAlphanumeric(20),                                 // BATCH/LOT
+		"11":   date,                                                     // PROD DATE
+		"12":   date,                                                     // DUE DATE
+		"13":   date,                                                     // PACK DATE
+		"15":   date,                                                     // BEST BEFORE
+		"16":   date,                                                     // SELL BY
+		"17":   date,                                                     // USE BY
+		"20":   fixedNumeric(2),                                          // VARIANT
+		"21":   variableAlphanumeric(20),                                 // SERIAL
+		"22":   variableAlphanumeric(20),                                 // CPV
+		"240":  variableAlphanumeric(30),                                 // ADDITIONAL ID
+		"241":  variableAlphanumeric(30),                                 // CUST. PART No.
+		"250":  variableAlphanumeric(30),                                 // SECONDARY SERIAL
+		"254":  variableAlphanumeric(20),                                 // GLN EXTENSION COMPONENT
+		"30":   variableNumeric(8),                                       // VAR. COUNT
+		"37":   variableNumeric(8),                                       // COUNT
+		"400":  variableAlphanumeric(30),                                 // ORDER NUMBER
+		"410":  gln,                                                      // SHIP TO LOC
+		"411":  gln,                                                      // BILL TO
+		"412":  gln,                                                      // PURCHASE FROM
+		"413":  gln,                                                      // SHIP FOR LOC
+		"414":  gln,                                                      // LOC No.
+		"415":  gln,                                                      // PAY TO
+		"420":  variableAlphanumeric(20),                                 // SHIP TO POST
+		"422":  fixedNumeric(3),                                          // ORIGIN
+		"8004": variableAlphanumeric(30),                                 // GIAI
+		"8020": variableAlphanumeric(25),                                 // REF No.
+		"90":   variableAlphanumeric(30),                                 // INTERNAL
+	}
+
+	// two digit prefixes of application identifiers with predefined length
+	// only they don't need separator after the value
+	predefinedLength = map[string]bool{
+		"00": true, "01": true, "02": true, "03": true, "04": true,
+		"11": true, "12": true, "13": true, "14": true, "15": true, "16": true, "17": true, "18": true, "19": true, "20": true,
+		"31": true, "32": true, "33": true, "34": true, "35": true, "36": true,
+		"41": true,
+	}
+)
+
+func init() {
+	// measures (net weight, length, etc.) are n6 and have decimal point position as the last digit of AI
+	for _, prefix := range []string{"310", "311", "312", "313", "314", "315", "316", "320", "330", "356", "357"} {
+		for d := '0'; d <= '5'; d++ {
+			knownAIs[prefix+string(d)] = fixedNumeric(6)
+		}
+	}
+	// amounts payable are n..15 with decimal point position as the last digit of AI
+	for _, prefix := range []string{"390", "392"} {
+		for d := '0'; d <= '9'; d++ {
+			knownAIs[prefix+string(d)] = variableNumeric(15)
+		}
+	}
+	// company internal information
+	for ai := 91; ai <= 99; ai++ {
+		knownAIs[string(rune('0'+ai/10))+string(rune('0'+ai%10))] = variableAlphanumeric(90)
+	}
+}
+
+func isDigits(str string) bool {
+	for i := 0; i < len(str); i++ {
+		if str[i] < '0' || str[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isCharset82 tells if str consists only of GS1 encodable character set 82
+func isCharset82(str string) bool {
+	for i := 0; i < len(str); i++ {
+		ch := str[i]
+		switch {
+		case ch >= '0' && ch <= '9', ch >= 'A' && ch <= 'Z', ch >= 'a' && ch <= 'z':
+		case strings.IndexByte("!\"%&'()*+,-./:;<=>?_", ch) != -1:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// CheckDigit calculates GS1 mod 10 check digit for given digits (without the check digit itself)
+func CheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		// weights go 3, 1, 3, 1... starting from the right
+		if i%2 == 0 {
+			sum += 3 * d
+		} else {
+			sum += d
+		}
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDate(str string) bool {
+	month := (str[2]-'0')*10 + str[3] - '0'
+	day := (str[4]-'0')*10 + str[5] - '0'
+
+	// day can be 00 meaning the last day of the month
+	return month >= 1 && month <= 12 && day <= 31
+}
+
+// validate checks if value suits format
+func (f aiFormat) validate(value string) error {
+	if len(value) < f.minLen || len(value) > f.maxLen {
+		return WrongValueError
+	}
+
+	switch f.set {
+	case numeric:
+		if !isDigits(value) {
+			return WrongValueError
+		}
+	case alphanumeric:
+		if !isCharset82(value) {
+			return WrongValueError
+		}
+	}
+
+	if f.checkDigit && CheckDigit(value[:len(value)-1]) != value[len(value)-1] {
+		return CheckDigitError
+	}
+	if f.date && !isDate(value) {
+		return WrongDateError
+	}
+
+	return nil
+}
+
+// Validate checks if value is correct for given application identifier
+func Validate(ai, value string) error {
+	f, ok := knownAIs[ai]
+	if !ok {
+		return UnknownAIError
+	}
+
+	return f.validate(value)
+}
+
+// needsSeparator tells if the value of ai should be terminated with separator when not the last
+func needsSeparator(ai string) bool {
+	return !predefinedLength[ai[:2]]
+}
+
+type element struct {
+	ai, value string
+}
+
+// A Builder builds GS1 element string from application identifiers and their values
+type Builder struct {
+	elements []element
+}
+
+// NewBuilder returns empty Builder
+func NewBuilder() *Builder {
+	return &Builder{elements: make([]element, 0)}
+}
+
+// Add validates and adds value of ai to the element string
+func (b *Builder) Add(ai, value string) error {
+	if err := Validate(ai, value); err != nil {
+		return err
+	}
+
+	b.elements = append(b.elements, element{ai: ai, value: value})
+	return nil
+}
+
+// String returns element string with qr_tools.GroupSeparator after variable-length values
+//
+// elements with predefined length are placed first, so that
+// less separators are needed
+func (b *Builder) String() string {
+	elements := make([]element, len(b.elements))
+	copy(elements, b.elements)
+	sort.SliceStable(elements, func(i, j int) bool {
+		return !needsSeparator(elements[i].ai) && needsSeparator(elements[j].ai)
+	})
+
+	sb := strings.Builder{}
+	for i, el := range elements {
+		sb.WriteString(el.ai)
+		sb.WriteString(el.value)
+		if i != len(elements)-1 && needsSeparator(el.ai) {
+			sb.WriteByte(qr_tools.GroupSeparator)
+		}
+	}
+
+	return sb.String()
+}
+
+// Marshal marshals element string using FNC1 in first position
+func (b *Builder) Marshal(lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) ([]byte, error) {
+	return qr_tools.NewFNC1FirstMarshaler(lvl, ver).MarshalString(b.String())
+}
+
+// matchAI finds the known application identifier that data starts with
+func matchAI(data string) (string, aiFormat, bool) {
+	// application identifiers are 2 to 4 digits long
+	for l := 2; l <= 4 && l <= len(data); l++ {
+		if f, ok := knownAIs[data[:l]]; ok {
+			return data[:l], f, true
+		}
+	}
+
+	return "", aiFormat{}, false
+}
+
+// Parse turns decoded GS1 element string back into the map of application identifiers to their values
+// all the values are validated
+func Parse(data string) (map[string]string, error) {
+	data = strings.TrimPrefix(data, string(qr_tools.GroupSeparator))
+
+	ais := make(map[string]string)
+	for len(data) > 0 {
+		ai, f, ok := matchAI(data)
+		if !ok {
+			return nil, UnknownAIError
+		}
+		data = data[len(ai):]
+
+		var value string
+		if needsSeparator(ai) {
+			end := strings.IndexByte(data, qr_tools.GroupSeparator)
+			if end == -1 {
+				end = len(data)
+			}
+			value, data = data[:end], strings.TrimPrefix(data[end:], string(qr_tools.GroupSeparator))
+		} else {
+			if len(data) < f.maxLen {
+				return nil, UnterminatedError
+			}
+			value, data = data[:f.maxLen], data[f.maxLen:]
+		}
+
+		if err := f.validate(value); err != nil {
+			return nil, err
+		}
+		ais[ai] = value
+	}
+
+	return ais, nil
+}
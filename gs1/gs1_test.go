@@ -0,0 +1,109 @@
+package gs1
+
+import (
+	"errors"
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+func TestCheckDigit(t *testing.T) {
+	for digits, check := range map[string]byte{
+		"0950600013435":     '2',
+		"1234567890123":     '1',
+		"0000000000000":     '0',
+		"00614141123456789": '0',
+	} {
+		if got := CheckDigit(digits); got != check {
+			t.Errorf("Check digit of %s is %c, but %c expected", digits, got, check)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		ai, value string
+		err       error
+	}{
+		{"01", "09506000134352", nil},
+		{"01", "09506000134353", CheckDigitError},
+		{"01", "0950600013435", WrongValueError},
+		{"00", "006141411234567890", nil},
+		{"17", "251200", nil},
+		{"17", "251300", WrongDateError},
+		{"10", "ABC123", nil},
+		{"10", "ABC 123", WrongValueError},
+		{"10", "123456789012345678901", WrongValueError},
+		{"3103", "000750", nil},
+		{"3922", "1999", nil},
+		{"7", "1", UnknownAIError},
+	}
+
+	for _, c := range cases {
+		if err := Validate(c.ai, c.value); !errors.Is(err, c.err) {
+			t.Errorf("Validating (%s)%s gave %v instead of %v", c.ai, c.value, err, c.err)
+		}
+	}
+}
+
+func TestBuilder_String(t *testing.T) {
+	b := NewBuilder()
+	for _, el := range [][2]string{{"10", "ABC123"}, {"01", "09506000134352"}, {"21", "XYZ"}, {"17", "251231"}} {
+		if err := b.Add(el[0], el[1]); err != nil {
+			t.Fatalf("Failed to add (%s)%s: %v", el[0], el[1], err)
+		}
+	}
+
+	expected := "0109506000134352" + "17251231" + "10ABC123" + string(qr_tools.GroupSeparator) + "21XYZ"
+	if s := b.String(); s != expected {
+		t.Errorf("Built element string %q instead of %q", s, expected)
+	}
+
+	if err := b.Add("01", "1"); err == nil {
+		t.Errorf("Wrong value was added to the builder")
+	}
+}
+
+func TestParse(t *testing.T) {
+	b := NewBuilder()
+	ais := map[string]string{"01": "09506000134352", "10": "LOT%1", "21": "12345", "3103": "000750", "422": "643"}
+	for ai, value := range ais {
+		if err := b.Add(ai, value); err != nil {
+			t.Fatalf("Failed to add (%s)%s: %v", ai, value, err)
+		}
+	}
+
+	parsed, err := Parse(b.String())
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %v", b.String(), err)
+	}
+	if len(parsed) != len(ais) {
+		t.Errorf("Parsed %d elements instead of %d", len(parsed), len(ais))
+	}
+	for ai, value := range ais {
+		if parsed[ai] != value {
+			t.Errorf("Parsed (%s)%s instead of (%s)%s", ai, parsed[ai], ai, value)
+		}
+	}
+
+	for _, data := range []string{"0109506000", "0109506000134353", "7712"} {
+		if _, err := Parse(data); err == nil {
+			t.Errorf("Wrong data %q is parsed without error", data)
+		}
+	}
+}
+
+func TestBuilder_Marshal(t *testing.T) {
+	b := NewBuilder()
+	_ = b.Add("01", "09506000134352")
+
+	data, err := b.Marshal(qr_tools.L, 1)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	expected, _ := qr_tools.NewFNC1FirstMarshaler(qr_tools.L, 1).MarshalString(b.String())
+	if string(data) != string(expected) {
+		t.Errorf("Builder marshaled data differently from FNC1Marshaler")
+	}
+}
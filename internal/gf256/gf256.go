@@ -0,0 +1,65 @@
+// Package gf256 implements arithmetic of GF(256) field
+// with the primitive polynomial x^8 + x^4 + x^3 + x^2 + 1 that QR codes use
+package gf256
+
+const polynomial = 0x11d
+
+var (
+	expTable [510]byte
+	logTable [256]byte
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		expTable[i], expTable[i+255] = byte(x), byte(x)
+		logTable[x] = byte(i)
+
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= polynomial
+		}
+	}
+}
+
+// Add returns a + b, subtraction is the same operation
+func Add(a, b byte) byte {
+	return a ^ b
+}
+
+// Exp returns 2^n
+func Exp(n int) byte {
+	return expTable[n%255]
+}
+
+// Log returns n such that 2^n = a, a must not be 0
+func Log(a byte) int {
+	if a == 0 {
+		panic("gf256: logarithm of zero")
+	}
+	return int(logTable[a])
+}
+
+// Mul returns a * b
+func Mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+// Div returns a / b, b must not be 0
+func Div(a, b byte) byte {
+	if b == 0 {
+		panic("gf256: division by zero")
+	}
+	if a == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+255-int(logTable[b])]
+}
+
+// Inv returns 1 / a, a must not be 0
+func Inv(a byte) byte {
+	return Div(1, a)
+}
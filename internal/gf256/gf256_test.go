@@ -0,0 +1,61 @@
+package gf256
+
+import (
+	"testing"
+)
+
+// slowMul multiplies polynomials and reduces them bit by bit
+func slowMul(a, b byte) byte {
+	var p int
+	x, y := int(a), int(b)
+	for y != 0 {
+		if y&1 != 0 {
+			p ^= x
+		}
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= polynomial
+		}
+		y >>= 1
+	}
+	return byte(p)
+}
+
+func TestMul(t *testing.T) {
+	for a := 0; a < 256; a++ {
+		for b := 0; b < 256; b++ {
+			if p := Mul(byte(a), byte(b)); p != slowMul(byte(a), byte(b)) {
+				t.Fatalf("%d * %d = %d instead of %d", a, b, p, slowMul(byte(a), byte(b)))
+			}
+		}
+	}
+}
+
+func TestDiv(t *testing.T) {
+	for a := 0; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			if Mul(Div(byte(a), byte(b)), byte(b)) != byte(a) {
+				t.Fatalf("%d / %d * %d isn't %d", a, b, b, a)
+			}
+		}
+	}
+}
+
+func TestExpLog(t *testing.T) {
+	// first values of QR code exponent table
+	expected := []byte{1, 2, 4, 8, 16, 32, 64, 128, 29, 58, 116, 232, 205, 135}
+	for i, e := range expected {
+		if Exp(i) != e {
+			t.Errorf("2^%d = %d instead of %d", i, Exp(i), e)
+		}
+	}
+
+	for a := 1; a < 256; a++ {
+		if Exp(Log(byte(a))) != byte(a) {
+			t.Errorf("2^log(%d) isn't %d", a, a)
+		}
+	}
+	if Exp(255) != 1 {
+		t.Errorf("2^255 isn't 1")
+	}
+}
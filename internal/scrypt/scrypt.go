@@ -0,0 +1,159 @@
+// Package scrypt implements scrypt key derivation function (RFC 7914)
+// together with PBKDF2-HMAC-SHA256 it's built on
+package scrypt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+var (
+	WrongParametersError = errors.New("wrong scrypt parameters")
+)
+
+// PBKDF2 derives keyLen bytes from password and salt with HMAC-SHA256 (RFC 8018)
+func PBKDF2(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+
+	key := make([]byte, 0, keyLen+sha256.Size)
+	u := make([]byte, sha256.Size)
+	t := make([]byte, sha256.Size)
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, block))
+		u = prf.Sum(u[:0])
+		copy(t, u)
+
+		for i := 1; i < iter; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		key = append(key, t...)
+	}
+
+	return key[:keyLen]
+}
+
+// salsa208 applies Salsa20/8 core to the 16 words of b
+func salsa208(b *[16]uint32) {
+	x := *b
+	for i := 0; i < 8; i += 2 {
+		// columns
+		x[4] ^= bits.RotateLeft32(x[0]+x[12], 7)
+		x[8] ^= bits.RotateLeft32(x[4]+x[0], 9)
+		x[12] ^= bits.RotateLeft32(x[8]+x[4], 13)
+		x[0] ^= bits.RotateLeft32(x[12]+x[8], 18)
+		x[9] ^= bits.RotateLeft32(x[5]+x[1], 7)
+		x[13] ^= bits.RotateLeft32(x[9]+x[5], 9)
+		x[1] ^= bits.RotateLeft32(x[13]+x[9], 13)
+		x[5] ^= bits.RotateLeft32(x[1]+x[13], 18)
+		x[14] ^= bits.RotateLeft32(x[10]+x[6], 7)
+		x[2] ^= bits.RotateLeft32(x[14]+x[10], 9)
+		x[6] ^= bits.RotateLeft32(x[2]+x[14], 13)
+		x[10] ^= bits.RotateLeft32(x[6]+x[2], 18)
+		x[3] ^= bits.RotateLeft32(x[15]+x[11], 7)
+		x[7] ^= bits.RotateLeft32(x[3]+x[15], 9)
+		x[11] ^= bits.RotateLeft32(x[7]+x[3], 13)
+		x[15] ^= bits.RotateLeft32(x[11]+x[7], 18)
+
+		// rows
+		x[1] ^= bits.RotateLeft32(x[0]+x[3], 7)
+		x[2] ^= bits.RotateLeft32(x[1]+x[0], 9)
+		x[3] ^= bits.RotateLeft32(x[2]+x[1], 13)
+		x[0] ^= bits.RotateLeft32(x[3]+x[2], 18)
+		x[6] ^= bits.RotateLeft32(x[5]+x[4], 7)
+		x[7] ^= bits.RotateLeft32(x[6]+x[5], 9)
+		x[4] ^= bits.RotateLeft32(x[7]+x[6], 13)
+		x[5] ^= bits.RotateLeft32(x[4]+x[7], 18)
+		x[11] ^= bits.RotateLeft32(x[10]+x[9], 7)
+		x[8] ^= bits.RotateLeft32(x[11]+x[10], 9)
+		x[9] ^= bits.RotateLeft32(x[8]+x[11], 13)
+		x[10] ^= bits.RotateLeft32(x[9]+x[8], 18)
+		x[12] ^= bits.RotateLeft32(x[15]+x[14], 7)
+		x[13] ^= bits.RotateLeft32(x[12]+x[15], 9)
+		x[14] ^= bits.RotateLeft32(x[13]+x[12], 13)
+		x[15] ^= bits.RotateLeft32(x[14]+x[13], 18)
+	}
+
+	for i := range b {
+		b[i] += x[i]
+	}
+}
+
+// blockMix mixes 2r blocks of 16 words from in into out (scryptBlockMix)
+// even blocks go to the first half of out, odd ones to the second
+func blockMix(in, out []uint32, r int) {
+	var x [16]uint32
+	copy(x[:], in[(2*r-1)*16:])
+
+	for i := 0; i < 2*r; i++ {
+		for j := range x {
+			x[j] ^= in[i*16+j]
+		}
+		salsa208(&x)
+
+		pos := (i/2 + i%2*r) * 16
+		copy(out[pos:pos+16], x[:])
+	}
+}
+
+// roMix is scryptROMix applied to block b of 32r words
+func roMix(b []uint32, n, r int) {
+	size := 32 * r
+	v := make([]uint32, n*size)
+	x := make([]uint32, size)
+	y := make([]uint32, size)
+	copy(x, b)
+
+	for i := 0; i < n; i++ {
+		copy(v[i*size:], x)
+		blockMix(x, y, r)
+		x, y = y, x
+	}
+
+	for i := 0; i < n; i++ {
+		// integerify takes the first word of the last 16 word block
+		j := int(x[size-16]) & (n - 1)
+		for k := range x {
+			x[k] ^= v[j*size+k]
+		}
+		blockMix(x, y, r)
+		x, y = y, x
+	}
+
+	copy(b, x)
+}
+
+// Key derives keyLen bytes from password and salt
+// n is the CPU/memory cost that must be a power of two greater than 1,
+// r is the block size and p is the parallelization
+func Key(password, salt []byte, n, r, p, keyLen int) ([]byte, error) {
+	if n <= 1 || n&(n-1) != 0 || r <= 0 || p <= 0 || r*p >= 1<<30 || n > (1<<31-1)/(128*r) {
+		return nil, WrongParametersError
+	}
+
+	blocks := PBKDF2(password, salt, 1, p*128*r)
+
+	words := make([]uint32, 32*r)
+	for i := 0; i < p; i++ {
+		block := blocks[i*128*r : (i+1)*128*r]
+		for j := range words {
+			words[j] = binary.LittleEndian.Uint32(block[j*4:])
+		}
+		roMix(words, n, r)
+		for j, w := range words {
+			binary.LittleEndian.PutUint32(block[j*4:], w)
+		}
+	}
+
+	return PBKDF2(password, blocks, 1, keyLen), nil
+}
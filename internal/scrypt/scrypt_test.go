@@ -0,0 +1,45 @@
+package scrypt
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestPBKDF2(t *testing.T) {
+	// vector from RFC 7914
+	expected := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+		"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+
+	if key := hex.EncodeToString(PBKDF2([]byte("passwd"), []byte("salt"), 1, 64)); key != expected {
+		t.Errorf("Derived %s instead of %s", key, expected)
+	}
+}
+
+func TestKey(t *testing.T) {
+	// vectors from RFC 7914
+	cases := []struct {
+		password, salt string
+		n, r, p        int
+		expected       string
+	}{
+		{"", "", 16, 1, 1, "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442" +
+			"fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906"},
+		{"password", "NaCl", 1024, 8, 16, "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b373162" +
+			"2eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"},
+	}
+
+	for _, c := range cases {
+		key, err := Key([]byte(c.password), []byte(c.salt), c.n, c.r, c.p, 64)
+		if err != nil {
+			t.Errorf("Failed to derive key: %v", err)
+		} else if hex.EncodeToString(key) != c.expected {
+			t.Errorf("Derived %x instead of %s", key, c.expected)
+		}
+	}
+
+	for _, n := range []int{0, 1, 15} {
+		if _, err := Key(nil, nil, n, 1, 1, 32); err != WrongParametersError {
+			t.Errorf("Wrong cost %d is accepted with %v", n, err)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package qr_tools
+
+var (
+	// error correction codewords in every block and the number of blocks
+	// (taken from https://www.thonky.com/qr-code-tutorial/error-correction-table)
+	ecCodewordsPerBlock = [][]int{
+		{7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},  // L
+		{10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28}, // M
+		{13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30}, // Q
+		{17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30}, // H
+	}
+	ecBlocksNumber = [][]int{
+		{1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},              // L
+		{1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},     // M
+		{1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},  // Q
+		{1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81}, // H
+	}
+)
+
+// ModuleKind is enum that
+// shows what a module of the matrix is used for
+type ModuleKind uint8
+
+const (
+	// DataModule holds data or error correction codewords (or remainder bits)
+	DataModule ModuleKind = iota
+	// FinderModule is a part of one of three finder patterns or their separators
+	FinderModule
+	TimingModule
+	AlignmentModule
+	// FormatModule holds format information (ErrorCorrectionLevel and mask)
+	FormatModule
+	// VersionModule holds version information, versions 7 and greater have it
+	VersionModule
+	// DarkModule is the single module near bottom left finder that is always dark
+	DarkModule
+)
+
+// IsFunction tells if the module is a part of function pattern rather than data
+func (k ModuleKind) IsFunction() bool {
+	return k != DataModule
+}
+
+// ECBlock describes a single block of Reed–Solomon code
+type ECBlock struct {
+	DataCodewords int
+	ECCodewords   int
+}
+
+// MatrixSize returns the number of modules in a side of chosen version (without quiet zone)
+// throws wrongQRVersionError
+func MatrixSize(ver QRVersion) (int, error) {
+	if ver < 1 || ver > 40 {
+		return 0, wrongQRVersionError
+	}
+
+	return 17 + 4*int(ver), nil
+}
+
+// alignmentPositions returns coordinates of alignment pattern centers (the same for rows and columns)
+func alignmentPositions(ver QRVersion) []int {
+	if ver == 1 {
+		return nil
+	}
+
+	num := int(ver)/7 + 2
+	step := 26
+	if ver != 32 {
+		step = (int(ver)*4 + num*2 + 1) / (num*2 - 2) * 2
+	}
+
+	positions := make([]int, num)
+	positions[0] = 6
+	for i, pos := num-1, 17+4*int(ver)-7; i > 0; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+// rawCodewords returns the number of data and error correction codewords version holds
+func rawCodewords(ver QRVersion) int {
+	v := int(ver)
+	modules := (16*v+128)*v + 64
+	if v >= 2 {
+		num := v/7 + 2
+		modules -= (25*num-10)*num - 55
+		if v >= 7 {
+			modules -= 36
+		}
+	}
+
+	return modules / 8
+}
+
+// ECBlocks returns Reed–Solomon blocks of chosen version and ErrorCorrectionLevel
+// short blocks go first, long blocks have one more data codeword
+// throws wrongQRVersionError and wrongLevelError
+func ECBlocks(lvl ErrorCorrectionLevel, ver QRVersion) ([]ECBlock, error) {
+	if ver < 1 || ver > 40 {
+		return nil, wrongQRVersionError
+	}
+	if lvl > H {
+		return nil, wrongLevelError
+	}
+
+	num := ecBlocksNumber[lvl][ver-1]
+	ec := ecCodewordsPerBlock[lvl][ver-1]
+	raw := rawCodewords(ver)
+	short := num - raw%num
+
+	blocks := make([]ECBlock, num)
+	for i := range blocks {
+		blocks[i] = ECBlock{DataCodewords: raw/num - ec, ECCodewords: ec}
+		if i >= short {
+			blocks[i].DataCodewords++
+		}
+	}
+
+	return blocks, nil
+}
+
+// FunctionPatterns returns kinds of all the modules of chosen version indexed by row and column
+// throws wrongQRVersionError
+func FunctionPatterns(ver QRVersion) ([][]ModuleKind, error) {
+	size, err := MatrixSize(ver)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := make([][]ModuleKind, size)
+	for y := range kinds {
+		kinds[y] = make([]ModuleKind, size)
+	}
+	fill := func(x0, y0, w, h int, kind ModuleKind) {
+		for y := max(y0, 0); y < min(y0+h, size); y++ {
+			for x := max(x0, 0); x < min(x0+w, size); x++ {
+				kinds[y][x] = kind
+			}
+		}
+	}
+
+	// timing patterns go first, since the others overlap them
+	fill(6, 0, 1, size, TimingModule)
+	fill(0, 6, size, 1, TimingModule)
+
+	// finder patterns together with separators
+	fill(-1, -1, 9, 9, FinderModule)
+	fill(size-8, -1, 9, 9, FinderModule)
+	fill(-1, size-8, 9, 9, FinderModule)
+
+	positions := alignmentPositions(ver)
+	last := len(positions) - 1
+	for i, cy := range positions {
+		for j, cx := range positions {
+			// corners with finder patterns are skipped
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			fill(cx-2, cy-2, 5, 5, AlignmentModule)
+		}
+	}
+
+	fill(0, 8, 6, 1, FormatModule)
+	fill(7, 8, 2, 1, FormatModule)
+	fill(8, 0, 1, 6, FormatModule)
+	fill(8, 7, 1, 1, FormatModule)
+	fill(size-8, 8, 8, 1, FormatModule)
+	fill(8, size-7, 1, 7, FormatModule)
+	kinds[size-8][8] = DarkModule
+
+	if ver >= 7 {
+		fill(0, size-11, 6, 3, VersionModule)
+		fill(size-11, 0, 3, 6, VersionModule)
+	}
+
+	return kinds, nil
+}
+
+// CodewordRef points to the codeword the module belongs to
+// Index counts data codewords of the block first and error correction codewords after them,
+// Block is -1 for function patterns and remainder bits
+type CodewordRef struct {
+	Block int
+	Index int
+}
+
+// CodewordMap returns codewords all the modules of chosen version and ErrorCorrectionLevel belong to
+// indexed by row and column, so damage of the matrix can be traced back to the blocks
+// throws wrongQRVersionError and wrongLevelError
+func CodewordMap(lvl ErrorCorrectionLevel, ver QRVersion) ([][]CodewordRef, error) {
+	kinds, err := FunctionPatterns(ver)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := ECBlocks(lvl, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	order := codewordOrder(blocks)
+	size := len(kinds)
+	refs := make([][]CodewordRef, size)
+	for y := range refs {
+		refs[y] = make([]CodewordRef, size)
+		for x := range refs[y] {
+			refs[y][x] = CodewordRef{Block: -1, Index: -1}
+		}
+	}
+
+	for bit, p := range placement(kinds) {
+		if bit/8 < len(order) {
+			refs[p.y][p.x] = order[bit/8]
+		}
+	}
+
+	return refs, nil
+}
+
+// codewordOrder returns the order of codewords after interleaving:
+// data codewords of all the blocks, then error correction ones
+func codewordOrder(blocks []ECBlock) []CodewordRef {
+	order := make([]CodewordRef, 0)
+	longest := blocks[len(blocks)-1].DataCodewords
+	for i := 0; i < longest; i++ {
+		for b, block := range blocks {
+			if i < block.DataCodewords {
+				order = append(order, CodewordRef{Block: b, Index: i})
+			}
+		}
+	}
+	for i := 0; i < blocks[0].ECCodewords; i++ {
+		for b, block := range blocks {
+			order = append(order, CodewordRef{Block: b, Index: block.DataCodewords + i})
+		}
+	}
+	return order
+}
+
+// point is a module of the matrix at column x and row y
+type point struct {
+	x, y int
+}
+
+// placement returns data modules in the order bits of codewords are put into them:
+// two module wide columns from the bottom right corner, zigzagging up and down,
+// the most significant bit of a codeword goes first
+func placement(kinds [][]ModuleKind) []point {
+	size := len(kinds)
+	points := make([]point, 0, size*size)
+	for right := size - 1; right >= 1; right -= 2 {
+		// vertical timing pattern is skipped
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < size; vert++ {
+			y := vert
+			if upward {
+				y = size - 1 - vert
+			}
+			for x := right; x >= right-1; x-- {
+				if !kinds[y][x].IsFunction() {
+					points = append(points, point{x: x, y: y})
+				}
+			}
+		}
+	}
+	return points
+}
@@ -0,0 +1,147 @@
+package qr_tools
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestECBlocks(t *testing.T) {
+	for lvl := ErrorCorrectionLevel(L); lvl <= H; lvl++ {
+		for ver := QRVersion(1); ver <= 40; ver++ {
+			blocks, err := ECBlocks(lvl, ver)
+			if err != nil {
+				t.Fatalf("Failed to get blocks of version %d: %v", ver, err)
+			}
+
+			data, total := 0, 0
+			for _, b := range blocks {
+				data += b.DataCodewords
+				total += b.DataCodewords + b.ECCodewords
+			}
+			if uint(data) != codewordsCapacities[lvl][ver-1] || total != rawCodewords(ver) {
+				t.Errorf("Blocks of version %d with level %d hold %d/%d codewords instead of %d/%d",
+					ver, lvl, data, total, codewordsCapacities[lvl][ver-1], rawCodewords(ver))
+			}
+		}
+	}
+
+	// version 5 with level Q has two blocks of 15 and two blocks of 16 data codewords
+	blocks, _ := ECBlocks(Q, 5)
+	expected := []ECBlock{{15, 18}, {15, 18}, {16, 18}, {16, 18}}
+	if !reflect.DeepEqual(blocks, expected) {
+		t.Errorf("Blocks of version 5 with level Q are %v instead of %v", blocks, expected)
+	}
+
+	if _, err := ECBlocks(L, 0); err == nil {
+		t.Errorf("Blocks of version 0 are returned without error")
+	}
+	if _, err := ECBlocks(H+1, 5); !errors.Is(err, wrongLevelError) {
+		t.Errorf("Blocks of wrong level give %v instead of %v", err, wrongLevelError)
+	}
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	cases := []struct {
+		ver      QRVersion
+		expected []int
+	}{
+		{1, nil},
+		{2, []int{6, 18}},
+		{7, []int{6, 22, 38}},
+		{32, []int{6, 34, 60, 86, 112, 138}},
+		{40, []int{6, 30, 58, 86, 114, 142, 170}},
+	}
+
+	for _, c := range cases {
+		if positions := alignmentPositions(c.ver); !reflect.DeepEqual(positions, c.expected) {
+			t.Errorf("Alignment patterns of version %d are at %v instead of %v", c.ver, positions, c.expected)
+		}
+	}
+}
+
+func TestFunctionPatterns(t *testing.T) {
+	for ver := QRVersion(1); ver <= 40; ver++ {
+		kinds, err := FunctionPatterns(ver)
+		if err != nil {
+			t.Fatalf("Failed to get patterns of version %d: %v", ver, err)
+		}
+
+		data := 0
+		for _, row := range kinds {
+			for _, k := range row {
+				if !k.IsFunction() {
+					data++
+				}
+			}
+		}
+		// remainder bits are data modules too
+		if data/8 != rawCodewords(ver) {
+			t.Errorf("Version %d has %d data modules, that's not enough for %d codewords", ver, data, rawCodewords(ver))
+		}
+	}
+
+	kinds, _ := FunctionPatterns(1)
+	cases := []struct {
+		x, y     int
+		expected ModuleKind
+	}{
+		{0, 0, FinderModule},
+		{7, 7, FinderModule},
+		{8, 8, FormatModule},
+		{10, 6, TimingModule},
+		{8, 13, DarkModule},
+		{20, 8, FormatModule},
+		{9, 9, DataModule},
+	}
+	for _, c := range cases {
+		if kinds[c.y][c.x] != c.expected {
+			t.Errorf("Module (%d, %d) is %d instead of %d", c.x, c.y, kinds[c.y][c.x], c.expected)
+		}
+	}
+
+	kinds, _ = FunctionPatterns(7)
+	if kinds[22][22] != AlignmentModule || kinds[34][0] != VersionModule || kinds[0][34] != VersionModule {
+		t.Errorf("Alignment or version patterns of version 7 are misplaced")
+	}
+}
+
+func TestCodewordMap(t *testing.T) {
+	refs, err := CodewordMap(M, 5)
+	if err != nil {
+		t.Fatalf("Failed to get codeword map: %v", err)
+	}
+	blocks, _ := ECBlocks(M, 5)
+
+	// every codeword takes exactly 8 modules
+	counts := make(map[CodewordRef]int)
+	for _, row := range refs {
+		for _, ref := range row {
+			if ref.Block != -1 {
+				counts[ref]++
+			}
+		}
+	}
+	total := 0
+	for _, b := range blocks {
+		total += b.DataCodewords + b.ECCodewords
+	}
+	if len(counts) != total {
+		t.Errorf("Map has %d codewords instead of %d", len(counts), total)
+	}
+	for ref, n := range counts {
+		if n != 8 {
+			t.Errorf("Codeword %v takes %d modules", ref, n)
+		}
+	}
+
+	// the first codeword starts at the bottom right corner and goes up
+	size := len(refs)
+	first := CodewordRef{Block: 0, Index: 0}
+	if refs[size-1][size-1] != first || refs[size-4][size-2] != first {
+		t.Errorf("First codeword is misplaced")
+	}
+	if refs[size-5][size-1] != (CodewordRef{Block: 1, Index: 0}) {
+		t.Errorf("Codewords aren't interleaved")
+	}
+}
@@ -0,0 +1,208 @@
+// Package logo checks that an area covered by a logo
+// leaves enough error correction capacity for the code to be read
+//
+// areas are given in fractions of the matrix side (without quiet zone),
+// so the same logo can be checked against different versions;
+// render.Options.Logo clears the area and draws the logo picture over the rendered code
+package logo
+
+import (
+	"errors"
+	"math"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+var (
+	TooMuchDamageError     = errors.New("area damages more codewords than error correction can restore")
+	FunctionPatternError   = errors.New("area covers finder, timing, format or version patterns")
+	NoSuitableVersionError = errors.New("no version and level leave enough error correction for the area")
+)
+
+// Area is a part of the matrix that is covered
+type Area interface {
+	// Covers tells if area overlaps module (x, y) of the matrix with size modules in a side
+	Covers(x, y, size int) bool
+}
+
+// Rect is a rectangle with top left corner at (X, Y)
+type Rect struct {
+	X, Y, W, H float64
+}
+
+// CenteredRect returns rectangle in the center of the matrix
+func CenteredRect(w, h float64) Rect {
+	return Rect{X: (1 - w) / 2, Y: (1 - h) / 2, W: w, H: h}
+}
+
+func (r Rect) Covers(x, y, size int) bool {
+	s := float64(size)
+	return float64(x+1) > r.X*s && float64(x) < (r.X+r.W)*s &&
+		float64(y+1) > r.Y*s && float64(y) < (r.Y+r.H)*s
+}
+
+// Circle is a circle with center at (CX, CY)
+type Circle struct {
+	CX, CY, R float64
+}
+
+// CenteredCircle returns circle in the center of the matrix
+func CenteredCircle(r float64) Circle {
+	return Circle{CX: 0.5, CY: 0.5, R: r}
+}
+
+func (c Circle) Covers(x, y, size int) bool {
+	s := float64(size)
+	// the nearest point of the module to the center
+	nx := math.Max(float64(x), math.Min(c.CX*s, float64(x+1)))
+	ny := math.Max(float64(y), math.Min(c.CY*s, float64(y+1)))
+	dx, dy := nx-c.CX*s, ny-c.CY*s
+	return dx*dx+dy*dy < c.R*s*c.R*s
+}
+
+// misdecodeProtection returns error correction codewords reserved against misdecoding in small versions
+// (ISO/IEC 18004 table 9), they can't be used for correction
+func misdecodeProtection(lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) int {
+	switch {
+	case ver == 1 && lvl == qr_tools.L:
+		return 3
+	case ver == 1 && lvl == qr_tools.M, ver == 2 && lvl == qr_tools.L:
+		return 2
+	case ver == 1, ver == 3 && lvl == qr_tools.L:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Report shows how the area damages every Reed–Solomon block
+type Report struct {
+	// Damaged is the number of codewords with at least one covered module
+	Damaged []int
+	// Capacity is the number of codewords error correction can restore
+	Capacity []int
+	// FunctionPatterns tells if the area covers something except data and alignment patterns
+	FunctionPatterns bool
+}
+
+// Fits tells if the code with the area is still readable
+func (r *Report) Fits() bool {
+	if r.FunctionPatterns {
+		return false
+	}
+	for i := range r.Damaged {
+		if r.Damaged[i] > r.Capacity[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Usage returns the greatest share of error correction capacity used by the area among the blocks
+// it's worth warning when it's close to 1, since print defects need capacity too
+func (r *Report) Usage() float64 {
+	usage := 0.0
+	for i := range r.Damaged {
+		if r.Capacity[i] == 0 {
+			if r.Damaged[i] > 0 {
+				return math.Inf(1)
+			}
+			continue
+		}
+		usage = math.Max(usage, float64(r.Damaged[i])/float64(r.Capacity[i]))
+	}
+	return usage
+}
+
+// Analyze calculates how the area damages the code of chosen version and ErrorCorrectionLevel
+// throws the errors of qr_tools.CodewordMap if version or level is wrong
+func Analyze(area Area, lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) (*Report, error) {
+	kinds, err := qr_tools.FunctionPatterns(ver)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := qr_tools.CodewordMap(lvl, ver)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := qr_tools.ECBlocks(lvl, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{Damaged: make([]int, len(blocks)), Capacity: make([]int, len(blocks))}
+	for i, b := range blocks {
+		r.Capacity[i] = (b.ECCodewords - misdecodeProtection(lvl, ver)) / 2
+	}
+
+	size := len(kinds)
+	damaged := make(map[qr_tools.CodewordRef]bool)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if !area.Covers(x, y, size) {
+				continue
+			}
+
+			switch kinds[y][x] {
+			case qr_tools.DataModule:
+				ref := refs[y][x]
+				if ref.Block != -1 && !damaged[ref] {
+					damaged[ref] = true
+					r.Damaged[ref.Block]++
+				}
+			case qr_tools.AlignmentModule:
+				// decoders cope with a missing alignment pattern
+			default:
+				r.FunctionPatterns = true
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// Check returns error if the area makes the code of chosen version and ErrorCorrectionLevel unreadable
+func Check(area Area, lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) error {
+	r, err := Analyze(area, lvl, ver)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case r.FunctionPatterns:
+		return FunctionPatternError
+	case !r.Fits():
+		return TooMuchDamageError
+	}
+	return nil
+}
+
+// Choose returns the smallest version and the lowest ErrorCorrectionLevel (not lower than lvl)
+// segments fit into while the code with the area stays readable
+// level is raised first, since it keeps the code smaller
+// throws the errors of qr_tools.SmallestVersion if lvl is wrong
+func Choose(segments []qr_tools.Segment, area Area, lvl qr_tools.ErrorCorrectionLevel) (qr_tools.ErrorCorrectionLevel, qr_tools.QRVersion, error) {
+	start, err := qr_tools.SmallestVersion(segments, lvl)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for ver := start; ver <= 40; ver++ {
+		best, err := qr_tools.BestLevel(segments, ver)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		for l := lvl; l <= best; l++ {
+			r, err := Analyze(area, l, ver)
+			if err != nil {
+				return 0, 0, err
+			}
+			if r.Fits() {
+				return l, ver, nil
+			}
+		}
+	}
+
+	return 0, 0, NoSuitableVersionError
+}
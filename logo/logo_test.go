@@ -0,0 +1,83 @@
+package logo
+
+import (
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+func TestAreas(t *testing.T) {
+	// 21 modules in a side, the center module is (10, 10)
+	r := CenteredRect(1.0/21, 1.0/21)
+	if !r.Covers(10, 10, 21) || r.Covers(9, 10, 21) || r.Covers(10, 11, 21) {
+		t.Errorf("Rectangle covers wrong modules")
+	}
+
+	c := CenteredCircle(0.1)
+	if !c.Covers(10, 10, 21) || !c.Covers(8, 10, 21) || c.Covers(8, 8, 21) || c.Covers(0, 0, 21) {
+		t.Errorf("Circle covers wrong modules")
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	// the whole of a single codeword in the bottom right corner
+	corner := Rect{X: 23.0 / 25, Y: 21.0 / 25, W: 2.0 / 25, H: 4.0 / 25}
+	r, err := Analyze(corner, qr_tools.L, 2)
+	if err != nil {
+		t.Fatalf("Failed to analyze: %v", err)
+	}
+	if len(r.Damaged) != 1 || r.Damaged[0] != 1 || r.Capacity[0] != 4 || r.FunctionPatterns {
+		t.Errorf("Corner damage is reported as %v", r)
+	}
+	if !r.Fits() || r.Usage() != 0.25 {
+		t.Errorf("Corner damage uses %f of capacity", r.Usage())
+	}
+
+	// top left corner is a finder pattern
+	if err := Check(Rect{W: 0.1, H: 0.1}, qr_tools.H, 2); err != FunctionPatternError {
+		t.Errorf("Covered finder pattern is accepted with %v", err)
+	}
+
+	_, wrongLevel := qr_tools.DataCapacity(qr_tools.H+1, 2)
+	if _, err := Analyze(corner, qr_tools.H+1, 2); wrongLevel == nil || err != wrongLevel {
+		t.Errorf("Wrong level is analyzed with %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	area := CenteredRect(0.3, 0.3)
+
+	if err := Check(area, qr_tools.L, 10); err != TooMuchDamageError {
+		t.Errorf("Logo is accepted with level L: %v", err)
+	}
+	if err := Check(area, qr_tools.H, 10); err != nil {
+		t.Errorf("Logo isn't accepted with level H: %v", err)
+	}
+}
+
+func TestChoose(t *testing.T) {
+	segments := []qr_tools.Segment{qr_tools.NewByteSegment([]byte("https://example.com/products/4711"))}
+	area := CenteredRect(0.3, 0.3)
+
+	lvl, ver, err := Choose(segments, area, qr_tools.L)
+	if err != nil {
+		t.Fatalf("Failed to choose: %v", err)
+	}
+	if err := Check(area, lvl, ver); err != nil {
+		t.Errorf("Chosen level %d and version %d don't fit the logo: %v", lvl, ver, err)
+	}
+	if smallest, _ := qr_tools.SmallestVersion(segments, lvl); ver < smallest {
+		t.Errorf("Data doesn't fit chosen version %d", ver)
+	}
+	if lvl == qr_tools.L {
+		t.Errorf("Level isn't raised for the logo")
+	}
+
+	if _, _, err := Choose(segments, CenteredRect(0.9, 0.9), qr_tools.L); err != NoSuitableVersionError {
+		t.Errorf("Huge logo is accepted with %v", err)
+	}
+	_, wrongLevel := qr_tools.DataCapacity(qr_tools.H+1, 1)
+	if _, _, err := Choose(segments, area, qr_tools.H+1); wrongLevel == nil || err != wrongLevel {
+		t.Errorf("Wrong level is chosen from with %v", err)
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"errors"
 	"math"
 	"strconv"
-	"unicode"
+	"strings"
 )
 
 const (
@@ -13,9 +13,9 @@ const (
 )
 
 var (
-	bitBeyondError      = errors.New("bit number goes beyond number of bytes")
 	wrongFormatError    = errors.New("content format is not suitable for marshaler")
 	wrongQRVersionError = errors.New("wrong qr version")
+	dataTooLongError    = errors.New("data doesn't fit into qr version")
 
 	// just some magic numbers, hope later I'll calculate them by myself (taken from https://www.thonky.com/qr-code-tutorial/character-capacities)
 	numericCapacities = [][]uint{
@@ -73,9 +73,10 @@ const (
 type QRVersion uint
 
 // Marshaler is the interface implemented by types that
-// can marshal a string into a sequence of bytes.
+// can marshal a string or raw payload into a sequence of bytes.
 type Marshaler interface {
 	MarshalString(str string) (data []byte, err error)
+	MarshalBytes(payload []byte) (data []byte, err error)
 }
 
 // A NumericMarshaler can marshal numeric data
@@ -91,9 +92,11 @@ func NewNumericMarshaler(lvl ErrorCorrectionLevel, ver QRVersion) *NumericMarsha
 	return &NumericMarshaler{lvl: lvl, ver: ver}
 }
 
+// isNumeric tells if str consists of ASCII digits only,
+// other Unicode digits can't be put into numeric mode
 func isNumeric(str string) bool {
-	for _, ch := range str {
-		if !unicode.IsDigit(ch) {
+	for i := 0; i < len(str); i++ {
+		if str[i] < '0' || str[i] > '9' {
 			return false
 		}
 	}
@@ -112,37 +115,56 @@ func clearLeadingZeroes(str string) string {
 }
 
 // MarshalString marshals the given numeric string
+// throws dataTooLongError if it doesn't fit into chosen version and ErrorCorrectionLevel
 func (nm *NumericMarshaler) MarshalString(str string) ([]byte, error) {
 	if !isNumeric(str) {
 		return nil, wrongFormatError
 	}
 
-	ba := newBitsetAppender()
+	ba := NewBitWriter()
+	if err := encodeNumeric(ba, nm.ver, str); err != nil {
+		return nil, err
+	}
 
+	// padding information
+	bitsNum, err := DataCapacity(nm.lvl, nm.ver)
+	if err != nil {
+		return nil, err
+	}
+	if err := addPadding(ba, bitsNum); err != nil {
+		return nil, err
+	}
+
+	return ba.getData(), nil
+}
+
+// MarshalBytes marshals the given numeric payload
+func (nm *NumericMarshaler) MarshalBytes(payload []byte) ([]byte, error) {
+	return nm.MarshalString(string(payload))
+}
+
+// encodeNumeric appends numeric segment (mode indicator, size indicator and data) to ba
+// str should be already checked with isNumeric
+func encodeNumeric(ba *BitWriter, ver QRVersion, str string) error {
 	//adding mode indicator - numeric
 	ba.appendByte(0b00010000, 4)
 	//adding size indicator
-	if err := addCharacterCount(numericBitCounts, ba, nm.ver, len(str)); err != nil {
-		return nil, err
+	if err := addCharacterCount(numericBitCounts, ba, ver, len(str)); err != nil {
+		return err
 	}
 
-	// splitting digits into triplets and cutting away leading zeroes
-	// the size of encoded depends on number of digits
+	// splitting digits into triplets
+	// the size of encoded depends on number of digits in triplet, not on its value
 	for i := 0; i < len(str); i += 3 {
 		piece := str[i:min(len(str), i+3)]
-		piece = clearLeadingZeroes(piece)
 
 		bits := uint(1 + 3*len(piece))
 
-		pint, _ := strconv.Atoi(piece)
+		pint, _ := strconv.Atoi(clearLeadingZeroes(piece))
 		ba.appendUint16(uint16(pint)<<(16-bits), bits)
 	}
 
-	// padding information
-	bitsNum := codewordsCapacities[nm.lvl][nm.ver-1] * 8
-	addPadding(ba, bitsNum)
-
-	return ba.getData(), nil
+	return nil
 }
 
 // An AlphanumericMarshaler can marshal alphanumeric data (0-9, A-Z,' ', S$, %, *, +, -, ., /, :)
@@ -182,18 +204,42 @@ func isAlphaNumeric(str string) bool {
 }
 
 // MarshalString marshals the given alphanumeric string
+// throws dataTooLongError if it doesn't fit into chosen version and ErrorCorrectionLevel
 func (am *AlphanumericMarshaler) MarshalString(str string) ([]byte, error) {
 	if !isAlphaNumeric(str) {
 		return nil, wrongFormatError
 	}
 
-	ba := newBitsetAppender()
+	ba := NewBitWriter()
+	if err := encodeAlphanumeric(ba, am.ver, str); err != nil {
+		return nil, err
+	}
+
+	//applying padding
+	bitsNum, err := DataCapacity(am.lvl, am.ver)
+	if err != nil {
+		return nil, err
+	}
+	if err := addPadding(ba, bitsNum); err != nil {
+		return nil, err
+	}
+
+	return ba.getData(), nil
+}
+
+// MarshalBytes marshals the given alphanumeric payload
+func (am *AlphanumericMarshaler) MarshalBytes(payload []byte) ([]byte, error) {
+	return am.MarshalString(string(payload))
+}
 
+// encodeAlphanumeric appends alphanumeric segment (mode indicator, size indicator and data) to ba
+// str should be already checked with isAlphaNumeric
+func encodeAlphanumeric(ba *BitWriter, ver QRVersion, str string) error {
 	//adding mode indicator - alphanumeric
 	ba.appendByte(0b00100000, 4)
 	//adding size indicator
-	if err := addCharacterCount(alphanumericBitCounts, ba, am.ver, len(str)); err != nil {
-		return nil, err
+	if err := addCharacterCount(alphanumericBitCounts, ba, ver, len(str)); err != nil {
+		return err
 	}
 
 	//splitting string in duos and encoding
@@ -209,11 +255,7 @@ func (am *AlphanumericMarshaler) MarshalString(str string) ([]byte, error) {
 		ba.appendUint16(nm<<10, 6)
 	}
 
-	//applying padding
-	bitsNum := codewordsCapacities[am.lvl][am.ver-1] * 8
-	addPadding(ba, bitsNum)
-
-	return ba.getData(), nil
+	return nil
 }
 
 // A ByteMarshaler can marshal byte data
@@ -230,41 +272,69 @@ func NewByteMarshaler(lvl ErrorCorrectionLevel, ver QRVersion) *ByteMarshaler {
 }
 
 // MarshalString marshals the given byte string
+// throws dataTooLongError if it doesn't fit into chosen version and ErrorCorrectionLevel
 func (bm *ByteMarshaler) MarshalString(str string) ([]byte, error) {
-	ba := newBitsetAppender()
+	ba := NewBitWriter()
+	if err := encodeByte(ba, bm.ver, str); err != nil {
+		return nil, err
+	}
+
+	//padding information
+	bitsNum, err := DataCapacity(bm.lvl, bm.ver)
+	if err != nil {
+		return nil, err
+	}
+	if err := addPadding(ba, bitsNum); err != nil {
+		return nil, err
+	}
+
+	return ba.getData(), nil
+}
+
+// MarshalBytes marshals the given binary payload
+// payload is kept as it is, no UTF-8 is assumed
+func (bm *ByteMarshaler) MarshalBytes(payload []byte) ([]byte, error) {
+	return bm.MarshalString(string(payload))
+}
 
+// encodeByte appends byte segment (mode indicator, size indicator and data) to ba
+func encodeByte(ba *BitWriter, ver QRVersion, str string) error {
 	//adding mode indicator - byte
 	ba.appendByte(0b01000000, 4)
 	//adding size indicator
-	if err := addCharacterCount(byteBitCounts, ba, bm.ver, len(str)); err != nil {
-		return nil, err
+	if err := addCharacterCount(byteBitCounts, ba, ver, len(str)); err != nil {
+		return err
 	}
 
 	//just past data (no way there would be an error)
 	_ = ba.append([]byte(str), uint(len(str)*8))
 
-	//padding information
-	bitsNum := codewordsCapacities[bm.lvl][bm.ver-1] * 8
-	addPadding(ba, bitsNum)
-
-	return ba.getData(), nil
+	return nil
 }
 
 //will add kanji later
 
-// addCharacterCount appends character count indicator to string
+// characterCountBits returns the size of character count indicator for chosen version
 // throws wrongQRVersionError
-func addCharacterCount(bitCounts [3]uint, ba *bitsetAppender, ver QRVersion, chCnt int) error {
-	var cntSize uint
+func characterCountBits(bitCounts [3]uint, ver QRVersion) (uint, error) {
 	switch {
 	case ver >= 1 && ver <= 9:
-		cntSize = bitCounts[0]
+		return bitCounts[0], nil
 	case ver >= 10 && ver <= 26:
-		cntSize = bitCounts[1]
+		return bitCounts[1], nil
 	case ver >= 27 && ver <= 40:
-		cntSize = bitCounts[2]
+		return bitCounts[2], nil
 	default:
-		return wrongQRVersionError
+		return 0, wrongQRVersionError
+	}
+}
+
+// addCharacterCount appends character count indicator to string
+// throws wrongQRVersionError
+func addCharacterCount(bitCounts [3]uint, ba *BitWriter, ver QRVersion, chCnt int) error {
+	cntSize, err := characterCountBits(bitCounts, ver)
+	if err != nil {
+		return err
 	}
 
 	ba.appendUint16(uint16(chCnt<<(16-cntSize)), cntSize)
@@ -273,7 +343,12 @@ func addCharacterCount(bitCounts [3]uint, ba *bitsetAppender, ver QRVersion, chC
 
 // addPadding adds padding after placing information
 // used in some marshalers
-func addPadding(ba *bitsetAppender, bitsNum uint) {
+// throws dataTooLongError if information takes more than bitsNum bits
+func addPadding(ba *BitWriter, bitsNum uint) error {
+	if ba.n > bitsNum {
+		return dataTooLongError
+	}
+
 	// adding 0-terminator
 	ba.appendUint16(0, min(bitsNum-ba.n, 4))
 
@@ -284,6 +359,8 @@ func addPadding(ba *bitsetAppender, bitsNum uint) {
 	for ba.n < bitsNum {
 		ba.appendUint16(0b11101100_00010001, min(16, bitsNum-ba.n))
 	}
+
+	return nil
 }
 
 // A QRMarshaler can marshal numeric, alphanumeric, byte and kanji (later) effectively
@@ -300,6 +377,7 @@ func NewQRMarshaler(lvl ErrorCorrectionLevel, ver QRVersion) *QRMarshaler {
 }
 
 // MarshalString marshals the given string effectively
+// throws dataTooLongError if it doesn't fit into chosen version and ErrorCorrectionLevel
 func (qm *QRMarshaler) MarshalString(str string) ([]byte, error) {
 	var mller Marshaler = NewNumericMarshaler(qm.lvl, qm.ver)
 	if data, err := mller.MarshalString(str); err == nil {
@@ -325,92 +403,98 @@ func (qm *QRMarshaler) MarshalString(str string) ([]byte, error) {
 	return nil, wrongFormatError
 }
 
-// Unmarshaler is the interface implemented by types that
-// can unmarshal a string from a sequence of bytes
-type Unmarshaler interface {
-	UnmarshalToString(data []byte) (str string, err error)
+// MarshalBytes marshals the given payload effectively
+// payload is kept as it is, so any binary data can be marshaled
+func (qm *QRMarshaler) MarshalBytes(payload []byte) ([]byte, error) {
+	return qm.MarshalString(string(payload))
 }
 
-// bitsetAppender is a structure that
-// helps to connect long sequences of bits
+// GroupSeparator is the character separating variable-length fields in GS1 element strings
+// it stands for FNC1 character used as separator
+const GroupSeparator = '\x1d'
+
+// A FNC1Marshaler can marshal data in FNC1 (GS1 or industry specific) format
+// with respect to ErrorCorrectionLevel
 //
-// note that n should always be not greater than len(data) * 8
-type bitsetAppender struct {
-	data []byte
-	n    uint
-}
+// field separators should be given as GroupSeparator, they are turned into %
+// in alphanumeric mode and kept as they are in byte mode
+type FNC1Marshaler struct {
+	lvl ErrorCorrectionLevel
+	ver QRVersion
 
-// newBitsetAppender creates empty bitsetAppender
-func newBitsetAppender() *bitsetAppender {
-	return &bitsetAppender{data: make([]byte, 0), n: 0}
+	second       bool
+	appIndicator byte
 }
 
-// appendByte appends n bits to the sequence of bits inside bitAppender
-// if n is greater than 8 appendByte append just 8 bits
-func (ba *bitsetAppender) appendByte(data byte, n uint) {
-	if n == 0 {
-		return
-	}
-	oldN := n
-	n = min(8, n)
-
-	if ba.n%8 != 0 {
-		ba.data[ba.n/8] |= data >> (ba.n % 8)
-		ba.data[ba.n/8] &= allOnes << (8 - min(ba.n%8+n, 8))
-	}
-	if (8-ba.n%8)%8 < n {
-		ba.data = append(ba.data, data<<((8-ba.n%8)%8))
-	}
-
-	if oldN > 8 {
-		ba.n += 8
-	} else {
-		ba.n += n
-	}
+// NewFNC1FirstMarshaler returns FNC1Marshaler using FNC1 in first position (GS1 data)
+// with chosen ErrorCorrectionLevel
+func NewFNC1FirstMarshaler(lvl ErrorCorrectionLevel, ver QRVersion) *FNC1Marshaler {
+	return &FNC1Marshaler{lvl: lvl, ver: ver}
 }
 
-// append appends n bits to the sequence that is already in bitsetAppender
-// note that n shall not be greater than len(date) * 8, otherwise you'll get a bitBeyondError
-// but previous data would be written
-func (ba *bitsetAppender) append(data []byte, n uint) error {
-	if n == 0 {
-		return nil
-	}
-
-	times := (n-1)/8 + 1
+// NewFNC1SecondMarshaler returns FNC1Marshaler using FNC1 in second position (industry specific data)
+// with chosen ErrorCorrectionLevel
+//
+// appIndicator is either a number from 0 to 99 or ASCII value of the letter + 100
+func NewFNC1SecondMarshaler(lvl ErrorCorrectionLevel, ver QRVersion, appIndicator byte) *FNC1Marshaler {
+	return &FNC1Marshaler{lvl: lvl, ver: ver, second: true, appIndicator: appIndicator}
+}
 
-	for i := 0; times > 0; times, n, i = times-1, n-8, i+1 {
-		if i >= len(data) {
-			return bitBeyondError
+// escapeFNC1Alphanumeric turns separators into % and doubles % that are already in string
+func escapeFNC1Alphanumeric(str string) string {
+	sb := strings.Builder{}
+	for _, ch := range str {
+		switch ch {
+		case GroupSeparator:
+			sb.WriteByte('%')
+		case '%':
+			sb.WriteString("%%")
+		default:
+			sb.WriteRune(ch)
 		}
-
-		ba.appendByte(data[i], n)
 	}
 
-	return nil
+	return sb.String()
 }
 
-// appendUint16 simply splits uint into 4 bytes and appends them
-// if n is greater than 16 appendUint16 appends just 16 bits
-func (ba *bitsetAppender) appendUint16(data uint16, n uint) {
-	if n == 0 {
-		return
+// MarshalString marshals the given FNC1 string effectively
+// throws dataTooLongError if it doesn't fit into chosen version and ErrorCorrectionLevel
+func (fm *FNC1Marshaler) MarshalString(str string) ([]byte, error) {
+	ba := NewBitWriter()
+
+	//adding mode indicator - FNC1
+	if fm.second {
+		ba.appendByte(0b10010000, 4)
+		ba.appendByte(fm.appIndicator, 8)
+	} else {
+		ba.appendByte(0b01010000, 4)
 	}
 
-	n = min(n, 16)
-	times := (n-1)/8 + 1
+	var err error
+	if escaped := escapeFNC1Alphanumeric(str); isNumeric(str) {
+		err = encodeNumeric(ba, fm.ver, str)
+	} else if isAlphaNumeric(escaped) {
+		err = encodeAlphanumeric(ba, fm.ver, escaped)
+	} else {
+		err = encodeByte(ba, fm.ver, str)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	m := 8
-	for ; times > 0; times, n, m = times-1, n-8, m-8 {
-		ba.appendByte(byte(data>>m), n)
+	//padding information
+	bitsNum, err := DataCapacity(fm.lvl, fm.ver)
+	if err != nil {
+		return nil, err
+	}
+	if err := addPadding(ba, bitsNum); err != nil {
+		return nil, err
 	}
+
+	return ba.getData(), nil
 }
 
-// getData gives out written data, cutting all not important stuff away
-func (ba *bitsetAppender) getData() []byte {
-	inc := uint(0)
-	if ba.n%8 != 0 {
-		inc = 1
-	}
-	return ba.data[:ba.n/8+inc]
+// MarshalBytes marshals the given FNC1 payload effectively
+func (fm *FNC1Marshaler) MarshalBytes(payload []byte) ([]byte, error) {
+	return fm.MarshalString(string(payload))
 }
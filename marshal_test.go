@@ -4,7 +4,6 @@ import (
 	"bytes"
 	cryptoRand "crypto/rand"
 	"errors"
-	"math"
 	"math/rand"
 	"strconv"
 	"strings"
@@ -31,111 +30,6 @@ func TestCapacities(t *testing.T) {
 	testCapacity(t, codewordsCapacities, "codewords")
 }
 
-func TestNewBitsetAppender(t *testing.T) {
-	ba := newBitsetAppender()
-
-	if ba.n != 0 || len(ba.data) != 0 {
-		t.Errorf("Newly created bitset appender is not empty")
-	}
-}
-
-func TestBitsetAppendByte(t *testing.T) {
-	allOnes := byte(math.MaxUint8)
-	ba := newBitsetAppender()
-
-	ln := uint(0)
-	for i := uint(0); i <= 100; i++ {
-		ba.appendByte(byte(i)<<(8-min(i, 8)), i)
-
-		prLn := ln
-		ln += min(i, 8)
-		if ln != ba.n {
-			t.Errorf("Bitset appender length %d does not match actual number of bits appended %d", ba.n, ln)
-		}
-
-		var extr byte
-		if i == 0 {
-			extr = 0
-		} else if ba.n/8 == prLn/8 {
-			extr = ba.data[len(ba.data)-1] >> ((8 - ba.n%8) % 8)
-			extr &= allOnes >> (8 - (ba.n - prLn))
-		} else {
-			extr = ba.data[len(ba.data)-1] >> ((8 - ba.n%8) % 8)
-			if ba.n%8 != 0 {
-				extr |= ba.data[len(ba.data)-2] << (ba.n % 8)
-			}
-			extr &= allOnes >> (8 - (ba.n - prLn))
-		}
-
-		actual := byte(i) & (allOnes >> (8 - min(i, 8)))
-		if extr != actual {
-			t.Errorf("Extracted bites %b aren't equal with actual bites %b, i = %d", extr, actual, i)
-		}
-	}
-}
-
-func TestBitsetAppendUint16(t *testing.T) {
-	ba1 := newBitsetAppender()
-	ba2 := newBitsetAppender()
-
-	for i, num := 0, uint16(math.MaxUint8+1); i < 100; i, num = i+1, num+1 {
-		bits := i % 25
-
-		ba1.appendUint16(num, uint(bits))
-
-		ba2.appendByte(byte(num>>8), uint(min(bits, 8)))
-		ba2.appendByte(byte(num), uint(max(bits-8, 0)))
-
-		if ba1.n != ba2.n || !bytes.Equal(ba1.data, ba2.data) {
-			t.Errorf("Arrays aren't equal after adding %d bits from %d", bits, i)
-		}
-	}
-}
-
-func TestBitsetAppend(t *testing.T) {
-	ba1 := newBitsetAppender()
-	ba2 := newBitsetAppender()
-
-	data := make([]byte, 0, 12)
-	for i := 0; i < 12; i += 4 {
-		r := rand.Uint32()
-		data = append(data, byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
-	}
-
-	for i := 0; i <= 12*8+100; i++ {
-		err := ba1.append(data, uint(i))
-		if err != nil && i <= 12*8 {
-			t.Errorf("Got sudden error after adding %d bits", i)
-		} else if err == nil && i > 12*8 {
-			t.Errorf("Didn't get error after adding %d bits", i)
-		}
-
-		for j, y := min(i, 12*8), 0; j > 0; j, y = j-8, y+1 {
-			ba2.appendByte(data[y], uint(min(j, 8)))
-		}
-
-		if ba1.n != ba2.n || ba1.n > 0 && !bytes.Equal(ba1.data, ba2.data) {
-			t.Errorf("Arrays aren't equal after adding %d bits", i)
-		}
-	}
-}
-
-func TestBitsetGetData(t *testing.T) {
-	ba := newBitsetAppender()
-	data := make([]byte, 0, 12)
-	for i := 0; i < 12; i += 4 {
-		r := rand.Uint32()
-		data = append(data, byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
-	}
-
-	bits := uint(12*5 + 6)
-
-	_ = ba.append(data, bits)
-	if ba.n != bits || !bytes.Equal(ba.getData(), data[:(bits-1)/8+1]) {
-		t.Errorf("getData's value and data aren't equal")
-	}
-}
-
 func TestIsNumeric(t *testing.T) {
 	for l := 1; l < 100; l++ {
 		sb := strings.Builder{}
@@ -164,6 +58,13 @@ func TestIsNumeric(t *testing.T) {
 			t.Errorf("Not numeric string %s is recognized as one", s)
 		}
 	}
+
+	// digits of other scripts can't be put into numeric mode
+	for _, s := range []string{"\u0663", "12\uff13", "\u09e7"} {
+		if isNumeric(s) {
+			t.Errorf("Not ASCII digits %q are recognized as numeric", s)
+		}
+	}
 }
 
 func TestGetAlphaNumericNumber(t *testing.T) {
@@ -250,10 +151,10 @@ func TestAddCharacterCount(t *testing.T) {
 	for lvl := QRVersion(0); lvl < 100; lvl++ {
 		chCnt := rand.Int() % 100
 
-		chCntBa := newBitsetAppender()
+		chCntBa := NewBitWriter()
 		err := addCharacterCount([3]uint{10, 12, 14}, chCntBa, lvl, chCnt)
 
-		ba := newBitsetAppender()
+		ba := NewBitWriter()
 
 		var bitsNum uint
 		switch {
@@ -301,7 +202,7 @@ func TestAddPadding(t *testing.T) {
 
 	const l = 104
 	for num := uint(1); num < l; num++ {
-		ba := newBitsetAppender()
+		ba := NewBitWriter()
 		_ = ba.append(data, num)
 
 		addPadding(ba, l)
@@ -310,7 +211,7 @@ func TestAddPadding(t *testing.T) {
 			t.Errorf("Not all space is filled")
 		}
 
-		baCopy := newBitsetAppender()
+		baCopy := NewBitWriter()
 		_ = baCopy.append(data, num)
 
 		if baCopy.n < l-4 {
@@ -337,6 +238,12 @@ func TestAddPadding(t *testing.T) {
 			t.Errorf("Incorrect padding: %x != %x", ba.data, baCopy.data)
 		}
 	}
+
+	ba := NewBitWriter()
+	_ = ba.append(data, l+1)
+	if err := addPadding(ba, l); err != dataTooLongError {
+		t.Errorf("Padding of too long data gives %v instead of %v", err, dataTooLongError)
+	}
 }
 
 func TestNewNumericMarshaler(t *testing.T) {
@@ -372,7 +279,7 @@ func TestNumericMarshaler_MarshalString(t *testing.T) {
 
 		var sMarshaled []byte
 		{
-			ba := newBitsetAppender()
+			ba := NewBitWriter()
 			ba.appendByte(0b0001<<4, 4)
 			_ = addCharacterCount(numericBitCounts, ba, ver, len(s))
 			ba.appendUint16(0b1101100011<<6, 10)
@@ -426,7 +333,7 @@ func TestAlphanumericMarshaler_MarshalString(t *testing.T) {
 		var sMarshaled []byte
 		{
 			sSplitted := []string{"HE", "LL", "O ", "WO", "RL", "D"}
-			ba := newBitsetAppender()
+			ba := NewBitWriter()
 			ba.appendByte(0b0010<<4, 4)
 			_ = addCharacterCount(alphanumericBitCounts, ba, ver, len(s))
 			for _, pair := range sSplitted[:len(sSplitted)-1] {
@@ -473,7 +380,7 @@ func TestByteMarshaler_MarshalString(t *testing.T) {
 
 		var sMarshaled []byte
 		{
-			ba := newBitsetAppender()
+			ba := NewBitWriter()
 			ba.appendByte(0b0100<<4, 4)
 			_ = addCharacterCount(byteBitCounts, ba, ver, len(s))
 			for _, ch := range s {
@@ -539,3 +446,101 @@ func TestQRMarshaler_MarshalString(t *testing.T) {
 
 	}
 }
+
+func TestQRMarshaler_MarshalBytes(t *testing.T) {
+	// Arabic-Indic digit three is a digit for unicode, but it must stay binary
+	payload := []byte("\xd9\xa3")
+	data, err := NewQRMarshaler(L, 1).MarshalBytes(payload)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	expected, _ := NewByteMarshaler(L, 1).MarshalBytes(payload)
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Payload %x is marshaled as %x instead of %x", payload, data, expected)
+	}
+}
+
+func TestMarshalers_Capacity(t *testing.T) {
+	cases := []struct {
+		name     string
+		mller    Marshaler
+		str      string
+		expected error
+	}{
+		{"byte", NewByteMarshaler(L, 1), strings.Repeat("a", 17), nil},
+		{"byte", NewByteMarshaler(L, 1), strings.Repeat("a", 40), dataTooLongError},
+		{"numeric", NewNumericMarshaler(H, 1), strings.Repeat("1", 17), nil},
+		{"numeric", NewNumericMarshaler(H, 1), strings.Repeat("1", 18), dataTooLongError},
+		{"alphanumeric", NewAlphanumericMarshaler(Q, 2), strings.Repeat("A", 30), dataTooLongError},
+		{"qr", NewQRMarshaler(M, 1), strings.Repeat("a", 15), dataTooLongError},
+		{"fnc1", NewFNC1FirstMarshaler(L, 1), strings.Repeat("a", 20), dataTooLongError},
+		{"wrong level", NewByteMarshaler(H+1, 1), "a", wrongLevelError},
+	}
+
+	for _, c := range cases {
+		data, err := c.mller.MarshalString(c.str)
+		if err != c.expected {
+			t.Errorf("%s marshaler gives %v for %d characters instead of %v", c.name, err, len(c.str), c.expected)
+		}
+		if err != nil && data != nil {
+			t.Errorf("%s marshaler returns %d codewords with error", c.name, len(data))
+		}
+	}
+
+	// capacity of version 1-L is 19 codewords
+	if data, _ := NewByteMarshaler(L, 1).MarshalString(strings.Repeat("a", 17)); len(data) != 19 {
+		t.Errorf("Byte marshaler returns %d codewords instead of 19", len(data))
+	}
+}
+
+func TestFNC1Marshaler_MarshalString(t *testing.T) {
+	lvl := ErrorCorrectionLevel(L)
+	cases := []struct {
+		str     string
+		escaped string
+		mode    byte
+		counts  [3]uint
+	}{
+		{"0109506000134352", "0109506000134352", 0b0001, numericBitCounts},
+		{"10ABC%1\x1d2112", "10ABC%%1%2112", 0b0010, alphanumericBitCounts},
+		{"10abc\x1d2112", "10abc\x1d2112", 0b0100, byteBitCounts},
+	}
+
+	for _, c := range cases {
+		for _, second := range []bool{false, true} {
+			var fm *FNC1Marshaler
+			if second {
+				fm = NewFNC1SecondMarshaler(lvl, 1, 37)
+			} else {
+				fm = NewFNC1FirstMarshaler(lvl, 1)
+			}
+
+			data, err := fm.MarshalString(c.str)
+			if err != nil {
+				t.Errorf("Failed to marshal %q: %v", c.str, err)
+				continue
+			}
+
+			ba := NewBitWriter()
+			if second {
+				ba.appendByte(0b1001<<4, 4)
+				ba.appendByte(37, 8)
+			} else {
+				ba.appendByte(0b0101<<4, 4)
+			}
+			switch c.mode {
+			case 0b0001:
+				_ = encodeNumeric(ba, 1, c.escaped)
+			case 0b0010:
+				_ = encodeAlphanumeric(ba, 1, c.escaped)
+			default:
+				_ = encodeByte(ba, 1, c.escaped)
+			}
+			addPadding(ba, codewordsCapacities[lvl][0]*8)
+
+			if !bytes.Equal(data, ba.getData()) {
+				t.Errorf("Data %q isn't marshaled properly", c.str)
+			}
+		}
+	}
+}
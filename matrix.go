@@ -0,0 +1,406 @@
+package qr_tools
+
+import (
+	"errors"
+)
+
+var (
+	wrongMaskError       = errors.New("wrong mask")
+	wrongDataLengthError = errors.New("number of data codewords doesn't match qr version")
+	wrongTargetError     = errors.New("target isn't of the matrix size")
+)
+
+// Mask is enum that
+// shows which of 8 patterns inverts data modules of the matrix
+type Mask uint8
+
+// MaskCount is the number of masks, they go from 0 to MaskCount-1
+const MaskCount = 8
+
+// inverts tells if the mask inverts module (x, y)
+func (m Mask) inverts(x, y int) bool {
+	switch m {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// bchCode appends remainder of data divided by poly, that has ecBits+1 bits, to data
+func bchCode(data uint, ecBits int, poly uint) uint {
+	rem := data
+	for i := 0; i < ecBits; i++ {
+		rem = rem<<1 ^ (rem>>(ecBits-1))*poly
+	}
+	return data<<ecBits | rem
+}
+
+// levelBits are format information bits of ErrorCorrectionLevel
+var levelBits = []uint{L: 1, M: 0, Q: 3, H: 2}
+
+// formatBits returns 15 bits of format information: level, mask and BCH code masked by 0x5412
+func formatBits(lvl ErrorCorrectionLevel, mask Mask) uint {
+	return bchCode(levelBits[lvl]<<3|uint(mask), 10, 0x537) ^ 0x5412
+}
+
+// versionBits returns 18 bits of version information: version and BCH code
+func versionBits(ver QRVersion) uint {
+	return bchCode(uint(ver), 12, 0x1f25)
+}
+
+// formatPositions returns modules of both copies of format information,
+// bit i (counted from the least significant) of both copies goes to [i]
+func formatPositions(size int) (first, second [15]point) {
+	for i := 0; i < 15; i++ {
+		switch {
+		case i < 6:
+			first[i] = point{x: 8, y: i}
+		case i < 8:
+			first[i] = point{x: 8, y: i + 1}
+		case i == 8:
+			first[i] = point{x: 7, y: 8}
+		default:
+			first[i] = point{x: 14 - i, y: 8}
+		}
+
+		if i < 8 {
+			second[i] = point{x: size - 1 - i, y: 8}
+		} else {
+			second[i] = point{x: 8, y: size - 15 + i}
+		}
+	}
+	return first, second
+}
+
+// versionPositions returns modules of both copies of version information,
+// bit i (counted from the least significant) of both copies goes to [i]
+func versionPositions(size int) (first, second [18]point) {
+	for i := 0; i < 18; i++ {
+		a, b := size-11+i%3, i/3
+		first[i], second[i] = point{x: a, y: b}, point{x: b, y: a}
+	}
+	return first, second
+}
+
+// codewords returns data codewords with error correction codewords of all the blocks in placement order
+// throws wrongDataLengthError if data doesn't fill the capacity of chosen version and ErrorCorrectionLevel
+func codewords(data []byte, lvl ErrorCorrectionLevel, ver QRVersion) ([]byte, error) {
+	blocks, err := ECBlocks(lvl, ver)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != int(codewordsCapacities[lvl][ver-1]) {
+		return nil, wrongDataLengthError
+	}
+
+	full := make([][]byte, len(blocks))
+	for i, b := range blocks {
+		full[i] = append(data[:b.DataCodewords:b.DataCodewords], rsEncode(data[:b.DataCodewords], b.ECCodewords)...)
+		data = data[b.DataCodewords:]
+	}
+
+	order := codewordOrder(blocks)
+	stream := make([]byte, len(order))
+	for i, ref := range order {
+		stream[i] = full[ref.Block][ref.Index]
+	}
+	return stream, nil
+}
+
+// draw returns the matrix with function patterns, format and version information
+// and codewords put into data modules masked by chosen mask, remainder bits are 0
+func draw(stream []byte, lvl ErrorCorrectionLevel, ver QRVersion, mask Mask) ([][]bool, error) {
+	kinds, err := FunctionPatterns(ver)
+	if err != nil {
+		return nil, err
+	}
+
+	size := len(kinds)
+	modules := make([][]bool, size)
+	for y := range modules {
+		modules[y] = make([]bool, size)
+	}
+	set := func(p point, dark bool) {
+		modules[p.y][p.x] = dark
+	}
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			switch kinds[y][x] {
+			case TimingModule:
+				modules[y][x] = (x+y)%2 == 0
+			case DarkModule:
+				modules[y][x] = true
+			}
+		}
+	}
+
+	// finder patterns are 7 × 7 rings of dark, light and dark 3 × 3 square in the middle
+	for _, c := range []point{{x: 3, y: 3}, {x: size - 4, y: 3}, {x: 3, y: size - 4}} {
+		for y := max(c.y-3, 0); y <= min(c.y+3, size-1); y++ {
+			for x := max(c.x-3, 0); x <= min(c.x+3, size-1); x++ {
+				modules[y][x] = max(abs(x-c.x), abs(y-c.y)) != 2
+			}
+		}
+	}
+
+	// alignment patterns are 5 × 5 with dark center, the corners with finders are skipped by kinds
+	positions := alignmentPositions(ver)
+	for _, cy := range positions {
+		for _, cx := range positions {
+			if kinds[cy][cx] != AlignmentModule {
+				continue
+			}
+			for y := cy - 2; y <= cy+2; y++ {
+				for x := cx - 2; x <= cx+2; x++ {
+					modules[y][x] = max(abs(x-cx), abs(y-cy)) != 1
+				}
+			}
+		}
+	}
+
+	format := formatBits(lvl, mask)
+	first, second := formatPositions(size)
+	for i := range first {
+		set(first[i], format>>i&1 == 1)
+		set(second[i], format>>i&1 == 1)
+	}
+	if ver >= 7 {
+		version := versionBits(ver)
+		first, second := versionPositions(size)
+		for i := range first {
+			set(first[i], version>>i&1 == 1)
+			set(second[i], version>>i&1 == 1)
+		}
+	}
+
+	for bit, p := range placement(kinds) {
+		dark := bit/8 < len(stream) && stream[bit/8]>>(7-bit%8)&1 == 1
+		set(p, dark != mask.inverts(p.x, p.y))
+	}
+
+	return modules, nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// penalty scores how hard the matrix is to read with the rules of the standard:
+// long runs of one color, 2 × 2 blocks, finder-like patterns and the share of dark modules
+func penalty(modules [][]bool) int {
+	size := len(modules)
+	score := 0
+
+	at := func(i, j int, vertical bool) bool {
+		if vertical {
+			return modules[j][i]
+		}
+		return modules[i][j]
+	}
+	finderLike := []bool{true, false, true, true, true, false, true}
+	for _, vertical := range []bool{false, true} {
+		for i := 0; i < size; i++ {
+			run := 1
+			for j := 1; j <= size; j++ {
+				if j < size && at(i, j, vertical) == at(i, j-1, vertical) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += 3 + run - 5
+				}
+				run = 1
+			}
+
+			// 1:1:3:1:1 pattern with 4 light modules on one side
+			for j := 0; j+7 <= size; j++ {
+				match := true
+				for k, dark := range finderLike {
+					if at(i, j+k, vertical) != dark {
+						match = false
+						break
+					}
+				}
+				if !match {
+					continue
+				}
+				for _, side := range [][2]int{{j - 4, j}, {j + 7, j + 11}} {
+					if side[0] < 0 || side[1] > size {
+						continue
+					}
+					light := true
+					for k := side[0]; k < side[1]; k++ {
+						light = light && !at(i, k, vertical)
+					}
+					if light {
+						score += 40
+					}
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if modules[y][x] {
+				dark++
+			}
+			if x > 0 && y > 0 && modules[y][x] == modules[y-1][x] && modules[y][x] == modules[y][x-1] && modules[y][x] == modules[y-1][x-1] {
+				score += 3
+			}
+		}
+	}
+
+	// every 5% of deviation from the half of dark modules
+	total := size * size
+	score += ((abs(dark*20-total*10)+total-1)/total - 1) * 10
+
+	return score
+}
+
+// MatrixWithMask returns the matrix of QR code holding data codewords (as marshalers or EncodeSegments return them)
+// with error correction codewords added, data modules are masked by chosen mask
+// throws wrongMaskError, wrongDataLengthError and the errors of ECBlocks
+func MatrixWithMask(data []byte, lvl ErrorCorrectionLevel, ver QRVersion, mask Mask) ([][]bool, error) {
+	if mask >= MaskCount {
+		return nil, wrongMaskError
+	}
+	stream, err := codewords(data, lvl, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	return draw(stream, lvl, ver, mask)
+}
+
+// Matrix returns the matrix of QR code holding data codewords (as marshalers or EncodeSegments return them)
+// with error correction codewords added, the mask with the lowest penalty is chosen
+// throws wrongDataLengthError and the errors of ECBlocks
+func Matrix(data []byte, lvl ErrorCorrectionLevel, ver QRVersion) ([][]bool, error) {
+	stream, err := codewords(data, lvl, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	var best [][]bool
+	bestScore := 0
+	for mask := Mask(0); mask < MaskCount; mask++ {
+		modules, err := draw(stream, lvl, ver, mask)
+		if err != nil {
+			return nil, err
+		}
+		if score := penalty(modules); best == nil || score < bestScore {
+			best, bestScore = modules, score
+		}
+	}
+
+	return best, nil
+}
+
+// Encode encodes segments into the matrix of QR code with chosen version and ErrorCorrectionLevel
+// throws the errors of EncodeSegments
+func Encode(segments []Segment, lvl ErrorCorrectionLevel, ver QRVersion) ([][]bool, error) {
+	data, err := EncodeSegments(segments, lvl, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	return Matrix(data, lvl, ver)
+}
+
+// EncodeBiased encodes segments into the matrix like Encode does, but makes data modules follow target,
+// where true stands for dark module: pad codewords after the segments are filled with the bits of target
+// unmasked by each of the masks, and the mask with the fewest data modules different from target is chosen;
+// error correction codewords and codewords of the segments stay as they are
+// throws wrongTargetError if target isn't of the matrix size and the errors of EncodeSegments
+func EncodeBiased(segments []Segment, lvl ErrorCorrectionLevel, ver QRVersion, target [][]bool) ([][]bool, error) {
+	data, err := EncodeSegments(segments, lvl, ver)
+	if err != nil {
+		return nil, err
+	}
+	kinds, err := FunctionPatterns(ver)
+	if err != nil {
+		return nil, err
+	}
+	if len(target) != len(kinds) {
+		return nil, wrongTargetError
+	}
+	for _, row := range target {
+		if len(row) != len(kinds) {
+			return nil, wrongTargetError
+		}
+	}
+	blocks, err := ECBlocks(lvl, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	// codewords after the terminator are free, the decoder doesn't read them
+	bits, err := SegmentsBitLength(segments, ver)
+	if err != nil {
+		return nil, err
+	}
+	used := min(int(bits+4+7)/8, len(data))
+
+	// offsets[b] is the index of the first data codeword of block b in data
+	offsets := make([]int, len(blocks))
+	for b := 1; b < len(blocks); b++ {
+		offsets[b] = offsets[b-1] + blocks[b-1].DataCodewords
+	}
+
+	points := placement(kinds)
+	var best [][]bool
+	bestMismatches := 0
+	for mask := Mask(0); mask < MaskCount; mask++ {
+		biased := append([]byte(nil), data...)
+		for i, ref := range codewordOrder(blocks) {
+			index := offsets[ref.Block] + ref.Index
+			if ref.Index >= blocks[ref.Block].DataCodewords || index < used {
+				continue
+			}
+
+			var codeword byte
+			for _, p := range points[8*i : 8*i+8] {
+				codeword <<= 1
+				if target[p.y][p.x] != mask.inverts(p.x, p.y) {
+					codeword |= 1
+				}
+			}
+			biased[index] = codeword
+		}
+
+		modules, err := MatrixWithMask(biased, lvl, ver, mask)
+		if err != nil {
+			return nil, err
+		}
+		mismatches := 0
+		for _, p := range points {
+			if modules[p.y][p.x] != target[p.y][p.x] {
+				mismatches++
+			}
+		}
+		if best == nil || mismatches < bestMismatches {
+			best, bestMismatches = modules, mismatches
+		}
+	}
+
+	return best, nil
+}
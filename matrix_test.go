@@ -0,0 +1,174 @@
+package qr_tools
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFormatBits(t *testing.T) {
+	cases := []struct {
+		lvl      ErrorCorrectionLevel
+		mask     Mask
+		expected uint
+	}{
+		{L, 0, 0b111011111000100},
+		{M, 0, 0b101010000010010},
+		{Q, 0, 0b011010101011111},
+		{H, 0, 0b001011010001001},
+		{L, 7, 0b110100101110110},
+		{H, 7, 0b000100000111011},
+	}
+
+	for _, c := range cases {
+		if bits := formatBits(c.lvl, c.mask); bits != c.expected {
+			t.Errorf("Format of level %d with mask %d is %015b instead of %015b", c.lvl, c.mask, bits, c.expected)
+		}
+	}
+
+	if bits := versionBits(7); bits != 0b000111110010010100 {
+		t.Errorf("Version information of version 7 is %018b", bits)
+	}
+	if bits := versionBits(40); bits != 0b101000110001101001 {
+		t.Errorf("Version information of version 40 is %018b", bits)
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	segment, _ := NewAlphanumericSegment("HELLO WORLD")
+	data, _ := EncodeSegments([]Segment{segment}, M, 1)
+	stream, _ := codewords(data, M, 1)
+
+	for mask := Mask(0); mask < MaskCount; mask++ {
+		modules, err := MatrixWithMask(data, M, 1, mask)
+		if err != nil {
+			t.Fatalf("Failed to make matrix: %v", err)
+		}
+
+		// finder in the corner, timing pattern and the dark module
+		if !modules[0][0] || modules[1][1] || !modules[3][3] || modules[7][7] {
+			t.Errorf("Finder pattern with mask %d is drawn wrong", mask)
+		}
+		if !modules[6][8] || modules[6][9] || !modules[6][10] || !modules[13][8] {
+			t.Errorf("Timing pattern or dark module with mask %d is drawn wrong", mask)
+		}
+
+		// both copies of format information hold the mask
+		first, second := formatPositions(len(modules))
+		for i := range first {
+			bit := formatBits(M, mask)>>i&1 == 1
+			if modules[first[i].y][first[i].x] != bit || modules[second[i].y][second[i].x] != bit {
+				t.Errorf("Bit %d of format information with mask %d is drawn wrong", i, mask)
+			}
+		}
+
+		// unmasked data modules give codewords back
+		kinds, _ := FunctionPatterns(1)
+		read := make([]byte, len(stream))
+		for bit, p := range placement(kinds) {
+			if bit/8 < len(read) && modules[p.y][p.x] != mask.inverts(p.x, p.y) {
+				read[bit/8] |= 1 << (7 - bit%8)
+			}
+		}
+		if !reflect.DeepEqual(read, stream) {
+			t.Errorf("Matrix with mask %d holds %v instead of %v", mask, read, stream)
+		}
+	}
+
+	if _, err := Matrix(data[1:], M, 1); !errors.Is(err, wrongDataLengthError) {
+		t.Errorf("Short data is put into matrix with %v", err)
+	}
+	if _, err := MatrixWithMask(data, M, 1, MaskCount); !errors.Is(err, wrongMaskError) {
+		t.Errorf("Wrong mask is used with %v", err)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	segment := NewByteSegment([]byte("https://example.com/some/long/path"))
+	modules, err := Encode([]Segment{segment}, H, 7)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	if len(modules) != 45 {
+		t.Fatalf("Matrix of version 7 has %d modules in a side", len(modules))
+	}
+
+	// both copies of version information
+	first, second := versionPositions(45)
+	for i := range first {
+		bit := versionBits(7)>>i&1 == 1
+		if modules[first[i].y][first[i].x] != bit || modules[second[i].y][second[i].x] != bit {
+			t.Errorf("Bit %d of version information is drawn wrong", i)
+		}
+	}
+	// alignment pattern in the middle
+	if !modules[22][22] || modules[22][21] || !modules[22][20] {
+		t.Errorf("Alignment pattern is drawn wrong")
+	}
+
+	if _, err := Encode([]Segment{segment}, H, 1); !errors.Is(err, dataTooLongError) {
+		t.Errorf("Too long segments are encoded with %v", err)
+	}
+}
+
+func TestEncodeBiased(t *testing.T) {
+	segments := []Segment{NewByteSegment([]byte("biased"))}
+	// left half of the matrix is dark, the right one is light
+	target := make([][]bool, 37)
+	for y := range target {
+		target[y] = make([]bool, 37)
+		for x := range target[y] {
+			target[y][x] = x < 18
+		}
+	}
+	mismatches := func(modules [][]bool) int {
+		kinds, _ := FunctionPatterns(5)
+		count := 0
+		for _, p := range placement(kinds) {
+			if modules[p.y][p.x] != target[p.y][p.x] {
+				count++
+			}
+		}
+		return count
+	}
+
+	biased, err := EncodeBiased(segments, L, 5, target)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	plain, _ := Encode(segments, L, 5)
+	if mismatches(biased)*2 > mismatches(plain) {
+		t.Errorf("Biased matrix has %d modules different from target, the plain one %d", mismatches(biased), mismatches(plain))
+	}
+
+	data, _, ver, err := Decode(biased)
+	if err != nil {
+		t.Fatalf("Failed to decode biased matrix: %v", err)
+	}
+	if payload, err := NewQRUnmarshaler(ver).UnmarshalToString(data); err != nil || payload != "biased" {
+		t.Errorf("Biased matrix holds %q instead of %q: %v", payload, "biased", err)
+	}
+
+	if _, err := EncodeBiased(segments, L, 5, target[1:]); err != wrongTargetError {
+		t.Errorf("Target of wrong size is accepted with %v", err)
+	}
+}
+
+func TestPenalty(t *testing.T) {
+	light := make([][]bool, 21)
+	stripes := make([][]bool, 21)
+	for y := range light {
+		light[y] = make([]bool, 21)
+		stripes[y] = make([]bool, 21)
+		for x := range stripes[y] {
+			stripes[y][x] = (x+y)%2 == 0
+		}
+	}
+
+	if penalty(light) <= penalty(stripes) {
+		t.Errorf("Light matrix has penalty %d not greater than checkerboard %d", penalty(light), penalty(stripes))
+	}
+	if p := penalty(stripes); p != 0 {
+		t.Errorf("Checkerboard has penalty %d instead of 0", p)
+	}
+}
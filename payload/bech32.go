@@ -0,0 +1,147 @@
+package payload
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"strings"
+)
+
+const (
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	base58Charset = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+	// checksum constants of BIP173 and BIP350
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// bech32Polymod calculates BCH checksum of 5 bit values
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+
+	return chk
+}
+
+// decodeBech32 decodes bech32 or bech32m string into human readable part and 5 bit values (without checksum)
+// the string must be entirely lowercase or entirely uppercase
+func decodeBech32(str string) (hrp string, data []byte, checksum uint32, err error) {
+	if len(str) > 90 || (strings.ToLower(str) != str && strings.ToUpper(str) != str) {
+		return "", nil, 0, WrongValueError
+	}
+	str = strings.ToLower(str)
+
+	sep := strings.LastIndexByte(str, '1')
+	if sep < 1 || sep+7 > len(str) {
+		return "", nil, 0, WrongValueError
+	}
+	hrp = str[:sep]
+
+	values := make([]byte, 0, len(hrp)*2+1+len(str)-sep-1)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]>>5)
+	}
+	values = append(values, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]&31)
+	}
+
+	for i := sep + 1; i < len(str); i++ {
+		v := strings.IndexByte(bech32Charset, str[i])
+		if v == -1 {
+			return "", nil, 0, WrongValueError
+		}
+		data = append(data, byte(v))
+	}
+
+	checksum = bech32Polymod(append(values, data...))
+	return hrp, data[:len(data)-6], checksum, nil
+}
+
+// convertBits regroups bits of values from groups of size from to groups of size to
+// incomplete last group is allowed only if it's zero padding
+func convertBits(values []byte, from, to uint) ([]byte, bool) {
+	acc, bits := uint(0), uint(0)
+	converted := make([]byte, 0, len(values)*int(from)/int(to)+1)
+	for _, v := range values {
+		acc = acc<<from | uint(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			converted = append(converted, byte(acc>>bits&(1<<to-1)))
+		}
+	}
+
+	return converted, bits < from && acc&(1<<bits-1) == 0
+}
+
+// ValidateSegwitAddress checks bech32 (witness version 0) or bech32m (witness version 1 and higher) address
+// for bitcoin mainnet, testnet or regtest
+func ValidateSegwitAddress(addr string) error {
+	hrp, data, checksum, err := decodeBech32(addr)
+	if err != nil {
+		return err
+	}
+	if hrp != "bc" && hrp != "tb" && hrp != "bcrt" {
+		return WrongValueError
+	}
+	if len(data) == 0 || data[0] > 16 {
+		return WrongValueError
+	}
+
+	version := data[0]
+	if version == 0 && checksum != bech32Const || version != 0 && checksum != bech32mConst {
+		return WrongValueError
+	}
+
+	program, ok := convertBits(data[1:], 5, 8)
+	if !ok || len(program) < 2 || len(program) > 40 {
+		return WrongValueError
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return WrongValueError
+	}
+
+	return nil
+}
+
+// ValidateBase58Address checks legacy (P2PKH or P2SH) base58check address
+func ValidateBase58Address(addr string) error {
+	num := new(big.Int)
+	for i := 0; i < len(addr); i++ {
+		v := strings.IndexByte(base58Charset, addr[i])
+		if v == -1 {
+			return WrongValueError
+		}
+		num.Mul(num, big.NewInt(58))
+		num.Add(num, big.NewInt(int64(v)))
+	}
+
+	// leading ones stand for leading zero bytes
+	decoded := num.Bytes()
+	for i := 0; i < len(addr) && addr[i] == '1'; i++ {
+		decoded = append([]byte{0}, decoded...)
+	}
+	if len(decoded) != 25 {
+		return WrongValueError
+	}
+
+	first := sha256.Sum256(decoded[:21])
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], decoded[21:]) {
+		return WrongValueError
+	}
+
+	return nil
+}
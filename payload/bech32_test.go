@@ -0,0 +1,48 @@
+package payload
+
+import (
+	"testing"
+)
+
+func TestValidateSegwitAddress(t *testing.T) {
+	for _, addr := range []string{
+		"BC1QW508D6QEJXTDG4C5R3ZARVARY0C5XW7KM6DMX9",
+		"bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3",
+		"tb1qw508d6qejxtdg4c5r3zarvary0c5xw7k3ukgak",
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+	} {
+		if err := ValidateSegwitAddress(addr); err != nil {
+			t.Errorf("Valid address %s is not recognized as one", addr)
+		}
+	}
+
+	for _, addr := range []string{
+		// wrong checksum
+		"bc1qw508d6qejxtdg4c5r3zarvary0c5xw7km6dmx8",
+		// mixed case
+		"bc1qW508d6qejxtdg4c5r3zarvary0c5xw7km6dmx9",
+		// witness version 1 with bech32 checksum
+		"bc1pw508d6qejxtdg4c5r3zarvary0c5xw7kw508d6qejxtdg4c5r3zarvary0c5xw7k7grplx",
+		// unknown human readable part
+		"ltc1qw508d6qejxtdg4c5r3zarvary0c5xw7kgmn4n9",
+		"bc1",
+	} {
+		if err := ValidateSegwitAddress(addr); err == nil {
+			t.Errorf("Invalid address %s is recognized as valid", addr)
+		}
+	}
+}
+
+func TestValidateBase58Address(t *testing.T) {
+	for _, addr := range []string{"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy"} {
+		if err := ValidateBase58Address(addr); err != nil {
+			t.Errorf("Valid address %s is not recognized as one", addr)
+		}
+	}
+
+	for _, addr := range []string{"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN3", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN0", "1BvBMSEY"} {
+		if err := ValidateBase58Address(addr); err == nil {
+			t.Errorf("Invalid address %s is recognized as valid", addr)
+		}
+	}
+}
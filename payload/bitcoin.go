@@ -0,0 +1,196 @@
+package payload
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+const (
+	bitcoinScheme      = "bitcoin:"
+	satoshisPerBitcoin = 100_000_000
+	// bitcoinMaxAmount is 21 million bitcoins in satoshis
+	bitcoinMaxAmount = 21_000_000 * satoshisPerBitcoin
+)
+
+// BitcoinPayment is the payload of BIP21 bitcoin: URI
+type BitcoinPayment struct {
+	// Address is either bech32 (bech32m) segwit address or legacy base58 address
+	Address string
+	// Amount is in satoshis, omitted when 0
+	Amount  int64
+	Label   string
+	Message string
+	// Lightning is BOLT11 invoice that wallets supporting it can pay instead
+	Lightning string
+}
+
+// isSegwit tells if address looks like bech32 address rather than base58 one
+func isSegwit(addr string) bool {
+	lower := strings.ToLower(addr)
+	return strings.HasPrefix(lower, "bc1") || strings.HasPrefix(lower, "tb1") || strings.HasPrefix(lower, "bcrt1")
+}
+
+// formatBitcoinAmount formats satoshis as bitcoins without trailing zeroes
+func formatBitcoinAmount(satoshis int64) string {
+	s := strconv.FormatInt(satoshis/satoshisPerBitcoin, 10)
+	if satoshis%satoshisPerBitcoin == 0 {
+		return s
+	}
+
+	return s + "." + strings.TrimRight(strconv.FormatInt(satoshisPerBitcoin+satoshis%satoshisPerBitcoin, 10)[1:], "0")
+}
+
+// parseSatoshis parses amount in bitcoins with at most eight decimals into satoshis
+func parseSatoshis(str string) (int64, error) {
+	whole, fraction, _ := strings.Cut(str, ".")
+	if whole == "" || !isDigits(whole) || !isDigits(fraction) || len(fraction) > 8 {
+		return 0, WrongValueError
+	}
+
+	satoshis, err := strconv.ParseInt(whole+(fraction + "00000000")[:8], 10, 64)
+	if err != nil {
+		return 0, WrongValueError
+	}
+	return satoshis, nil
+}
+
+func (b *BitcoinPayment) validate() error {
+	if b.Amount < 0 || b.Amount > bitcoinMaxAmount {
+		return WrongValueError
+	}
+
+	if isSegwit(b.Address) {
+		return ValidateSegwitAddress(b.Address)
+	}
+	return ValidateBase58Address(b.Address)
+}
+
+// address returns scheme with the address
+// for bech32 address both of them are uppercased, so they fit alphanumeric mode
+func (b *BitcoinPayment) address() string {
+	if isSegwit(b.Address) {
+		return strings.ToUpper(bitcoinScheme + b.Address)
+	}
+	return bitcoinScheme + b.Address
+}
+
+// query returns URI parameters starting with ? or empty string if there are none
+func (b *BitcoinPayment) query() string {
+	sb := strings.Builder{}
+
+	sep := "?"
+	if b.Amount != 0 {
+		sb.WriteString(sep + "amount=" + formatBitcoinAmount(b.Amount))
+		sep = "&"
+	}
+	if b.Label != "" {
+		sb.WriteString(sep + "label=" + escapeQuery(b.Label))
+		sep = "&"
+	}
+	if b.Message != "" {
+		sb.WriteString(sep + "message=" + escapeQuery(b.Message))
+		sep = "&"
+	}
+	if b.Lightning != "" {
+		sb.WriteString(sep + "lightning=" + escapeQuery(b.Lightning))
+	}
+
+	return sb.String()
+}
+
+// Build returns bitcoin: URI
+// without parameters the URI with bech32 address is entirely alphanumeric
+func (b *BitcoinPayment) Build() (string, error) {
+	if err := b.validate(); err != nil {
+		return "", err
+	}
+
+	return b.address() + b.query(), nil
+}
+
+// String returns bitcoin: URI or empty string if it's not valid
+// use Build to get the error
+func (b *BitcoinPayment) String() string {
+	s, _ := b.Build()
+	return s
+}
+
+// Segments returns URI split into alphanumeric segment with bech32 address and byte segment with parameters
+// legacy address is put into byte segment together with parameters
+func (b *BitcoinPayment) Segments() ([]qr_tools.Segment, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	if !isSegwit(b.Address) {
+		return []qr_tools.Segment{qr_tools.NewByteSegment([]byte(b.address() + b.query()))}, nil
+	}
+
+	address, err := qr_tools.NewAlphanumericSegment(b.address())
+	if err != nil {
+		return nil, err
+	}
+
+	segments := []qr_tools.Segment{address}
+	if query := b.query(); query != "" {
+		segments = append(segments, qr_tools.NewByteSegment([]byte(query)))
+	}
+	return segments, nil
+}
+
+// Marshal marshals URI into the smallest QR version
+// with the highest ErrorCorrectionLevel that still fits into it
+func (b *BitcoinPayment) Marshal() ([]byte, qr_tools.ErrorCorrectionLevel, qr_tools.QRVersion, error) {
+	segments, err := b.Segments()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return marshalSegments(segments)
+}
+
+// ParseBitcoin parses BIP21 bitcoin: URI
+// throws WrongFormatError if there is unknown required (req-) parameter
+func ParseBitcoin(str string) (*BitcoinPayment, error) {
+	body, ok := cutScheme(str, bitcoinScheme)
+	if !ok {
+		return nil, WrongFormatError
+	}
+
+	addr, query, _ := strings.Cut(body, "?")
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, WrongFormatError
+	}
+	for key := range values {
+		if strings.HasPrefix(key, "req-") {
+			return nil, WrongFormatError
+		}
+	}
+
+	b := &BitcoinPayment{
+		Address:   addr,
+		Label:     values.Get("label"),
+		Message:   values.Get("message"),
+		Lightning: values.Get("lightning"),
+	}
+	if amount := values.Get("amount"); amount != "" {
+		if b.Amount, err = parseSatoshis(amount); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	// bech32 address is stored lowercase, as wallets show it
+	if isSegwit(b.Address) {
+		b.Address = strings.ToLower(b.Address)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,108 @@
+package payload
+
+import (
+	"reflect"
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+const (
+	testSegwitAddress = "bc1qw508d6qejxtdg4c5r3zarvary0c5xw7km6dmx9"
+	testLegacyAddress = "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2"
+)
+
+func TestBitcoinPayment_Build(t *testing.T) {
+	cases := []struct {
+		b        BitcoinPayment
+		expected string
+	}{
+		{BitcoinPayment{Address: testSegwitAddress},
+			"BITCOIN:BC1QW508D6QEJXTDG4C5R3ZARVARY0C5XW7KM6DMX9"},
+		{BitcoinPayment{Address: testSegwitAddress, Amount: 2_050_000, Label: "Luke Jr", Lightning: "lnbc1"},
+			"BITCOIN:BC1QW508D6QEJXTDG4C5R3ZARVARY0C5XW7KM6DMX9?amount=0.0205&label=Luke%20Jr&lightning=lnbc1"},
+		{BitcoinPayment{Address: testLegacyAddress, Amount: 100_000_000, Message: "Donation"},
+			"bitcoin:1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2?amount=1&message=Donation"},
+	}
+
+	for _, c := range cases {
+		s, err := c.b.Build()
+		if err != nil {
+			t.Errorf("Failed to build %v: %v", c.b, err)
+		} else if s != c.expected {
+			t.Errorf("Built %s instead of %s", s, c.expected)
+		}
+	}
+
+	for _, b := range []BitcoinPayment{
+		{Address: "bc1qw508d6qejxtdg4c5r3zarvary0c5xw7km6dmx8"},
+		{Address: "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN3"},
+		{Address: testSegwitAddress, Amount: -1},
+		{Address: testSegwitAddress, Amount: bitcoinMaxAmount + 1},
+	} {
+		if _, err := b.Build(); err == nil {
+			t.Errorf("Invalid payment %v is built without error", b)
+		}
+	}
+}
+
+func TestBitcoinPayment_Marshal(t *testing.T) {
+	// without parameters QRMarshaler chooses alphanumeric mode by itself
+	b := BitcoinPayment{Address: testSegwitAddress}
+	data, err := Marshal(&b, qr_tools.M, 3)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if data[0]>>4 != 0b0010 {
+		t.Errorf("Address is marshaled in mode %04b instead of alphanumeric", data[0]>>4)
+	}
+
+	b = BitcoinPayment{Address: testSegwitAddress, Amount: 2_050_000, Label: "Luke Jr"}
+	data, _, ver, err := b.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	segments, _ := b.Segments()
+	mixed, _ := qr_tools.SegmentsBitLength(segments, ver)
+	single, _ := qr_tools.NewByteSegment([]byte(b.String())).BitLength(ver)
+	if mixed >= single {
+		t.Errorf("Segments take %d bits, but the whole URI in byte mode takes %d", mixed, single)
+	}
+
+	unmarshaled, err := qr_tools.NewQRUnmarshaler(ver).UnmarshalToString(data)
+	if err != nil || unmarshaled != b.String() {
+		t.Errorf("Unmarshaled %q instead of %q: %v", unmarshaled, b.String(), err)
+	}
+}
+
+func TestParseBitcoin(t *testing.T) {
+	for _, b := range []BitcoinPayment{
+		{Address: testSegwitAddress},
+		{Address: testSegwitAddress, Amount: 1, Label: "a&b", Message: "Thanks!", Lightning: "lnbc1"},
+		{Address: testLegacyAddress, Amount: 2_100_000_000_000_000},
+	} {
+		parsed, err := ParseBitcoin(b.String())
+		if err != nil {
+			t.Errorf("Failed to parse %s: %v", b.String(), err)
+		} else if !reflect.DeepEqual(*parsed, b) {
+			t.Errorf("Parsed %v instead of %v", *parsed, b)
+		}
+	}
+
+	parsed, err := ParseBitcoin("bitcoin:" + testLegacyAddress + "?amount=50&label=Luke-Jr&somethingyoudontunderstand=50")
+	if err != nil || parsed.Amount != 5_000_000_000 || parsed.Label != "Luke-Jr" {
+		t.Errorf("Payment with unknown parameter is parsed wrong: %v, %v", parsed, err)
+	}
+
+	for _, s := range []string{
+		"litecoin:" + testSegwitAddress,
+		"bitcoin:" + testLegacyAddress + "?amount=0.000000001",
+		"bitcoin:" + testLegacyAddress + "?amount=1,5",
+		"bitcoin:" + testLegacyAddress + "?req-somethingyoudontunderstand=50",
+	} {
+		if _, err := ParseBitcoin(s); err == nil {
+			t.Errorf("Wrong payload %s is parsed without error", s)
+		}
+	}
+}
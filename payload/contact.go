@@ -0,0 +1,284 @@
+package payload
+
+import (
+	"strings"
+)
+
+// VCardVersion is enum that
+// shows what version of vCard format is used
+type VCardVersion string
+
+const (
+	VCard3 VCardVersion = "3.0"
+	VCard4 VCardVersion = "4.0"
+)
+
+// Address is the postal address of the contact
+type Address struct {
+	POBox      string
+	Extended   string
+	Street     string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+}
+
+func (a Address) components() []string {
+	return []string{a.POBox, a.Extended, a.Street, a.City, a.Region, a.PostalCode, a.Country}
+}
+
+func (a Address) isEmpty() bool {
+	return a == Address{}
+}
+
+// newAddress makes address from its components, missing ones are left empty
+func newAddress(components []string) Address {
+	components = append(components, make([]string, 7)...)
+	return Address{
+		POBox:      components[0],
+		Extended:   components[1],
+		Street:     components[2],
+		City:       components[3],
+		Region:     components[4],
+		PostalCode: components[5],
+		Country:    components[6],
+	}
+}
+
+// VCard is the payload with contact information in vCard format
+type VCard struct {
+	// Version is VCard3 when empty
+	Version    VCardVersion
+	FamilyName string
+	GivenName  string
+	// FormattedName is made of GivenName and FamilyName when empty
+	FormattedName string
+	Organization  string
+	Title         string
+	Phones        []string
+	Emails        []string
+	URL           string
+	Address       Address
+	Note          string
+}
+
+// escapeVCard escapes vCard text value
+func escapeVCard(str string) string {
+	return strings.ReplaceAll(escape(str, ",;"), "\n", "\\n")
+}
+
+// unescapeVCard unescapes vCard text value
+func unescapeVCard(str string) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(str); i++ {
+		if str[i] == '\\' && i+1 < len(str) {
+			i++
+			if str[i] == 'n' || str[i] == 'N' {
+				sb.WriteByte('\n')
+				continue
+			}
+		}
+		sb.WriteByte(str[i])
+	}
+
+	return sb.String()
+}
+
+func joinVCard(components []string) string {
+	escaped := make([]string, len(components))
+	for i, c := range components {
+		escaped[i] = escapeVCard(c)
+	}
+	return strings.Join(escaped, ";")
+}
+
+func splitVCard(value string) []string {
+	components := splitEscaped(value, ';')
+	for i, c := range components {
+		components[i] = unescapeVCard(c)
+	}
+	return components
+}
+
+// String returns vCard payload
+// empty properties are omitted
+func (v *VCard) String() string {
+	version := v.Version
+	if version == "" {
+		version = VCard3
+	}
+	formattedName := v.FormattedName
+	if formattedName == "" {
+		formattedName = strings.TrimSpace(v.GivenName + " " + v.FamilyName)
+	}
+
+	lines := []string{"BEGIN:VCARD", "VERSION:" + string(version)}
+	lines = append(lines, "N:"+joinVCard([]string{v.FamilyName, v.GivenName, "", "", ""}))
+	lines = append(lines, "FN:"+escapeVCard(formattedName))
+	if v.Organization != "" {
+		lines = append(lines, "ORG:"+escapeVCard(v.Organization))
+	}
+	if v.Title != "" {
+		lines = append(lines, "TITLE:"+escapeVCard(v.Title))
+	}
+	for _, phone := range v.Phones {
+		lines = append(lines, "TEL:"+escapeVCard(phone))
+	}
+	for _, email := range v.Emails {
+		lines = append(lines, "EMAIL:"+escapeVCard(email))
+	}
+	if v.URL != "" {
+		lines = append(lines, "URL:"+escapeVCard(v.URL))
+	}
+	if !v.Address.isEmpty() {
+		lines = append(lines, "ADR:"+joinVCard(v.Address.components()))
+	}
+	if v.Note != "" {
+		lines = append(lines, "NOTE:"+escapeVCard(v.Note))
+	}
+	lines = append(lines, "END:VCARD")
+
+	return strings.Join(lines, "\r\n")
+}
+
+// ParseVCard parses vCard payload
+// unknown properties and parameters of properties are skipped
+func ParseVCard(str string) (*VCard, error) {
+	// unfolding lines that were split
+	str = strings.ReplaceAll(str, "\r\n", "\n")
+	str = strings.NewReplacer("\n ", "", "\n\t", "").Replace(str)
+	lines := strings.Split(strings.TrimSpace(str), "\n")
+
+	if len(lines) < 2 || !strings.EqualFold(lines[0], "BEGIN:VCARD") || !strings.EqualFold(lines[len(lines)-1], "END:VCARD") {
+		return nil, WrongFormatError
+	}
+
+	v := &VCard{}
+	for _, line := range lines[1 : len(lines)-1] {
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, WrongFormatError
+		}
+		// parameters like TYPE=CELL are skipped
+		name, _, _ = strings.Cut(strings.ToUpper(name), ";")
+
+		switch name {
+		case "VERSION":
+			v.Version = VCardVersion(value)
+		case "N":
+			components := append(splitVCard(value), "", "")
+			v.FamilyName, v.GivenName = components[0], components[1]
+		case "FN":
+			v.FormattedName = unescapeVCard(value)
+		case "ORG":
+			v.Organization = unescapeVCard(value)
+		case "TITLE":
+			v.Title = unescapeVCard(value)
+		case "TEL":
+			v.Phones = append(v.Phones, strings.TrimPrefix(unescapeVCard(value), "tel:"))
+		case "EMAIL":
+			v.Emails = append(v.Emails, unescapeVCard(value))
+		case "URL":
+			v.URL = unescapeVCard(value)
+		case "ADR":
+			v.Address = newAddress(splitVCard(value))
+		case "NOTE":
+			v.Note = unescapeVCard(value)
+		}
+	}
+
+	return v, nil
+}
+
+// MeCard is the payload with contact information in MECARD format
+// it's more compact than VCard
+type MeCard struct {
+	FamilyName string
+	GivenName  string
+	Phones     []string
+	Emails     []string
+	URL        string
+	Address    Address
+	// Birthday is the date in YYYYMMDD format
+	Birthday string
+	Note     string
+}
+
+// String returns MECARD: payload
+// empty fields are omitted
+func (m *MeCard) String() string {
+	sb := strings.Builder{}
+	sb.WriteString("MECARD:N:")
+	sb.WriteString(escape(m.FamilyName, fieldSpecials))
+	if m.GivenName != "" {
+		sb.WriteString("," + escape(m.GivenName, fieldSpecials))
+	}
+	sb.WriteString(";")
+
+	for _, phone := range m.Phones {
+		sb.WriteString("TEL:" + escape(phone, fieldSpecials) + ";")
+	}
+	for _, email := range m.Emails {
+		sb.WriteString("EMAIL:" + escape(email, fieldSpecials) + ";")
+	}
+	if m.URL != "" {
+		sb.WriteString("URL:" + escape(m.URL, fieldSpecials) + ";")
+	}
+	if !m.Address.isEmpty() {
+		components := m.Address.components()
+		for i, c := range components {
+			components[i] = escape(c, fieldSpecials)
+		}
+		sb.WriteString("ADR:" + strings.Join(components, ",") + ";")
+	}
+	if m.Birthday != "" {
+		sb.WriteString("BDAY:" + escape(m.Birthday, fieldSpecials) + ";")
+	}
+	if m.Note != "" {
+		sb.WriteString("NOTE:" + escape(m.Note, fieldSpecials) + ";")
+	}
+	sb.WriteString(";")
+
+	return sb.String()
+}
+
+// ParseMeCard parses MECARD: payload
+func ParseMeCard(str string) (*MeCard, error) {
+	body, ok := cutScheme(str, "MECARD:")
+	if !ok {
+		return nil, WrongFormatError
+	}
+
+	fields, err := parseFields(body)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MeCard{}
+	for _, f := range fields {
+		switch f.key {
+		case "N":
+			components := append(splitEscaped(f.value, ','), "")
+			m.FamilyName, m.GivenName = unescape(components[0]), unescape(components[1])
+		case "TEL":
+			m.Phones = append(m.Phones, unescape(f.value))
+		case "EMAIL":
+			m.Emails = append(m.Emails, unescape(f.value))
+		case "URL":
+			m.URL = unescape(f.value)
+		case "ADR":
+			components := splitEscaped(f.value, ',')
+			for i, c := range components {
+				components[i] = unescape(c)
+			}
+			m.Address = newAddress(components)
+		case "BDAY":
+			m.Birthday = unescape(f.value)
+		case "NOTE":
+			m.Note = unescape(f.value)
+		}
+	}
+
+	return m, nil
+}
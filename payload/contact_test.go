@@ -0,0 +1,72 @@
+package payload
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVCard_String(t *testing.T) {
+	v := VCard{FamilyName: "Doe", GivenName: "John", Phones: []string{"+12125551234"}, Note: "a,b;c\nd"}
+	expected := "BEGIN:VCARD\r\nVERSION:3.0\r\nN:Doe;John;;;\r\nFN:John Doe\r\nTEL:+12125551234\r\nNOTE:a\\,b\\;c\\nd\r\nEND:VCARD"
+	if s := v.String(); s != expected {
+		t.Errorf("Built %q instead of %q", s, expected)
+	}
+}
+
+func TestParseVCard(t *testing.T) {
+	for _, v := range []VCard{
+		{Version: VCard3, FamilyName: "Doe", GivenName: "John", FormattedName: "John Doe", Phones: []string{"+12125551234", "+12125550000"}, Emails: []string{"john@example.com"}},
+		{Version: VCard4, FamilyName: "Do;e", GivenName: "Ja,ne", FormattedName: "Dr. Jane", Organization: "ACME", Title: "CEO", URL: "https://example.com",
+			Address: Address{Street: "1 Main St", City: "Springfield", Country: "USA"}, Note: "line 1\nline \\2"},
+	} {
+		parsed, err := ParseVCard(v.String())
+		if err != nil {
+			t.Errorf("Failed to parse %q: %v", v.String(), err)
+		} else if !reflect.DeepEqual(*parsed, v) {
+			t.Errorf("Parsed %v instead of %v", *parsed, v)
+		}
+	}
+
+	folded := "BEGIN:VCARD\nVERSION:4.0\nFN:John\n  Doe\nTEL;TYPE=cell:tel:+1234\nEND:VCARD"
+	parsed, err := ParseVCard(folded)
+	if err != nil {
+		t.Fatalf("Failed to parse folded vCard: %v", err)
+	}
+	if parsed.FormattedName != "John Doe" || len(parsed.Phones) != 1 || parsed.Phones[0] != "+1234" {
+		t.Errorf("Folded vCard is parsed wrong: %v", *parsed)
+	}
+
+	for _, s := range []string{"BEGIN:VCARD\nFN:John", "FN:John\nEND:VCARD", "BEGIN:VCARD\nFN\nEND:VCARD"} {
+		if _, err := ParseVCard(s); err == nil {
+			t.Errorf("Wrong payload %q is parsed without error", s)
+		}
+	}
+}
+
+func TestMeCard_String(t *testing.T) {
+	m := MeCard{FamilyName: "Doe", GivenName: "John", Phones: []string{"+12125551234"}, URL: "http://a.b"}
+	expected := `MECARD:N:Doe,John;TEL:+12125551234;URL:http\://a.b;;`
+	if s := m.String(); s != expected {
+		t.Errorf("Built %s instead of %s", s, expected)
+	}
+}
+
+func TestParseMeCard(t *testing.T) {
+	for _, m := range []MeCard{
+		{FamilyName: "Doe", GivenName: "John", Phones: []string{"+12125551234"}, Emails: []string{"a@b.c", "d@e.f"}},
+		{FamilyName: "D,oe", URL: "http://a.b", Address: Address{Street: "1, Main St", City: "Springfield"}, Birthday: "19700101", Note: `x;y:z\`},
+	} {
+		parsed, err := ParseMeCard(m.String())
+		if err != nil {
+			t.Errorf("Failed to parse %s: %v", m.String(), err)
+		} else if !reflect.DeepEqual(*parsed, m) {
+			t.Errorf("Parsed %v instead of %v", *parsed, m)
+		}
+	}
+
+	for _, s := range []string{"MECARD:N:Doe", "VCARD:N:Doe;;"} {
+		if _, err := ParseMeCard(s); err == nil {
+			t.Errorf("Wrong payload %s is parsed without error", s)
+		}
+	}
+}
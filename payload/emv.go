@@ -0,0 +1,306 @@
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+var (
+	WrongCRCError = errors.New("crc of payload doesn't match")
+)
+
+// ids of EMV QRCPS merchant-presented data objects
+const (
+	emvFormatIndicator    = "00"
+	emvInitiationMethod   = "01"
+	emvCategoryCode       = "52"
+	emvCurrency           = "53"
+	emvAmount             = "54"
+	emvCountryCode        = "58"
+	emvMerchantName       = "59"
+	emvMerchantCity       = "60"
+	emvPostalCode         = "61"
+	emvAdditionalData     = "62"
+	emvCRC                = "63"
+	emvFirstAccount       = 26
+	emvLastAccount        = 51
+	emvStaticInitiation   = "11"
+	emvDynamicInitiation  = "12"
+	emvFormatIndicatorVal = "01"
+)
+
+// EMVField is a single data object (id and value) of EMV payload
+type EMVField struct {
+	ID    string
+	Value string
+}
+
+// EMVTemplate is a data object consisting of other data objects
+// (like merchant account information)
+type EMVTemplate struct {
+	ID     string
+	Fields []EMVField
+}
+
+// EMVPayment is the payload of EMV QRCPS merchant-presented QR code
+// used by PIX, UPI and other payment schemes
+type EMVPayment struct {
+	// Dynamic is set for single use codes (the ones with amount usually)
+	Dynamic bool
+	// MerchantAccounts are templates with ids from 26 to 51
+	MerchantAccounts []EMVTemplate
+	// MerchantCategoryCode is ISO 18245 code, 0000 is used when empty
+	MerchantCategoryCode string
+	// Currency is ISO 4217 numeric code
+	Currency string
+	// Amount is decimal number with dot as separator, omitted when empty
+	Amount       string
+	CountryCode  string
+	MerchantName string
+	MerchantCity string
+	PostalCode   string
+	// AdditionalData are fields of additional data template (62), omitted when empty
+	AdditionalData []EMVField
+}
+
+// CRC16 calculates CRC-16/CCITT-FALSE (polynomial 0x1021, initial value 0xFFFF)
+func CRC16(data []byte) uint16 {
+	crc := uint16(0xffff)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
+
+// writeTLV writes id, two digit length and value
+func writeTLV(sb *strings.Builder, id, value string) error {
+	if len(id) != 2 || !isDigits(id) || len(value) > 99 {
+		return WrongValueError
+	}
+
+	sb.WriteString(id)
+	sb.WriteString(fmt.Sprintf("%02d", len(value)))
+	sb.WriteString(value)
+	return nil
+}
+
+func writeTemplate(sb *strings.Builder, id string, fields []EMVField) error {
+	inner := strings.Builder{}
+	for _, f := range fields {
+		if err := writeTLV(&inner, f.ID, f.Value); err != nil {
+			return err
+		}
+	}
+
+	return writeTLV(sb, id, inner.String())
+}
+
+// readTLV splits data into the list of data objects
+func readTLV(data string) ([]EMVField, error) {
+	fields := make([]EMVField, 0)
+	for len(data) > 0 {
+		if len(data) < 4 || !isDigits(data[:4]) {
+			return nil, WrongFormatError
+		}
+
+		l, _ := strconv.Atoi(data[2:4])
+		if len(data) < 4+l {
+			return nil, WrongFormatError
+		}
+
+		fields = append(fields, EMVField{ID: data[:2], Value: data[4 : 4+l]})
+		data = data[4+l:]
+	}
+
+	return fields, nil
+}
+
+func isAmount(str string) bool {
+	whole, fraction, _ := strings.Cut(str, ".")
+	return len(str) <= 13 && whole != "" && isDigits(whole) && isDigits(fraction)
+}
+
+// validate checks the field limits of the specification
+func (p *EMVPayment) validate() error {
+	switch {
+	case len(p.MerchantAccounts) == 0:
+		return WrongValueError
+	case p.MerchantCategoryCode != "" && (len(p.MerchantCategoryCode) != 4 || !isDigits(p.MerchantCategoryCode)):
+		return WrongValueError
+	case len(p.Currency) != 3 || !isDigits(p.Currency):
+		return WrongValueError
+	case p.Amount != "" && !isAmount(p.Amount):
+		return WrongValueError
+	case len(p.CountryCode) != 2:
+		return WrongValueError
+	case p.MerchantName == "" || len(p.MerchantName) > 25:
+		return WrongValueError
+	case p.MerchantCity == "" || len(p.MerchantCity) > 15:
+		return WrongValueError
+	case len(p.PostalCode) > 10:
+		return WrongValueError
+	}
+
+	for _, account := range p.MerchantAccounts {
+		id, err := strconv.Atoi(account.ID)
+		if err != nil || id < emvFirstAccount || id > emvLastAccount {
+			return WrongValueError
+		}
+	}
+
+	return nil
+}
+
+// Build returns EMV payload with CRC (tag 63) at the end
+func (p *EMVPayment) Build() (string, error) {
+	if err := p.validate(); err != nil {
+		return "", err
+	}
+
+	sb := strings.Builder{}
+	_ = writeTLV(&sb, emvFormatIndicator, emvFormatIndicatorVal)
+	if p.Dynamic {
+		_ = writeTLV(&sb, emvInitiationMethod, emvDynamicInitiation)
+	} else {
+		_ = writeTLV(&sb, emvInitiationMethod, emvStaticInitiation)
+	}
+
+	for _, account := range p.MerchantAccounts {
+		if err := writeTemplate(&sb, account.ID, account.Fields); err != nil {
+			return "", err
+		}
+	}
+
+	mcc := p.MerchantCategoryCode
+	if mcc == "" {
+		mcc = "0000"
+	}
+	_ = writeTLV(&sb, emvCategoryCode, mcc)
+	_ = writeTLV(&sb, emvCurrency, p.Currency)
+	if p.Amount != "" {
+		_ = writeTLV(&sb, emvAmount, p.Amount)
+	}
+	_ = writeTLV(&sb, emvCountryCode, p.CountryCode)
+	_ = writeTLV(&sb, emvMerchantName, p.MerchantName)
+	_ = writeTLV(&sb, emvMerchantCity, p.MerchantCity)
+	if p.PostalCode != "" {
+		_ = writeTLV(&sb, emvPostalCode, p.PostalCode)
+	}
+	if len(p.AdditionalData) != 0 {
+		if err := writeTemplate(&sb, emvAdditionalData, p.AdditionalData); err != nil {
+			return "", err
+		}
+	}
+
+	// crc is calculated over the whole payload including its own id and length
+	sb.WriteString(emvCRC + "04")
+	sb.WriteString(fmt.Sprintf("%04X", CRC16([]byte(sb.String()))))
+
+	return sb.String(), nil
+}
+
+// String is meant for debugging: it returns EMV payload or the reason it can't be built,
+// use Build or Marshal to get the payload that is put into the code
+func (p *EMVPayment) String() string {
+	s, err := p.Build()
+	if err != nil {
+		return "invalid EMV payment: " + err.Error()
+	}
+	return s
+}
+
+// Segments returns the payload as a single segment of the cheapest mode
+func (p *EMVPayment) Segments() ([]qr_tools.Segment, error) {
+	s, err := p.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return []qr_tools.Segment{cheapestSegment(s)}, nil
+}
+
+// Marshal marshals the payload into the smallest QR version
+// with the highest ErrorCorrectionLevel that still fits into it
+func (p *EMVPayment) Marshal() ([]byte, qr_tools.ErrorCorrectionLevel, qr_tools.QRVersion, error) {
+	segments, err := p.Segments()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return marshalSegments(segments)
+}
+
+// ParseEMV verifies CRC and parses EMV merchant-presented payload
+func ParseEMV(str string) (*EMVPayment, error) {
+	// crc data object is always the last one and takes 8 characters
+	if len(str) < 8 || str[len(str)-8:len(str)-4] != emvCRC+"04" {
+		return nil, WrongFormatError
+	}
+	crc, err := strconv.ParseUint(str[len(str)-4:], 16, 16)
+	if err != nil {
+		return nil, WrongFormatError
+	}
+	if uint16(crc) != CRC16([]byte(str[:len(str)-4])) {
+		return nil, WrongCRCError
+	}
+
+	fields, err := readTLV(str[:len(str)-8])
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 || fields[0].ID != emvFormatIndicator || fields[0].Value != emvFormatIndicatorVal {
+		return nil, WrongFormatError
+	}
+
+	p := &EMVPayment{}
+	for _, f := range fields[1:] {
+		switch f.ID {
+		case emvInitiationMethod:
+			p.Dynamic = f.Value == emvDynamicInitiation
+		case emvCategoryCode:
+			p.MerchantCategoryCode = f.Value
+		case emvCurrency:
+			p.Currency = f.Value
+		case emvAmount:
+			p.Amount = f.Value
+		case emvCountryCode:
+			p.CountryCode = f.Value
+		case emvMerchantName:
+			p.MerchantName = f.Value
+		case emvMerchantCity:
+			p.MerchantCity = f.Value
+		case emvPostalCode:
+			p.PostalCode = f.Value
+		case emvAdditionalData:
+			if p.AdditionalData, err = readTLV(f.Value); err != nil {
+				return nil, err
+			}
+		default:
+			if id, _ := strconv.Atoi(f.ID); id >= emvFirstAccount && id <= emvLastAccount {
+				inner, err := readTLV(f.Value)
+				if err != nil {
+					return nil, err
+				}
+				p.MerchantAccounts = append(p.MerchantAccounts, EMVTemplate{ID: f.ID, Fields: inner})
+			}
+		}
+	}
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
@@ -0,0 +1,118 @@
+package payload
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+func TestCRC16(t *testing.T) {
+	if crc := CRC16([]byte("123456789")); crc != 0x29b1 {
+		t.Errorf("CRC of check string is %04X instead of 29B1", crc)
+	}
+}
+
+func testEMVPayment() EMVPayment {
+	return EMVPayment{
+		Dynamic: true,
+		MerchantAccounts: []EMVTemplate{
+			{ID: "26", Fields: []EMVField{{ID: "00", Value: "br.gov.bcb.pix"}, {ID: "01", Value: "123e4567-e12b-12d1-a456-426655440000"}}},
+		},
+		MerchantCategoryCode: "0000",
+		Currency:             "986",
+		Amount:               "10.50",
+		CountryCode:          "BR",
+		MerchantName:         "Fulano de Tal",
+		MerchantCity:         "BRASILIA",
+		AdditionalData:       []EMVField{{ID: "05", Value: "***"}},
+	}
+}
+
+func TestEMVPayment_Build(t *testing.T) {
+	p := testEMVPayment()
+	s, err := p.Build()
+	if err != nil {
+		t.Fatalf("Failed to build payload: %v", err)
+	}
+
+	expected := "000201010212" +
+		"2658" + "0014br.gov.bcb.pix" + "0136123e4567-e12b-12d1-a456-426655440000" +
+		"52040000" + "5303986" + "540510.50" + "5802BR" + "5913Fulano de Tal" + "6008BRASILIA" +
+		"62070503***" + "6304"
+	if s[:len(s)-4] != expected {
+		t.Errorf("Built %s instead of %s", s[:len(s)-4], expected)
+	}
+
+	for _, broken := range []func(p *EMVPayment){
+		func(p *EMVPayment) { p.MerchantAccounts = nil },
+		func(p *EMVPayment) { p.MerchantAccounts[0].ID = "25" },
+		func(p *EMVPayment) { p.Currency = "BRL" },
+		func(p *EMVPayment) { p.Amount = "10,50" },
+		func(p *EMVPayment) { p.MerchantName = "A very long merchant name that doesn't fit" },
+		func(p *EMVPayment) { p.MerchantCity = "" },
+	} {
+		p := testEMVPayment()
+		broken(&p)
+		if _, err := p.Build(); err == nil {
+			t.Errorf("Invalid payload %v is built", p)
+		}
+		if _, err := Marshal(&p, qr_tools.L, 10); err == nil {
+			t.Errorf("Invalid payload %v is marshaled", p)
+		}
+		if _, _, _, err := p.Marshal(); err == nil {
+			t.Errorf("Invalid payload %v is marshaled", p)
+		}
+		if _, err := p.Build(); !strings.HasSuffix(p.String(), err.Error()) {
+			t.Errorf("String of invalid payload %q doesn't tell why", p.String())
+		}
+	}
+}
+
+func TestEMVPayment_Marshal(t *testing.T) {
+	p := testEMVPayment()
+	expected, _ := p.Build()
+
+	data, _, ver, err := p.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if got, err := qr_tools.NewQRUnmarshaler(ver).UnmarshalToString(data); err != nil || got != expected {
+		t.Errorf("Unmarshaled %q instead of %q: %v", got, expected, err)
+	}
+
+	// payload without lowercase letters is marshaled in alphanumeric mode
+	p.MerchantName, p.AdditionalData = "FULANO DE TAL", nil
+	p.MerchantAccounts[0].Fields[0].Value = "BR.GOV.BCB.PIX"
+	p.MerchantAccounts[0].Fields[1].Value = "+5561912345678"
+	segments, err := p.Segments()
+	if err != nil || len(segments) != 1 || segments[0].Mode != qr_tools.AlphanumericMode {
+		t.Errorf("Uppercase payload is put into %v: %v", segments, err)
+	}
+}
+
+func TestParseEMV(t *testing.T) {
+	p := testEMVPayment()
+	s, _ := p.Build()
+
+	parsed, err := ParseEMV(s)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", s, err)
+	}
+	if !reflect.DeepEqual(*parsed, p) {
+		t.Errorf("Parsed %v instead of %v", *parsed, p)
+	}
+
+	tampered := []byte(s)
+	tampered[len(tampered)-20] ^= 1
+	if _, err := ParseEMV(string(tampered)); err != WrongCRCError {
+		t.Errorf("Tampered payload is parsed without crc error")
+	}
+
+	for _, s := range []string{"", "00020101021163041234", "0002010102116304ZZZZ"} {
+		if _, err := ParseEMV(s); err == nil {
+			t.Errorf("Wrong payload %s is parsed without error", s)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package payload
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+var (
+	VersionTooBigError = errors.New("qr version is greater than the standard allows")
+	TooLongError       = errors.New("payload doesn't fit into chosen qr version")
+)
+
+const (
+	// EPCMaxVersion is the greatest QR version allowed by EPC069-12
+	EPCMaxVersion = 13
+	// EPCLevel is the ErrorCorrectionLevel required by EPC069-12
+	EPCLevel = qr_tools.M
+
+	epcMaxLen       = 331
+	epcMaxAmount    = 999999999_99
+	epcServiceTag   = "BCD"
+	epcVersion      = "002"
+	epcCharsetUTF8  = "1"
+	epcIdentifier   = "SCT"
+	epcCurrencyCode = "EUR"
+)
+
+// EPCTransfer is the payload of SEPA credit transfer (EPC069-12 "BCD" code)
+type EPCTransfer struct {
+	// BIC is optional in version 002
+	BIC  string
+	Name string
+	IBAN string
+	// Amount is in euro cents, omitted when 0
+	Amount int64
+	// Purpose is 4 letter ISO 20022 purpose code
+	Purpose string
+	// Reference is structured creditor reference, it can't be used together with Text
+	Reference string
+	// Text is unstructured remittance information, it can't be used together with Reference
+	Text        string
+	Information string
+}
+
+// formatEPCAmount formats cents as euros without trailing zeroes
+func formatEPCAmount(cents int64) string {
+	s := strconv.FormatInt(cents/100, 10)
+	if cents%100 == 0 {
+		return s
+	}
+
+	return strings.TrimSuffix(s+"."+strconv.FormatInt(100+cents%100, 10)[1:], "0")
+}
+
+// parseCents parses amount with at most two decimals into cents
+func parseCents(str string) (int64, error) {
+	whole, fraction, _ := strings.Cut(str, ".")
+	if whole == "" || !isDigits(whole) || !isDigits(fraction) || len(fraction) > 2 {
+		return 0, WrongValueError
+	}
+
+	cents, err := strconv.ParseInt(whole+(fraction + "00")[:2], 10, 64)
+	if err != nil {
+		return 0, WrongValueError
+	}
+	return cents, nil
+}
+
+// validate checks the field limits of EPC069-12
+func (e *EPCTransfer) validate() error {
+	switch {
+	case len(e.BIC) != 0 && len(e.BIC) != 8 && len(e.BIC) != 11:
+		return WrongValueError
+	case e.Name == "" || len(e.Name) > 70:
+		return WrongValueError
+	case e.Amount < 0 || e.Amount > epcMaxAmount:
+		return WrongValueError
+	case len(e.Purpose) != 0 && len(e.Purpose) != 4:
+		return WrongValueError
+	case e.Reference != "" && e.Text != "":
+		return WrongValueError
+	case len(e.Reference) > 35 || len(e.Text) > 140 || len(e.Information) > 70:
+		return WrongValueError
+	}
+
+	if e.Reference != "" {
+		if err := ValidateCreditorReference(e.Reference); err != nil {
+			return err
+		}
+	}
+
+	return ValidateIBAN(NormalizeIBAN(e.IBAN))
+}
+
+// Build returns EPC payload
+// empty trailing lines are omitted
+func (e *EPCTransfer) Build() (string, error) {
+	if err := e.validate(); err != nil {
+		return "", err
+	}
+
+	amount := ""
+	if e.Amount != 0 {
+		amount = epcCurrencyCode + formatEPCAmount(e.Amount)
+	}
+
+	lines := []string{
+		epcServiceTag, epcVersion, epcCharsetUTF8, epcIdentifier,
+		e.BIC, e.Name, NormalizeIBAN(e.IBAN), amount, e.Purpose, e.Reference, e.Text, e.Information,
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	s := strings.Join(lines, "\n")
+	if len(s) > epcMaxLen {
+		return "", WrongValueError
+	}
+
+	return s, nil
+}
+
+// String returns EPC payload or empty string if it's not valid
+// use Build to get the error
+func (e *EPCTransfer) String() string {
+	s, _ := e.Build()
+	return s
+}
+
+// Marshal marshals the transfer with ErrorCorrectionLevel required by the standard
+// throws VersionTooBigError if ver is greater than EPCMaxVersion
+// and TooLongError if the transfer doesn't fit into ver
+func (e *EPCTransfer) Marshal(ver qr_tools.QRVersion) ([]byte, error) {
+	if ver > EPCMaxVersion {
+		return nil, VersionTooBigError
+	}
+
+	return marshalLines(e, EPCLevel, ver)
+}
+
+// ParseEPC parses EPC payload
+func ParseEPC(str string) (*EPCTransfer, error) {
+	lines := strings.Split(strings.ReplaceAll(str, "\r\n", "\n"), "\n")
+	if len(lines) < 7 || lines[0] != epcServiceTag || lines[3] != epcIdentifier {
+		return nil, WrongFormatError
+	}
+	if lines[1] != "001" && lines[1] != epcVersion {
+		return nil, WrongFormatError
+	}
+	lines = append(lines, make([]string, 12)...)
+
+	e := &EPCTransfer{
+		BIC:         lines[4],
+		Name:        lines[5],
+		IBAN:        lines[6],
+		Purpose:     lines[8],
+		Reference:   lines[9],
+		Text:        lines[10],
+		Information: lines[11],
+	}
+	if lines[7] != "" {
+		amount, found := strings.CutPrefix(lines[7], epcCurrencyCode)
+		if !found {
+			return nil, WrongValueError
+		}
+
+		var err error
+		if e.Amount, err = parseCents(amount); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
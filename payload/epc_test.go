@@ -0,0 +1,86 @@
+package payload
+
+import (
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+func testEPCTransfer() EPCTransfer {
+	return EPCTransfer{
+		BIC:    "BFSWDE33BER",
+		Name:   "Wikimedia Foerdergesellschaft",
+		IBAN:   "DE33 1002 0500 0001 1947 00",
+		Amount: 12330,
+		Text:   "Spende fuer Wikipedia",
+	}
+}
+
+func TestEPCTransfer_Build(t *testing.T) {
+	e := testEPCTransfer()
+	s, err := e.Build()
+	if err != nil {
+		t.Fatalf("Failed to build payload: %v", err)
+	}
+
+	expected := "BCD\n002\n1\nSCT\nBFSWDE33BER\nWikimedia Foerdergesellschaft\nDE33100205000001194700\nEUR123.3\n\n\nSpende fuer Wikipedia"
+	if s != expected {
+		t.Errorf("Built %q instead of %q", s, expected)
+	}
+
+	for _, broken := range []func(e *EPCTransfer){
+		func(e *EPCTransfer) { e.IBAN = "DE33 1002 0500 0001 1947 01" },
+		func(e *EPCTransfer) { e.Name = "" },
+		func(e *EPCTransfer) { e.Amount = 1_000_000_000_00 },
+		func(e *EPCTransfer) { e.Reference = "RF18539007547034" },
+		func(e *EPCTransfer) { e.Text, e.Reference = "", "RF19539007547034" },
+		func(e *EPCTransfer) { e.BIC = "BFSW" },
+	} {
+		e := testEPCTransfer()
+		broken(&e)
+		if _, err := e.Build(); err == nil {
+			t.Errorf("Invalid transfer %v is built", e)
+		}
+	}
+}
+
+func TestEPCTransfer_Marshal(t *testing.T) {
+	e := testEPCTransfer()
+	if _, err := e.Marshal(EPCMaxVersion + 1); err != VersionTooBigError {
+		t.Errorf("Version greater than allowed doesn't give an error")
+	}
+	if _, err := e.Marshal(1); err != TooLongError {
+		t.Errorf("Transfer is marshaled into too small version with %v", err)
+	}
+
+	data, err := e.Marshal(7)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	expected, _ := qr_tools.NewQRMarshaler(qr_tools.M, 7).MarshalString(e.String())
+	if string(data) != string(expected) {
+		t.Errorf("Transfer isn't marshaled with level M")
+	}
+}
+
+func TestParseEPC(t *testing.T) {
+	for _, e := range []EPCTransfer{
+		testEPCTransfer(),
+		{Name: "Someone", IBAN: "DE89370400440532013000", Amount: 5, Purpose: "CHAR", Reference: "RF18539007547034", Information: "Thanks"},
+		{Name: "Someone", IBAN: "DE89370400440532013000"},
+	} {
+		e.IBAN = NormalizeIBAN(e.IBAN)
+		parsed, err := ParseEPC(e.String())
+		if err != nil {
+			t.Errorf("Failed to parse %q: %v", e.String(), err)
+		} else if *parsed != e {
+			t.Errorf("Parsed %v instead of %v", *parsed, e)
+		}
+	}
+
+	for _, s := range []string{"BCD\n003\n1\nSCT\n\nName\nDE89370400440532013000", "BCD\n002\n1\nSCT\n\nName\nDE89370400440532013000\nUSD1", "BCD\n002\n1\nSCT\n\nName"} {
+		if _, err := ParseEPC(s); err == nil {
+			t.Errorf("Wrong payload %q is parsed without error", s)
+		}
+	}
+}
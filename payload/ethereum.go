@@ -0,0 +1,133 @@
+package payload
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const ethereumScheme = "ethereum:"
+
+// EthereumParameter is a single parameter of EIP-681 URI
+type EthereumParameter struct {
+	Key   string
+	Value string
+}
+
+// EthereumPayment is the payload of EIP-681 ethereum: URI
+type EthereumPayment struct {
+	// Address is 0x prefixed hex address or ENS name
+	Address string
+	// ChainID is omitted when 0
+	ChainID uint64
+	// Function is the contract function to call, e.g. transfer
+	Function string
+	// Parameters are written in the given order, e.g. value, gas or function arguments
+	Parameters []EthereumParameter
+}
+
+// isEthereumAddress tells if str is 0x followed by 40 hex digits
+// EIP-55 checksum of mixed case addresses isn't checked, since it needs Keccak-256
+func isEthereumAddress(str string) bool {
+	if len(str) != 42 || str[0] != '0' || (str[1] != 'x' && str[1] != 'X') {
+		return false
+	}
+
+	for i := 2; i < len(str); i++ {
+		ch := str[i]
+		if !(ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'f' || ch >= 'A' && ch <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *EthereumPayment) validate() error {
+	if !isEthereumAddress(e.Address) && !strings.Contains(e.Address, ".") {
+		return WrongValueError
+	}
+	if strings.ContainsAny(e.Address, "@/?&= ") || strings.ContainsAny(e.Function, "@/?&= ") {
+		return WrongValueError
+	}
+
+	for _, param := range e.Parameters {
+		if param.Key == "" {
+			return WrongValueError
+		}
+	}
+
+	return nil
+}
+
+// Build returns ethereum: URI
+func (e *EthereumPayment) Build() (string, error) {
+	if err := e.validate(); err != nil {
+		return "", err
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(ethereumScheme + e.Address)
+	if e.ChainID != 0 {
+		sb.WriteString("@" + strconv.FormatUint(e.ChainID, 10))
+	}
+	if e.Function != "" {
+		sb.WriteString("/" + e.Function)
+	}
+
+	sep := "?"
+	for _, param := range e.Parameters {
+		sb.WriteString(sep + escapeQuery(param.Key) + "=" + escapeQuery(param.Value))
+		sep = "&"
+	}
+
+	return sb.String(), nil
+}
+
+// String returns ethereum: URI or empty string if it's not valid
+// use Build to get the error
+func (e *EthereumPayment) String() string {
+	s, _ := e.Build()
+	return s
+}
+
+// ParseEthereum parses EIP-681 ethereum: URI
+// pay- prefix of the address is dropped
+func ParseEthereum(str string) (*EthereumPayment, error) {
+	body, ok := cutScheme(str, ethereumScheme)
+	if !ok {
+		return nil, WrongFormatError
+	}
+
+	target, query, _ := strings.Cut(body, "?")
+	target, function, _ := strings.Cut(target, "/")
+	addr, chain, hasChain := strings.Cut(target, "@")
+
+	e := &EthereumPayment{Address: strings.TrimPrefix(addr, "pay-"), Function: function}
+	if hasChain {
+		var err error
+		if e.ChainID, err = strconv.ParseUint(chain, 10, 64); err != nil {
+			return nil, WrongValueError
+		}
+	}
+
+	// url.ParseQuery loses the order of parameters, so they are split by hand
+	if query != "" {
+		for _, pair := range strings.Split(query, "&") {
+			key, value, _ := strings.Cut(pair, "=")
+			key, err := url.QueryUnescape(key)
+			if err != nil {
+				return nil, WrongFormatError
+			}
+			if value, err = url.QueryUnescape(value); err != nil {
+				return nil, WrongFormatError
+			}
+			e.Parameters = append(e.Parameters, EthereumParameter{Key: key, Value: value})
+		}
+	}
+
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
@@ -0,0 +1,68 @@
+package payload
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testEthereumAddress = "0xfb6916095ca1df60bb79Ce92ce3ea74c37c5d359"
+
+func TestEthereumPayment_Build(t *testing.T) {
+	cases := []struct {
+		e        EthereumPayment
+		expected string
+	}{
+		{EthereumPayment{Address: testEthereumAddress, Parameters: []EthereumParameter{{"value", "2.014e18"}}},
+			"ethereum:0xfb6916095ca1df60bb79Ce92ce3ea74c37c5d359?value=2.014e18"},
+		{EthereumPayment{Address: "0x89205a3a3b2a69de6dbf7f01ed13b2108b2c43e7", ChainID: 1, Function: "transfer",
+			Parameters: []EthereumParameter{{"address", testEthereumAddress}, {"uint256", "1"}}},
+			"ethereum:0x89205a3a3b2a69de6dbf7f01ed13b2108b2c43e7@1/transfer?address=0xfb6916095ca1df60bb79Ce92ce3ea74c37c5d359&uint256=1"},
+		{EthereumPayment{Address: "vitalik.eth"}, "ethereum:vitalik.eth"},
+	}
+
+	for _, c := range cases {
+		s, err := c.e.Build()
+		if err != nil {
+			t.Errorf("Failed to build %v: %v", c.e, err)
+		} else if s != c.expected {
+			t.Errorf("Built %s instead of %s", s, c.expected)
+		}
+	}
+
+	for _, e := range []EthereumPayment{
+		{Address: "0xfb6916095ca1df60bb79Ce92ce3ea74c37c5d35"},
+		{Address: "0xfb6916095ca1df60bb79Ce92ce3ea74c37c5d35g"},
+		{Address: testEthereumAddress, Function: "a/b"},
+		{Address: testEthereumAddress, Parameters: []EthereumParameter{{"", "1"}}},
+	} {
+		if _, err := e.Build(); err == nil {
+			t.Errorf("Invalid payment %v is built without error", e)
+		}
+	}
+}
+
+func TestParseEthereum(t *testing.T) {
+	for _, e := range []EthereumPayment{
+		{Address: testEthereumAddress},
+		{Address: testEthereumAddress, ChainID: 137, Function: "transfer",
+			Parameters: []EthereumParameter{{"uint256", "1"}, {"address", "vitalik.eth"}, {"gasLimit", "21000"}}},
+	} {
+		parsed, err := ParseEthereum(e.String())
+		if err != nil {
+			t.Errorf("Failed to parse %s: %v", e.String(), err)
+		} else if !reflect.DeepEqual(*parsed, e) {
+			t.Errorf("Parsed %v instead of %v", *parsed, e)
+		}
+	}
+
+	parsed, err := ParseEthereum("ethereum:pay-" + testEthereumAddress + "@1?value=1e18")
+	if err != nil || parsed.Address != testEthereumAddress || parsed.ChainID != 1 || parsed.Parameters[0].Value != "1e18" {
+		t.Errorf("Payment with pay- prefix is parsed wrong: %v, %v", parsed, err)
+	}
+
+	for _, s := range []string{"bitcoin:" + testEthereumAddress, "ethereum:0x12", "ethereum:" + testEthereumAddress + "@one"} {
+		if _, err := ParseEthereum(s); err == nil {
+			t.Errorf("Wrong payload %s is parsed without error", s)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package payload
+
+import (
+	"strings"
+)
+
+// NormalizeIBAN removes spaces from IBAN and uppercases it
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.ReplaceAll(iban, " ", ""))
+}
+
+// mod97 calculates ISO 7064 MOD 97-10 remainder of str
+// letters are turned into numbers (A = 10, ..., Z = 35), -1 is returned for other characters
+func mod97(str string) int {
+	rem := 0
+	for i := 0; i < len(str); i++ {
+		ch := str[i]
+		switch {
+		case ch >= '0' && ch <= '9':
+			rem = (rem*10 + int(ch-'0')) % 97
+		case ch >= 'A' && ch <= 'Z':
+			rem = (rem*100 + int(ch-'A') + 10) % 97
+		default:
+			return -1
+		}
+	}
+
+	return rem
+}
+
+// ValidateIBAN checks length, country code and check digits of normalized IBAN
+func ValidateIBAN(iban string) error {
+	if len(iban) < 15 || len(iban) > 34 {
+		return WrongValueError
+	}
+	if iban[0] < 'A' || iban[0] > 'Z' || iban[1] < 'A' || iban[1] > 'Z' || !isDigits(iban[2:4]) {
+		return WrongValueError
+	}
+
+	// country code and check digits go to the end
+	if mod97(iban[4:]+iban[:4]) != 1 {
+		return WrongValueError
+	}
+
+	return nil
+}
+
+// ValidateCreditorReference checks ISO 11649 creditor reference (RF followed by check digits)
+func ValidateCreditorReference(ref string) error {
+	if len(ref) < 5 || len(ref) > 25 || ref[:2] != "RF" || !isDigits(ref[2:4]) {
+		return WrongValueError
+	}
+
+	if mod97(ref[4:]+ref[:4]) != 1 {
+		return WrongValueError
+	}
+
+	return nil
+}
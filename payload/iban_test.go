@@ -0,0 +1,31 @@
+package payload
+
+import (
+	"testing"
+)
+
+func TestValidateIBAN(t *testing.T) {
+	for _, iban := range []string{"DE89 3704 0044 0532 0130 00", "CH93 0076 2011 6238 5295 7", "CH44 3199 9123 0008 8901 2", "gb82 west 1234 5698 7654 32"} {
+		if err := ValidateIBAN(NormalizeIBAN(iban)); err != nil {
+			t.Errorf("Valid IBAN %s is not recognized as one", iban)
+		}
+	}
+
+	for _, iban := range []string{"DE89 3704 0044 0532 0130 01", "DE8", "1289 3704 0044 0532 0130 00", "DE89-3704-0044-0532-0130-00"} {
+		if err := ValidateIBAN(NormalizeIBAN(iban)); err == nil {
+			t.Errorf("Invalid IBAN %s is recognized as valid", iban)
+		}
+	}
+}
+
+func TestValidateCreditorReference(t *testing.T) {
+	if err := ValidateCreditorReference("RF18539007547034"); err != nil {
+		t.Errorf("Valid creditor reference is not recognized as one")
+	}
+
+	for _, ref := range []string{"RF19539007547034", "RF18", "XX18539007547034", "RF18539007547034539007547034"} {
+		if err := ValidateCreditorReference(ref); err == nil {
+			t.Errorf("Invalid creditor reference %s is recognized as valid", ref)
+		}
+	}
+}
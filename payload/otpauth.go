@@ -0,0 +1,203 @@
+package payload
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strconv"
+	"strings"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+// OTPType is enum that
+// shows what kind of one-time passwords is used
+type OTPType string
+
+const (
+	TOTP OTPType = "totp"
+	HOTP OTPType = "hotp"
+)
+
+// OTPAlgorithm is enum that
+// shows what hash function is used to generate one-time passwords
+type OTPAlgorithm string
+
+const (
+	SHA1   OTPAlgorithm = "SHA1"
+	SHA256 OTPAlgorithm = "SHA256"
+	SHA512 OTPAlgorithm = "SHA512"
+)
+
+// default values that are omitted from the URI
+const (
+	otpDefaultAlgorithm = SHA1
+	otpDefaultDigits    = 6
+	otpDefaultPeriod    = 30
+)
+
+var otpBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// OTPAuth is the payload with TOTP or HOTP secret for authenticator apps
+type OTPAuth struct {
+	Type    OTPType
+	Issuer  string
+	Account string
+	Secret  []byte
+	// Algorithm is SHA1 when empty
+	Algorithm OTPAlgorithm
+	// Digits is 6 when 0
+	Digits int
+	// Period is 30 seconds when 0, used only with TOTP
+	Period int
+	// Counter is the initial counter value, used only with HOTP
+	Counter uint64
+}
+
+// escapeLabel escapes issuer or account name of the label
+// colon is escaped too, since it separates them
+func escapeLabel(str string) string {
+	return strings.ReplaceAll(url.PathEscape(str), ":", "%3A")
+}
+
+func (o *OTPAuth) validate() error {
+	switch {
+	case o.Type != TOTP && o.Type != HOTP:
+		return WrongValueError
+	case o.Account == "" || len(o.Secret) == 0:
+		return WrongValueError
+	case o.Algorithm != "" && o.Algorithm != SHA1 && o.Algorithm != SHA256 && o.Algorithm != SHA512:
+		return WrongValueError
+	case o.Digits != 0 && (o.Digits < 6 || o.Digits > 8):
+		return WrongValueError
+	case o.Period < 0:
+		return WrongValueError
+	}
+
+	return nil
+}
+
+// prefix returns the whole URI except the secret parameter value
+// secret goes last, so that it can be marshaled separately
+func (o *OTPAuth) prefix() string {
+	sb := strings.Builder{}
+	sb.WriteString("otpauth://" + string(o.Type) + "/")
+	if o.Issuer != "" {
+		sb.WriteString(escapeLabel(o.Issuer) + ":")
+	}
+	sb.WriteString(escapeLabel(o.Account) + "?")
+
+	if o.Issuer != "" {
+		sb.WriteString("issuer=" + url.QueryEscape(o.Issuer) + "&")
+	}
+	if o.Algorithm != "" && o.Algorithm != otpDefaultAlgorithm {
+		sb.WriteString("algorithm=" + string(o.Algorithm) + "&")
+	}
+	if o.Digits != 0 && o.Digits != otpDefaultDigits {
+		sb.WriteString("digits=" + strconv.Itoa(o.Digits) + "&")
+	}
+	if o.Type == TOTP && o.Period != 0 && o.Period != otpDefaultPeriod {
+		sb.WriteString("period=" + strconv.Itoa(o.Period) + "&")
+	}
+	if o.Type == HOTP {
+		sb.WriteString("counter=" + strconv.FormatUint(o.Counter, 10) + "&")
+	}
+	sb.WriteString("secret=")
+
+	return sb.String()
+}
+
+// Build returns otpauth:// URI
+// parameters with default values are omitted
+func (o *OTPAuth) Build() (string, error) {
+	if err := o.validate(); err != nil {
+		return "", err
+	}
+
+	return o.prefix() + otpBase32.EncodeToString(o.Secret), nil
+}
+
+// String returns otpauth:// URI or empty string if it's not valid
+// use Build to get the error
+func (o *OTPAuth) String() string {
+	s, _ := o.Build()
+	return s
+}
+
+// Segments returns URI split into byte segment and alphanumeric segment with Base32 secret
+// it's cheaper than putting the whole URI into byte mode
+func (o *OTPAuth) Segments() ([]qr_tools.Segment, error) {
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	secret, err := qr_tools.NewAlphanumericSegment(otpBase32.EncodeToString(o.Secret))
+	if err != nil {
+		return nil, err
+	}
+
+	return []qr_tools.Segment{qr_tools.NewByteSegment([]byte(o.prefix())), secret}, nil
+}
+
+// Marshal marshals URI into the smallest QR version
+// with the highest ErrorCorrectionLevel that still fits into it
+func (o *OTPAuth) Marshal() ([]byte, qr_tools.ErrorCorrectionLevel, qr_tools.QRVersion, error) {
+	segments, err := o.Segments()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return marshalSegments(segments)
+}
+
+// ParseOTPAuth parses otpauth:// URI
+func ParseOTPAuth(str string) (*OTPAuth, error) {
+	u, err := url.Parse(str)
+	if err != nil || !strings.EqualFold(u.Scheme, "otpauth") {
+		return nil, WrongFormatError
+	}
+
+	o := &OTPAuth{Type: OTPType(strings.ToLower(u.Host))}
+
+	// label is split before unescaping, since escaped colon can be inside issuer or account
+	label := strings.TrimPrefix(u.EscapedPath(), "/")
+	issuer, account, found := strings.Cut(label, ":")
+	if !found {
+		issuer, account = "", label
+	}
+	if o.Issuer, err = url.PathUnescape(issuer); err != nil {
+		return nil, WrongFormatError
+	}
+	if o.Account, err = url.PathUnescape(account); err != nil {
+		return nil, WrongFormatError
+	}
+	o.Account = strings.TrimLeft(o.Account, " ")
+
+	query := u.Query()
+	// issuer parameter is preferred over the label prefix
+	if issuer := query.Get("issuer"); issuer != "" {
+		o.Issuer = issuer
+	}
+
+	if o.Secret, err = otpBase32.DecodeString(strings.ToUpper(strings.TrimRight(query.Get("secret"), "="))); err != nil {
+		return nil, WrongValueError
+	}
+	o.Algorithm = OTPAlgorithm(strings.ToUpper(query.Get("algorithm")))
+	for key, field := range map[string]*int{"digits": &o.Digits, "period": &o.Period} {
+		if v := query.Get(key); v != "" {
+			if *field, err = strconv.Atoi(v); err != nil {
+				return nil, WrongValueError
+			}
+		}
+	}
+	if v := query.Get("counter"); v != "" {
+		if o.Counter, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return nil, WrongValueError
+		}
+	}
+
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
@@ -0,0 +1,101 @@
+package payload
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+// 160-bit secret "Hello!\xde\xad\xbe\xef" repeated twice
+var testSecret = []byte("Hello!\xde\xad\xbe\xefHello!\xde\xad\xbe\xef")
+
+func TestOTPAuth_Build(t *testing.T) {
+	cases := []struct {
+		o        OTPAuth
+		expected string
+	}{
+		{OTPAuth{Type: TOTP, Issuer: "ACME Co", Account: "john@example.com", Secret: testSecret},
+			"otpauth://totp/ACME%20Co:john@example.com?issuer=ACME+Co&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"},
+		{OTPAuth{Type: HOTP, Account: "a:b", Secret: []byte{1}, Algorithm: SHA256, Digits: 8, Counter: 5},
+			"otpauth://hotp/a%3Ab?algorithm=SHA256&digits=8&counter=5&secret=AE"},
+		{OTPAuth{Type: TOTP, Account: "x", Secret: []byte{1}, Algorithm: SHA1, Digits: 6, Period: 60},
+			"otpauth://totp/x?period=60&secret=AE"},
+	}
+
+	for _, c := range cases {
+		s, err := c.o.Build()
+		if err != nil {
+			t.Errorf("Failed to build %v: %v", c.o, err)
+		} else if s != c.expected {
+			t.Errorf("Built %s instead of %s", s, c.expected)
+		}
+	}
+
+	for _, o := range []OTPAuth{
+		{Type: "motp", Account: "x", Secret: []byte{1}},
+		{Type: TOTP, Secret: []byte{1}},
+		{Type: TOTP, Account: "x"},
+		{Type: TOTP, Account: "x", Secret: []byte{1}, Digits: 4},
+		{Type: TOTP, Account: "x", Secret: []byte{1}, Algorithm: "MD5"},
+	} {
+		if _, err := o.Build(); err == nil {
+			t.Errorf("Invalid payload %v is built", o)
+		}
+	}
+}
+
+func TestOTPAuth_Marshal(t *testing.T) {
+	o := OTPAuth{Type: TOTP, Issuer: "ACME Co", Account: "john@example.com", Secret: testSecret}
+
+	data, lvl, ver, err := o.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	segments, _ := o.Segments()
+	mixed, _ := qr_tools.SegmentsBitLength(segments, ver)
+	single, _ := qr_tools.NewByteSegment([]byte(o.String())).BitLength(ver)
+	if mixed >= single {
+		t.Errorf("Segments take %d bits, but the whole URI in byte mode takes %d", mixed, single)
+	}
+
+	if smallest, _ := qr_tools.SmallestVersion(segments, qr_tools.L); smallest != ver {
+		t.Errorf("Version %d is chosen instead of %d", ver, smallest)
+	}
+	if best, _ := qr_tools.BestLevel(segments, ver); best != lvl {
+		t.Errorf("Level %d is chosen instead of %d", lvl, best)
+	}
+
+	unmarshaled, err := qr_tools.NewQRUnmarshaler(ver).UnmarshalToString(data)
+	if err != nil || unmarshaled != o.String() {
+		t.Errorf("Unmarshaled %q instead of %q: %v", unmarshaled, o.String(), err)
+	}
+}
+
+func TestParseOTPAuth(t *testing.T) {
+	for _, o := range []OTPAuth{
+		{Type: TOTP, Issuer: "ACME:Co", Account: "john@example.com", Secret: testSecret},
+		{Type: HOTP, Account: "a b", Secret: []byte{1, 2, 3}, Algorithm: SHA512, Digits: 8, Counter: 5},
+		{Type: TOTP, Account: "x", Secret: []byte{1}, Period: 60},
+	} {
+		parsed, err := ParseOTPAuth(o.String())
+		if err != nil {
+			t.Errorf("Failed to parse %s: %v", o.String(), err)
+		} else if !reflect.DeepEqual(*parsed, o) {
+			t.Errorf("Parsed %v instead of %v", *parsed, o)
+		}
+	}
+
+	parsed, err := ParseOTPAuth("otpauth://totp/Example:alice@google.com?secret=jbswy3dpehpk3pxp&issuer=Example")
+	if err != nil || parsed.Issuer != "Example" || parsed.Account != "alice@google.com" || !bytes.Equal(parsed.Secret, []byte("Hello!\xde\xad\xbe\xef")) {
+		t.Errorf("Lowercase secret is parsed wrong: %v, %v", parsed, err)
+	}
+
+	for _, s := range []string{"https://totp/x?secret=AE", "otpauth://totp/x?secret=1", "otpauth://totp/x?secret=AE&digits=six"} {
+		if _, err := ParseOTPAuth(s); err == nil {
+			t.Errorf("Wrong payload %s is parsed without error", s)
+		}
+	}
+}
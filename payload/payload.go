@@ -0,0 +1,184 @@
+// Package payload builds and parses structured contents of QR codes
+// (Wi-Fi credentials, contacts, locations, messages, etc.)
+//
+// every builder produces the string that is the cheapest to marshal,
+// so that the smallest QR code can be used
+package payload
+
+import (
+	"errors"
+	"strings"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+var (
+	WrongFormatError = errors.New("content format doesn't match payload")
+	WrongValueError  = errors.New("wrong value of payload field")
+)
+
+// Payload is the interface implemented by all the payload builders
+type Payload interface {
+	String() string
+}
+
+// builder is implemented by payloads that can be invalid
+type builder interface {
+	Build() (string, error)
+}
+
+// Marshal marshals the payload with QRMarshaler, so the cheapest mode is chosen
+// payloads that can be invalid are built (and validated) only once
+func Marshal(p Payload, lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) ([]byte, error) {
+	var str string
+	if b, ok := p.(builder); ok {
+		var err error
+		if str, err = b.Build(); err != nil {
+			return nil, err
+		}
+	} else {
+		str = p.String()
+	}
+
+	return qr_tools.NewQRMarshaler(lvl, ver).MarshalString(str)
+}
+
+// marshalLines builds the payload of several lines, checks that it fits into chosen version and marshals it,
+// line breaks make QRMarshaler choose byte mode, so the payload is checked as a single byte segment
+// throws TooLongError if it doesn't fit
+func marshalLines(b builder, lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) ([]byte, error) {
+	str, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	smallest, err := qr_tools.SmallestVersion([]qr_tools.Segment{qr_tools.NewByteSegment([]byte(str))}, lvl)
+	if err != nil || smallest > ver {
+		return nil, TooLongError
+	}
+
+	return qr_tools.NewByteMarshaler(lvl, ver).MarshalString(str)
+}
+
+// marshalSegments marshals segments into the smallest QR version
+// with the highest ErrorCorrectionLevel that still fits into it
+func marshalSegments(segments []qr_tools.Segment) ([]byte, qr_tools.ErrorCorrectionLevel, qr_tools.QRVersion, error) {
+	ver, err := qr_tools.SmallestVersion(segments, qr_tools.L)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	lvl, err := qr_tools.BestLevel(segments, ver)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	data, err := qr_tools.EncodeSegments(segments, lvl, ver)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return data, lvl, ver, nil
+}
+
+// cheapestSegment returns str as numeric segment, alphanumeric one if it isn't numeric,
+// or byte one if it's neither
+func cheapestSegment(str string) qr_tools.Segment {
+	if segment, err := qr_tools.NewNumericSegment(str); err == nil {
+		return segment
+	}
+	if segment, err := qr_tools.NewAlphanumericSegment(str); err == nil {
+		return segment
+	}
+	return qr_tools.NewByteSegment([]byte(str))
+}
+
+func isDigits(str string) bool {
+	for i := 0; i < len(str); i++ {
+		if str[i] < '0' || str[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// escape puts backslash before all the special characters and backslash itself
+func escape(str, special string) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(str); i++ {
+		if str[i] == '\\' || strings.IndexByte(special, str[i]) != -1 {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(str[i])
+	}
+
+	return sb.String()
+}
+
+// splitEscaped splits str by sep that isn't escaped with backslash
+// escaping is kept as it is
+func splitEscaped(str string, sep byte) []string {
+	parts := make([]string, 0)
+
+	start := 0
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, str[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, str[start:])
+}
+
+// unescape removes escaping backslashes
+func unescape(str string) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(str); i++ {
+		if str[i] == '\\' && i+1 < len(str) {
+			i++
+		}
+		sb.WriteByte(str[i])
+	}
+
+	return sb.String()
+}
+
+// field is a KEY:value pair used by MECARD: and WIFI: payloads
+type field struct {
+	key, value string
+}
+
+// parseFields parses fields of the form KEY:value;KEY:value;; after the scheme
+// values are left escaped, because some of them consist of several components
+func parseFields(body string) ([]field, error) {
+	if !strings.HasSuffix(body, ";") {
+		return nil, WrongFormatError
+	}
+
+	fields := make([]field, 0)
+	for _, part := range splitEscaped(body, ';') {
+		if part == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(part, ":")
+		if !found {
+			return nil, WrongFormatError
+		}
+		fields = append(fields, field{key: strings.ToUpper(key), value: value})
+	}
+
+	return fields, nil
+}
+
+// cutScheme cuts case-insensitive scheme from the start of str
+func cutScheme(str, scheme string) (string, bool) {
+	if len(str) < len(scheme) || !strings.EqualFold(str[:len(scheme)], scheme) {
+		return "", false
+	}
+
+	return str[len(scheme):], true
+}
@@ -0,0 +1,32 @@
+package payload
+
+import (
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+// countingPayload counts how many times it's built
+type countingPayload struct {
+	builds int
+}
+
+func (c *countingPayload) Build() (string, error) {
+	c.builds++
+	return "HELLO", nil
+}
+
+func (c *countingPayload) String() string {
+	s, _ := c.Build()
+	return s
+}
+
+func TestMarshal_BuildsOnce(t *testing.T) {
+	c := &countingPayload{}
+	if _, err := Marshal(c, qr_tools.M, 1); err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if c.builds != 1 {
+		t.Errorf("Payload is built %d times instead of 1", c.builds)
+	}
+}
@@ -0,0 +1,296 @@
+package payload
+
+import (
+	"strconv"
+	"strings"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+const (
+	// SwissMaxVersion is the greatest QR version allowed by Swiss QR-bill
+	SwissMaxVersion = 25
+	// SwissLevel is the ErrorCorrectionLevel required by Swiss QR-bill
+	SwissLevel = qr_tools.M
+
+	swissMaxLen     = 997
+	swissMaxAmount  = 999999999_99
+	swissQRType     = "SPC"
+	swissVersion    = "0200"
+	swissCoding     = "1"
+	swissTrailer    = "EPD"
+	swissLinesCount = 31
+)
+
+// SwissAddressType is enum that
+// shows how the address of Swiss QR-bill is written
+type SwissAddressType string
+
+const (
+	// StructuredAddress has street, building number, postal code and town separately
+	StructuredAddress SwissAddressType = "S"
+	// CombinedAddress has two address lines, the second one with postal code and town
+	CombinedAddress SwissAddressType = "K"
+)
+
+// SwissAddress is the address of creditor or debtor of Swiss QR-bill
+type SwissAddress struct {
+	Type SwissAddressType
+	Name string
+	// Line1 is the street for StructuredAddress
+	Line1 string
+	// Line2 is the building number for StructuredAddress
+	Line2 string
+	// PostalCode and Town are used only with StructuredAddress
+	PostalCode string
+	Town       string
+	// Country is two letter ISO 3166 code
+	Country string
+}
+
+func (a SwissAddress) isEmpty() bool {
+	return a == SwissAddress{}
+}
+
+func (a SwissAddress) lines() []string {
+	if a.isEmpty() {
+		return make([]string, 7)
+	}
+	return []string{string(a.Type), a.Name, a.Line1, a.Line2, a.PostalCode, a.Town, a.Country}
+}
+
+func newSwissAddress(lines []string) SwissAddress {
+	return SwissAddress{
+		Type:       SwissAddressType(lines[0]),
+		Name:       lines[1],
+		Line1:      lines[2],
+		Line2:      lines[3],
+		PostalCode: lines[4],
+		Town:       lines[5],
+		Country:    lines[6],
+	}
+}
+
+// validate checks the field limits of the address
+func (a SwissAddress) validate() error {
+	if a.Name == "" || len(a.Name) > 70 || len(a.Line1) > 70 || len(a.Country) != 2 {
+		return WrongValueError
+	}
+
+	switch a.Type {
+	case StructuredAddress:
+		if len(a.Line2) > 16 || a.PostalCode == "" || len(a.PostalCode) > 16 || a.Town == "" || len(a.Town) > 35 {
+			return WrongValueError
+		}
+	case CombinedAddress:
+		if a.Line2 == "" || len(a.Line2) > 70 || a.PostalCode != "" || a.Town != "" {
+			return WrongValueError
+		}
+	default:
+		return WrongValueError
+	}
+
+	return nil
+}
+
+// SwissReferenceType is enum that
+// shows what payment reference Swiss QR-bill has
+type SwissReferenceType string
+
+const (
+	// QRReference is 27 digit reference, it's used only with QR-IBAN
+	QRReference SwissReferenceType = "QRR"
+	// CreditorReference is ISO 11649 reference, it can't be used with QR-IBAN
+	CreditorReference SwissReferenceType = "SCOR"
+	NoReference       SwissReferenceType = "NON"
+)
+
+// SwissBill is the payload of Swiss QR-bill ("SPC" code)
+type SwissBill struct {
+	IBAN     string
+	Creditor SwissAddress
+	// Amount is in cents (or rappen), omitted when 0
+	Amount int64
+	// Currency is either CHF or EUR
+	Currency string
+	// Debtor is omitted when empty
+	Debtor          SwissAddress
+	ReferenceType   SwissReferenceType
+	Reference       string
+	Message         string
+	BillInformation string
+	// AlternativeSchemes are up to two parameters of other payment schemes
+	AlternativeSchemes []string
+}
+
+// IsQRIBAN tells if normalized IBAN is Swiss QR-IBAN (its institution id is from 30000 to 31999)
+func IsQRIBAN(iban string) bool {
+	if len(iban) < 9 || (iban[:2] != "CH" && iban[:2] != "LI") {
+		return false
+	}
+
+	iid, err := strconv.Atoi(iban[4:9])
+	return err == nil && iid >= 30000 && iid <= 31999
+}
+
+// QRReferenceCheckDigit calculates recursive mod 10 check digit of QR reference
+// digits should be given without the check digit itself
+func QRReferenceCheckDigit(digits string) byte {
+	table := [10]int{0, 9, 4, 6, 8, 2, 7, 1, 3, 5}
+
+	carry := 0
+	for i := 0; i < len(digits); i++ {
+		carry = table[(carry+int(digits[i]-'0'))%10]
+	}
+
+	return byte('0' + (10-carry)%10)
+}
+
+// validate checks the field limits and reference of Swiss QR-bill
+func (b *SwissBill) validate() error {
+	iban := NormalizeIBAN(b.IBAN)
+	if err := ValidateIBAN(iban); err != nil {
+		return err
+	}
+	if iban[:2] != "CH" && iban[:2] != "LI" {
+		return WrongValueError
+	}
+
+	if err := b.Creditor.validate(); err != nil {
+		return err
+	}
+	if !b.Debtor.isEmpty() {
+		if err := b.Debtor.validate(); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case b.Amount < 0 || b.Amount > swissMaxAmount:
+		return WrongValueError
+	case b.Currency != "CHF" && b.Currency != "EUR":
+		return WrongValueError
+	case len(b.Message)+len(b.BillInformation) > 140:
+		return WrongValueError
+	case len(b.AlternativeSchemes) > 2:
+		return WrongValueError
+	}
+	for _, scheme := range b.AlternativeSchemes {
+		if len(scheme) > 100 {
+			return WrongValueError
+		}
+	}
+
+	// reference type depends on the kind of IBAN
+	switch b.ReferenceType {
+	case QRReference:
+		if !IsQRIBAN(iban) || len(b.Reference) != 27 || !isDigits(b.Reference) ||
+			QRReferenceCheckDigit(b.Reference[:26]) != b.Reference[26] {
+			return WrongValueError
+		}
+	case CreditorReference:
+		if IsQRIBAN(iban) {
+			return WrongValueError
+		}
+		return ValidateCreditorReference(b.Reference)
+	case NoReference:
+		if IsQRIBAN(iban) || b.Reference != "" {
+			return WrongValueError
+		}
+	default:
+		return WrongValueError
+	}
+
+	return nil
+}
+
+// Build returns Swiss QR-bill payload
+func (b *SwissBill) Build() (string, error) {
+	if err := b.validate(); err != nil {
+		return "", err
+	}
+
+	amount := ""
+	if b.Amount != 0 {
+		amount = strconv.FormatInt(b.Amount/100, 10) + "." + strconv.FormatInt(100+b.Amount%100, 10)[1:]
+	}
+
+	lines := []string{swissQRType, swissVersion, swissCoding, NormalizeIBAN(b.IBAN)}
+	lines = append(lines, b.Creditor.lines()...)
+	// ultimate creditor is reserved for future use
+	lines = append(lines, make([]string, 7)...)
+	lines = append(lines, amount, b.Currency)
+	lines = append(lines, b.Debtor.lines()...)
+	lines = append(lines, string(b.ReferenceType), b.Reference, b.Message, swissTrailer)
+	if b.BillInformation != "" || len(b.AlternativeSchemes) != 0 {
+		lines = append(lines, b.BillInformation)
+		lines = append(lines, b.AlternativeSchemes...)
+	}
+
+	s := strings.Join(lines, "\n")
+	if len(s) > swissMaxLen {
+		return "", WrongValueError
+	}
+
+	return s, nil
+}
+
+// String returns Swiss QR-bill payload or empty string if it's not valid
+// use Build to get the error
+func (b *SwissBill) String() string {
+	s, _ := b.Build()
+	return s
+}
+
+// Marshal marshals the bill with ErrorCorrectionLevel required by the standard
+// throws VersionTooBigError if ver is greater than SwissMaxVersion
+// and TooLongError if the bill doesn't fit into ver
+//
+// the Swiss cross the standard puts over the code is drawn by render with Options.SwissCross
+func (b *SwissBill) Marshal(ver qr_tools.QRVersion) ([]byte, error) {
+	if ver > SwissMaxVersion {
+		return nil, VersionTooBigError
+	}
+
+	return marshalLines(b, SwissLevel, ver)
+}
+
+// ParseSwissBill parses Swiss QR-bill payload
+func ParseSwissBill(str string) (*SwissBill, error) {
+	lines := strings.Split(strings.ReplaceAll(str, "\r\n", "\n"), "\n")
+	if len(lines) < swissLinesCount || len(lines) > swissLinesCount+3 {
+		return nil, WrongFormatError
+	}
+	if lines[0] != swissQRType || lines[1] != swissVersion || lines[2] != swissCoding || lines[30] != swissTrailer {
+		return nil, WrongFormatError
+	}
+
+	b := &SwissBill{
+		IBAN:          lines[3],
+		Creditor:      newSwissAddress(lines[4:11]),
+		Currency:      lines[19],
+		Debtor:        newSwissAddress(lines[20:27]),
+		ReferenceType: SwissReferenceType(lines[27]),
+		Reference:     lines[28],
+		Message:       lines[29],
+	}
+	if len(lines) > swissLinesCount {
+		b.BillInformation = lines[31]
+	}
+	if len(lines) > swissLinesCount+1 {
+		b.AlternativeSchemes = lines[32:]
+	}
+
+	if lines[18] != "" {
+		var err error
+		if b.Amount, err = parseCents(lines[18]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
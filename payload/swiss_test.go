@@ -0,0 +1,106 @@
+package payload
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testSwissBill() SwissBill {
+	return SwissBill{
+		IBAN: "CH44 3199 9123 0008 8901 2",
+		Creditor: SwissAddress{Type: StructuredAddress, Name: "Robert Schneider AG", Line1: "Rue du Lac", Line2: "1268",
+			PostalCode: "2501", Town: "Biel", Country: "CH"},
+		Amount:   194975,
+		Currency: "CHF",
+		Debtor: SwissAddress{Type: CombinedAddress, Name: "Pia-Maria Rutschmann-Schnyder", Line1: "Grosse Marktgasse 28",
+			Line2: "9400 Rorschach", Country: "CH"},
+		ReferenceType:   QRReference,
+		Reference:       "210000000003139471430009017",
+		Message:         "Order of 15 June 2020",
+		BillInformation: "//S1/10/10201409/11/200701/20/140.000-53",
+	}
+}
+
+func TestQRReferenceCheckDigit(t *testing.T) {
+	if d := QRReferenceCheckDigit("21000000000313947143000901"); d != '7' {
+		t.Errorf("Check digit is %c instead of 7", d)
+	}
+}
+
+func TestIsQRIBAN(t *testing.T) {
+	if !IsQRIBAN("CH4431999123000889012") {
+		t.Errorf("QR-IBAN is not recognized as one")
+	}
+	if IsQRIBAN("CH9300762011623852957") || IsQRIBAN("DE89370400440532013000") {
+		t.Errorf("Regular IBAN is recognized as QR-IBAN")
+	}
+}
+
+func TestSwissBill_Build(t *testing.T) {
+	b := testSwissBill()
+	s, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to build payload: %v", err)
+	}
+
+	lines := strings.Split(s, "\n")
+	if len(lines) != 32 || lines[0] != "SPC" || lines[3] != "CH4431999123000889012" || lines[18] != "1949.75" || lines[30] != "EPD" {
+		t.Errorf("Payload is built wrong: %q", s)
+	}
+
+	for _, broken := range []func(b *SwissBill){
+		func(b *SwissBill) { b.IBAN = "DE89370400440532013000" },
+		func(b *SwissBill) { b.Reference = "210000000003139471430009018" },
+		func(b *SwissBill) { b.ReferenceType, b.Reference = CreditorReference, "RF18539007547034" },
+		func(b *SwissBill) { b.ReferenceType, b.Reference = NoReference, "" },
+		func(b *SwissBill) { b.Currency = "USD" },
+		func(b *SwissBill) { b.Creditor.Town = "" },
+		func(b *SwissBill) { b.Debtor.Town = "Rorschach" },
+		func(b *SwissBill) { b.Message = strings.Repeat("x", 120) },
+	} {
+		b := testSwissBill()
+		broken(&b)
+		if _, err := b.Build(); err == nil {
+			t.Errorf("Invalid bill %v is built", b)
+		}
+	}
+
+	if _, err := b.Marshal(SwissMaxVersion + 1); err != VersionTooBigError {
+		t.Errorf("Version greater than allowed doesn't give an error")
+	}
+	if _, err := b.Marshal(5); err != TooLongError {
+		t.Errorf("Bill is marshaled into too small version with %v", err)
+	}
+	if _, err := b.Marshal(SwissMaxVersion); err != nil {
+		t.Errorf("Failed to marshal: %v", err)
+	}
+}
+
+func TestParseSwissBill(t *testing.T) {
+	regular := SwissBill{
+		IBAN:          "CH9300762011623852957",
+		Creditor:      SwissAddress{Type: CombinedAddress, Name: "Someone", Line1: "Street 1", Line2: "8000 Zurich", Country: "CH"},
+		Currency:      "EUR",
+		ReferenceType: CreditorReference,
+		Reference:     "RF18539007547034",
+	}
+	withSchemes := regular
+	withSchemes.ReferenceType, withSchemes.Reference = NoReference, ""
+	withSchemes.AlternativeSchemes = []string{"eBill/B/someone@example.com"}
+
+	b := testSwissBill()
+	b.IBAN = NormalizeIBAN(b.IBAN)
+	for _, bill := range []SwissBill{b, regular, withSchemes} {
+		parsed, err := ParseSwissBill(bill.String())
+		if err != nil {
+			t.Errorf("Failed to parse %q: %v", bill.String(), err)
+		} else if !reflect.DeepEqual(*parsed, bill) {
+			t.Errorf("Parsed %v instead of %v", *parsed, bill)
+		}
+	}
+
+	if _, err := ParseSwissBill("SPC\n0200\n1"); err == nil {
+		t.Errorf("Cut payload is parsed without error")
+	}
+}
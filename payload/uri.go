@@ -0,0 +1,206 @@
+package payload
+
+import (
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// normalizePhone removes visual separators from phone number
+// so that it consists only of alphanumeric characters
+func normalizePhone(phone string) string {
+	return strings.NewReplacer(" ", "", "(", "", ")", "", ".", "", "\t", "").Replace(phone)
+}
+
+// isPhone tells if phone contains only characters allowed in phone numbers
+func isPhone(phone string) bool {
+	if phone == "" {
+		return false
+	}
+
+	for i := 0; i < len(phone); i++ {
+		ch := phone[i]
+		if !(ch >= '0' && ch <= '9' || ch == '+' || ch == '-' || ch == '*' || ch == '#') {
+			return false
+		}
+	}
+	return true
+}
+
+// Geo is the payload with geographic location
+type Geo struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// validate checks that latitude and longitude are numbers within their ranges
+func (g *Geo) validate() error {
+	if math.IsNaN(g.Latitude) || g.Latitude < -90 || g.Latitude > 90 {
+		return WrongValueError
+	}
+	if math.IsNaN(g.Longitude) || g.Longitude < -180 || g.Longitude > 180 {
+		return WrongValueError
+	}
+	return nil
+}
+
+// Build returns geo: payload
+// coordinates are written with the least number of digits
+// throws WrongValueError if latitude or longitude is NaN or out of its range
+func (g *Geo) Build() (string, error) {
+	if err := g.validate(); err != nil {
+		return "", err
+	}
+
+	return "geo:" + strconv.FormatFloat(g.Latitude, 'f', -1, 64) + "," + strconv.FormatFloat(g.Longitude, 'f', -1, 64), nil
+}
+
+// String is meant for debugging: it returns geo: payload or the reason it can't be built,
+// use Build to get the payload that is put into the code
+func (g *Geo) String() string {
+	s, err := g.Build()
+	if err != nil {
+		return "invalid geo location: " + err.Error()
+	}
+	return s
+}
+
+// ParseGeo parses geo: payload
+// altitude and parameters are skipped
+func ParseGeo(str string) (*Geo, error) {
+	body, ok := cutScheme(str, "geo:")
+	if !ok {
+		return nil, WrongFormatError
+	}
+	body, _, _ = strings.Cut(body, ";")
+	body, _, _ = strings.Cut(body, "?")
+
+	coordinates := strings.Split(body, ",")
+	if len(coordinates) < 2 || len(coordinates) > 3 {
+		return nil, WrongFormatError
+	}
+
+	lat, err := strconv.ParseFloat(coordinates[0], 64)
+	if err != nil {
+		return nil, WrongValueError
+	}
+	lon, err := strconv.ParseFloat(coordinates[1], 64)
+	if err != nil {
+		return nil, WrongValueError
+	}
+
+	g := &Geo{Latitude: lat, Longitude: lon}
+	if err := g.validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// SMS is the payload with the message to be sent by SMS
+type SMS struct {
+	Number  string
+	Message string
+}
+
+// String returns SMSTO: payload
+// without message it fits alphanumeric mode
+func (s *SMS) String() string {
+	if s.Message == "" {
+		return "SMSTO:" + normalizePhone(s.Number)
+	}
+	return "SMSTO:" + normalizePhone(s.Number) + ":" + s.Message
+}
+
+// ParseSMS parses SMSTO: payload
+func ParseSMS(str string) (*SMS, error) {
+	body, ok := cutScheme(str, "SMSTO:")
+	if !ok {
+		return nil, WrongFormatError
+	}
+
+	number, message, _ := strings.Cut(body, ":")
+	number = normalizePhone(number)
+	if !isPhone(number) {
+		return nil, WrongValueError
+	}
+
+	return &SMS{Number: number, Message: message}, nil
+}
+
+// Email is the payload with the email to be sent
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// escapeQuery escapes value for mailto: query, spaces are encoded as %20
+func escapeQuery(str string) string {
+	return strings.ReplaceAll(url.QueryEscape(str), "+", "%20")
+}
+
+// String returns mailto: payload
+func (e *Email) String() string {
+	sb := strings.Builder{}
+	sb.WriteString("mailto:")
+	sb.WriteString(e.To)
+
+	sep := "?"
+	if e.Subject != "" {
+		sb.WriteString(sep + "subject=" + escapeQuery(e.Subject))
+		sep = "&"
+	}
+	if e.Body != "" {
+		sb.WriteString(sep + "body=" + escapeQuery(e.Body))
+	}
+
+	return sb.String()
+}
+
+// ParseEmail parses mailto: payload
+func ParseEmail(str string) (*Email, error) {
+	body, ok := cutScheme(str, "mailto:")
+	if !ok {
+		return nil, WrongFormatError
+	}
+
+	to, query, _ := strings.Cut(body, "?")
+	to, err := url.PathUnescape(to)
+	if err != nil || !strings.Contains(to, "@") {
+		return nil, WrongValueError
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, WrongFormatError
+	}
+
+	return &Email{To: to, Subject: values.Get("subject"), Body: values.Get("body")}, nil
+}
+
+// Tel is the payload with the phone number to call
+type Tel struct {
+	Number string
+}
+
+// String returns TEL: payload
+// scheme is uppercased and separators are removed so it fits alphanumeric mode
+func (t *Tel) String() string {
+	return "TEL:" + normalizePhone(t.Number)
+}
+
+// ParseTel parses tel: payload
+func ParseTel(str string) (*Tel, error) {
+	number, ok := cutScheme(str, "tel:")
+	if !ok {
+		return nil, WrongFormatError
+	}
+
+	number = normalizePhone(number)
+	if !isPhone(number) {
+		return nil, WrongValueError
+	}
+
+	return &Tel{Number: number}, nil
+}
@@ -0,0 +1,117 @@
+package payload
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+func TestGeo(t *testing.T) {
+	g := Geo{Latitude: 55.75, Longitude: -37.6}
+	if s, err := g.Build(); err != nil || s != "geo:55.75,-37.6" {
+		t.Errorf("Built %s instead of geo:55.75,-37.6: %v", s, err)
+	}
+
+	for _, wrong := range []Geo{{Latitude: math.NaN()}, {Longitude: math.Inf(1)}, {Latitude: -90.5}, {Longitude: 180.1}} {
+		if _, err := wrong.Build(); err != WrongValueError {
+			t.Errorf("Wrong location %v is built with %v", wrong, err)
+		}
+		if _, err := Marshal(&wrong, qr_tools.L, 2); err != WrongValueError {
+			t.Errorf("Wrong location %v is marshaled with %v", wrong, err)
+		}
+	}
+
+	parsed, err := ParseGeo("GEO:55.75,-37.6,120;u=35")
+	if err != nil || *parsed != g {
+		t.Errorf("Parsed %v instead of %v: %v", parsed, g, err)
+	}
+
+	for _, s := range []string{"geo:91,0", "geo:0,181", "geo:NaN,0", "geo:1", "geo:a,b", "loc:1,2"} {
+		if _, err := ParseGeo(s); err == nil {
+			t.Errorf("Wrong payload %s is parsed without error", s)
+		}
+	}
+}
+
+func TestSMS(t *testing.T) {
+	cases := []struct {
+		s        SMS
+		expected string
+	}{
+		{SMS{Number: "+1 (212) 555-1234"}, "SMSTO:+1212555-1234"},
+		{SMS{Number: "+12125551234", Message: "Hi: there"}, "SMSTO:+12125551234:Hi: there"},
+	}
+
+	for _, c := range cases {
+		if s := c.s.String(); s != c.expected {
+			t.Errorf("Built %s instead of %s", s, c.expected)
+		}
+	}
+
+	parsed, err := ParseSMS("smsto:+12125551234:Hi: there")
+	if err != nil || parsed.Number != "+12125551234" || parsed.Message != "Hi: there" {
+		t.Errorf("SMS is parsed wrong: %v, %v", parsed, err)
+	}
+	// separators are removed the same way String removes them
+	parsed, err = ParseSMS("SMSTO:+1 (212) 555-1234:Hi")
+	if err != nil || parsed.Number != "+1212555-1234" || parsed.Message != "Hi" {
+		t.Errorf("SMS with separators is parsed wrong: %v, %v", parsed, err)
+	}
+
+	for _, s := range []string{"SMSTO:", "SMSTO:abc:hi", "SMS:+123"} {
+		if _, err := ParseSMS(s); err == nil {
+			t.Errorf("Wrong payload %s is parsed without error", s)
+		}
+	}
+}
+
+func TestEmail(t *testing.T) {
+	e := Email{To: "john@example.com", Subject: "Hello there", Body: "a&b=c+d"}
+	expected := "mailto:john@example.com?subject=Hello%20there&body=a%26b%3Dc%2Bd"
+	if s := e.String(); s != expected {
+		t.Errorf("Built %s instead of %s", s, expected)
+	}
+
+	parsed, err := ParseEmail(expected)
+	if err != nil || *parsed != e {
+		t.Errorf("Parsed %v instead of %v: %v", parsed, e, err)
+	}
+
+	if s := (&Email{To: "a@b.c"}).String(); s != "mailto:a@b.c" {
+		t.Errorf("Built %s instead of mailto:a@b.c", s)
+	}
+
+	for _, s := range []string{"mailto:nobody", "email:a@b.c"} {
+		if _, err := ParseEmail(s); err == nil {
+			t.Errorf("Wrong payload %s is parsed without error", s)
+		}
+	}
+}
+
+func TestTel(t *testing.T) {
+	tel := Tel{Number: "+1 (212) 555.1234"}
+	if s := tel.String(); s != "TEL:+12125551234" {
+		t.Errorf("Built %s instead of TEL:+12125551234", s)
+	}
+
+	parsed, err := ParseTel("tel:+1-212-555-1234")
+	if err != nil || parsed.Number != "+1-212-555-1234" {
+		t.Errorf("Tel is parsed wrong: %v, %v", parsed, err)
+	}
+
+	if _, err := ParseTel("tel:call me"); err == nil {
+		t.Errorf("Wrong payload is parsed without error")
+	}
+
+	// the whole payload should go to alphanumeric mode
+	data, err := Marshal(&tel, qr_tools.L, 1)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	expected, _ := qr_tools.NewAlphanumericMarshaler(qr_tools.L, 1).MarshalString(tel.String())
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Tel payload isn't marshaled in alphanumeric mode")
+	}
+}
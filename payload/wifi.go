@@ -0,0 +1,78 @@
+package payload
+
+import (
+	"strings"
+)
+
+// characters that should be escaped in MECARD: and WIFI: values
+const fieldSpecials = ";,:\""
+
+// WiFiSecurity is enum that
+// shows what authentication Wi-Fi network uses
+type WiFiSecurity string
+
+const (
+	WPA    WiFiSecurity = "WPA"
+	WEP    WiFiSecurity = "WEP"
+	NoPass WiFiSecurity = "nopass"
+)
+
+// WiFi is the payload with Wi-Fi network credentials
+type WiFi struct {
+	SSID     string
+	Password string
+	Security WiFiSecurity
+	Hidden   bool
+}
+
+// String returns WIFI: payload
+// empty fields are omitted
+func (w *WiFi) String() string {
+	sb := strings.Builder{}
+	sb.WriteString("WIFI:")
+	if w.Security != "" {
+		sb.WriteString("T:" + string(w.Security) + ";")
+	}
+	sb.WriteString("S:" + escape(w.SSID, fieldSpecials) + ";")
+	if w.Password != "" && w.Security != NoPass {
+		sb.WriteString("P:" + escape(w.Password, fieldSpecials) + ";")
+	}
+	if w.Hidden {
+		sb.WriteString("H:true;")
+	}
+	sb.WriteString(";")
+
+	return sb.String()
+}
+
+// ParseWiFi parses WIFI: payload
+func ParseWiFi(str string) (*WiFi, error) {
+	body, ok := cutScheme(str, "WIFI:")
+	if !ok {
+		return nil, WrongFormatError
+	}
+
+	fields, err := parseFields(body)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &WiFi{}
+	for _, f := range fields {
+		switch f.key {
+		case "T":
+			w.Security = WiFiSecurity(unescape(f.value))
+		case "S":
+			w.SSID = unescape(f.value)
+		case "P":
+			w.Password = unescape(f.value)
+		case "H":
+			w.Hidden = strings.EqualFold(f.value, "true")
+		}
+	}
+	if w.SSID == "" {
+		return nil, WrongFormatError
+	}
+
+	return w, nil
+}
@@ -0,0 +1,43 @@
+package payload
+
+import (
+	"testing"
+)
+
+func TestWiFi_String(t *testing.T) {
+	cases := []struct {
+		w        WiFi
+		expected string
+	}{
+		{WiFi{SSID: "home", Password: "secret", Security: WPA}, "WIFI:T:WPA;S:home;P:secret;;"},
+		{WiFi{SSID: `a;b,c:d"e\f`, Password: "p;", Security: WEP, Hidden: true}, `WIFI:T:WEP;S:a\;b\,c\:d\"e\\f;P:p\;;H:true;;`},
+		{WiFi{SSID: "cafe", Password: "ignored", Security: NoPass}, "WIFI:T:nopass;S:cafe;;"},
+	}
+
+	for _, c := range cases {
+		if s := c.w.String(); s != c.expected {
+			t.Errorf("Built %s instead of %s", s, c.expected)
+		}
+	}
+}
+
+func TestParseWiFi(t *testing.T) {
+	for _, w := range []WiFi{
+		{SSID: "home", Password: "secret", Security: WPA},
+		{SSID: `a;b,c:d"e\f`, Password: `p;\`, Security: WEP, Hidden: true},
+		{SSID: "cafe", Security: NoPass},
+	} {
+		parsed, err := ParseWiFi(w.String())
+		if err != nil {
+			t.Errorf("Failed to parse %s: %v", w.String(), err)
+		} else if *parsed != w {
+			t.Errorf("Parsed %v instead of %v", *parsed, w)
+		}
+	}
+
+	for _, s := range []string{"WIFI:S:home", "WIFI:P:secret;;", "MECARD:S:home;;", "WIFI:S;;"} {
+		if _, err := ParseWiFi(s); err == nil {
+			t.Errorf("Wrong payload %s is parsed without error", s)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package qr_tools
+
+import (
+	"github.com/rinnothing/qr-tools/internal/gf256"
+)
+
+// rsGenerator returns generator polynomial (x - 2^0)(x - 2^1)...(x - 2^(n-1)) of Reed–Solomon code
+// with n error correction codewords, coefficients go from the highest degree
+func rsGenerator(n int) []byte {
+	gen := make([]byte, 1, n+1)
+	gen[0] = 1
+	for i := 0; i < n; i++ {
+		// multiplying by (x + 2^i)
+		gen = append(gen, 0)
+		for j := len(gen) - 1; j > 0; j-- {
+			gen[j] = gf256.Add(gen[j], gf256.Mul(gen[j-1], gf256.Exp(i)))
+		}
+	}
+	return gen
+}
+
+// rsEncode returns n error correction codewords of data:
+// the remainder of data polynomial multiplied by x^n divided by the generator
+func rsEncode(data []byte, n int) []byte {
+	gen := rsGenerator(n)
+	ec := make([]byte, n)
+	for _, d := range data {
+		factor := gf256.Add(d, ec[0])
+		copy(ec, ec[1:])
+		ec[n-1] = 0
+		for i := range ec {
+			ec[i] = gf256.Add(ec[i], gf256.Mul(gen[i+1], factor))
+		}
+	}
+	return ec
+}
+
+// rsSyndromes returns values of block polynomial at 2^0...2^(n-1)
+// and tells if all of them are zero, so the block has no errors
+func rsSyndromes(block []byte, n int) ([]byte, bool) {
+	syndromes := make([]byte, n)
+	clean := true
+	for j := range syndromes {
+		var s byte
+		for _, b := range block {
+			s = gf256.Add(gf256.Mul(s, gf256.Exp(j)), b)
+		}
+		syndromes[j] = s
+		clean = clean && s == 0
+	}
+	return syndromes, clean
+}
+
+// evaluate returns the value of polynomial with coefficients from the lowest degree at x
+func evaluate(poly []byte, x byte) byte {
+	var y byte
+	for i := len(poly) - 1; i >= 0; i-- {
+		y = gf256.Add(gf256.Mul(y, x), poly[i])
+	}
+	return y
+}
+
+// rsCorrect corrects errors of block (data codewords followed by n error correction ones) in place
+// and returns the number of corrected codewords, up to n/2 errors can be corrected
+// throws corruptedDataError if there are more of them
+func rsCorrect(block []byte, n int) (int, error) {
+	syndromes, clean := rsSyndromes(block, n)
+	if clean {
+		return 0, nil
+	}
+
+	// Berlekamp–Massey finds error locator polynomial, coefficients go from the lowest degree
+	locator, prev := []byte{1}, []byte{1}
+	errs, shift, last := 0, 1, byte(1)
+	for k := 0; k < n; k++ {
+		d := syndromes[k]
+		for i := 1; i <= k && i < len(locator); i++ {
+			d = gf256.Add(d, gf256.Mul(locator[i], syndromes[k-i]))
+		}
+		if d == 0 {
+			shift++
+			continue
+		}
+
+		next := make([]byte, max(len(locator), len(prev)+shift))
+		copy(next, locator)
+		factor := gf256.Div(d, last)
+		for i, c := range prev {
+			next[i+shift] = gf256.Add(next[i+shift], gf256.Mul(factor, c))
+		}
+		if 2*errs <= k {
+			prev, errs, last, shift = locator, k+1-errs, d, 1
+		} else {
+			shift++
+		}
+		locator = next
+	}
+	if 2*errs > n {
+		return 0, corruptedDataError
+	}
+
+	// evaluator is syndromes polynomial multiplied by locator modulo x^n
+	evaluator := make([]byte, n)
+	for i := range evaluator {
+		for j := 0; j <= i && j < len(locator); j++ {
+			evaluator[i] = gf256.Add(evaluator[i], gf256.Mul(locator[j], syndromes[i-j]))
+		}
+	}
+	// formal derivative of locator keeps only odd powers in characteristic 2
+	derivative := make([]byte, len(locator))
+	for i := 1; i < len(locator); i += 2 {
+		derivative[i-1] = locator[i]
+	}
+
+	// Chien search tries every position, Forney's formula gives the error value there
+	found := 0
+	for i := range block {
+		power := len(block) - 1 - i
+		inverse := gf256.Exp(255 - power%255)
+		if evaluate(locator, inverse) != 0 {
+			continue
+		}
+		denominator := evaluate(derivative, inverse)
+		if denominator == 0 {
+			return 0, corruptedDataError
+		}
+		value := gf256.Mul(gf256.Exp(power), gf256.Div(evaluate(evaluator, inverse), denominator))
+		block[i] = gf256.Add(block[i], value)
+		found++
+	}
+	if found != errs {
+		return 0, corruptedDataError
+	}
+	if _, clean := rsSyndromes(block, n); !clean {
+		return 0, corruptedDataError
+	}
+
+	return found, nil
+}
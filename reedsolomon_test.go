@@ -0,0 +1,62 @@
+package qr_tools
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRSEncode(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     []byte
+		expected []byte
+	}{
+		{"HELLO WORLD 1-M", []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17},
+			[]byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}},
+		{"01234567 1-M", []byte{16, 32, 12, 86, 97, 128, 236, 17, 236, 17, 236, 17, 236, 17, 236, 17},
+			[]byte{165, 36, 212, 193, 237, 54, 199, 135, 44, 85}},
+	}
+
+	for _, c := range cases {
+		if ec := rsEncode(c.data, len(c.expected)); !reflect.DeepEqual(ec, c.expected) {
+			t.Errorf("Error correction of %s is %v instead of %v", c.name, ec, c.expected)
+		}
+	}
+}
+
+func TestRSCorrect(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	block := append(data, rsEncode(data, 10)...)
+	original := append([]byte(nil), block...)
+
+	cases := []struct {
+		name      string
+		positions []int
+		expected  error
+	}{
+		{"clean", nil, nil},
+		{"single", []int{3}, nil},
+		{"data and error correction", []int{0, 7, 15, 20, 25}, nil},
+		{"too many", []int{0, 2, 4, 6, 8, 10}, corruptedDataError},
+	}
+
+	for _, c := range cases {
+		damaged := append([]byte(nil), block...)
+		for _, p := range c.positions {
+			damaged[p] ^= 0x5a
+		}
+
+		n, err := rsCorrect(damaged, 10)
+		if !errors.Is(err, c.expected) {
+			t.Errorf("Correction of %s gives %v instead of %v", c.name, err, c.expected)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if n != len(c.positions) || !reflect.DeepEqual(damaged, original) {
+			t.Errorf("Correction of %s fixes %d codewords: %v instead of %v", c.name, n, damaged, original)
+		}
+	}
+}
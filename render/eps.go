@@ -0,0 +1,89 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// run is a horizontal run of n dark modules starting at (x, y)
+type run struct {
+	x, y, n int
+}
+
+// runs returns all the runs of dark modules row by row
+func runs(modules [][]bool) []run {
+	result := make([]run, 0)
+	for y, row := range modules {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			result = append(result, run{x: start, y: y, n: x - start})
+		}
+	}
+	return result
+}
+
+// psColor returns PostScript operator setting c, transparency is ignored
+func psColor(c color.RGBA) string {
+	return fmt.Sprintf("%s %s %s setrgbcolor\n", num(float64(c.R)/255), num(float64(c.G)/255), num(float64(c.B)/255))
+}
+
+// EPS returns the matrix with quiet zone and caption as Encapsulated PostScript document
+// Scale is the size of module in points, modules are drawn as squares
+// with Style.Foreground.From color on Style.Background
+func EPS(modules [][]bool, opts Options) (string, error) {
+	if err := opts.normalize(); err != nil {
+		return "", err
+	}
+	if _, err := version(modules); err != nil {
+		return "", err
+	}
+
+	size := len(modules)
+	width, height := opts.bounds(size)
+
+	sb := strings.Builder{}
+	sb.WriteString("%!PS-Adobe-3.0 EPSF-3.0\n")
+	fmt.Fprintf(&sb, "%%%%BoundingBox: 0 0 %d %d\n", width*opts.Scale, height*opts.Scale)
+	sb.WriteString("%%Creator: qr-tools\n%%EndComments\n")
+
+	// matrix is drawn in module units from the top left corner, like in SVG
+	sb.WriteString("gsave\n/m { 1 rectfill } bind def\n")
+	fmt.Fprintf(&sb, "0 %d translate\n%d dup neg scale\n%d %d translate\n",
+		height*opts.Scale, opts.Scale, opts.QuietZone, opts.QuietZone)
+
+	if bg := opts.Style.Background; bg.A != 0 {
+		sb.WriteString(psColor(bg))
+		fmt.Fprintf(&sb, "%d %d %d %d rectfill\n", -opts.QuietZone, -opts.QuietZone, width, height)
+	}
+
+	sb.WriteString(psColor(opts.Style.Foreground.From))
+	for _, r := range runs(modules) {
+		fmt.Fprintf(&sb, "%d %d %d m\n", r.x, r.y, r.n)
+	}
+	for _, p := range opts.captionPrimitives(size) {
+		fmt.Fprintf(&sb, "%s %s %s %s rectfill\n", num(p.x), num(p.y), num(p.w), num(p.h))
+	}
+
+	sb.WriteString("grestore\n%%EOF\n")
+	return sb.String(), nil
+}
+
+// WriteEPS draws the matrix and writes it as EPS
+func WriteEPS(w io.Writer, modules [][]bool, opts Options) error {
+	eps, err := EPS(modules, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, eps)
+	return err
+}
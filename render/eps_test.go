@@ -0,0 +1,71 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRuns(t *testing.T) {
+	modules := [][]bool{
+		{true, true, false, true},
+		{false, false, false, false},
+		{false, true, true, true},
+	}
+	expected := []run{{0, 0, 2}, {3, 0, 1}, {1, 2, 3}}
+
+	got := runs(modules)
+	if len(got) != len(expected) {
+		t.Fatalf("Got %v runs instead of %v", got, expected)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Errorf("Run %d is %v instead of %v", i, got[i], expected[i])
+		}
+	}
+}
+
+func TestEPS(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	x, y := findModule(t, modules, pair)
+	opts := DefaultOptions()
+	opts.Scale = 2
+
+	eps, err := EPS(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to write EPS: %v", err)
+	}
+	for _, part := range []string{
+		"%!PS-Adobe-3.0 EPSF-3.0\n%%BoundingBox: 0 0 58 58\n",
+		"0 58 translate\n2 dup neg scale\n4 4 translate\n",
+		"1 1 1 setrgbcolor\n-4 -4 29 29 rectfill\n",
+		// neighbouring modules are merged
+		"0 0 0 setrgbcolor\n0 0 7 m\n",
+		fmt.Sprintf("\n%d %d 2 m\n", x, y),
+	} {
+		if !strings.Contains(eps, part) {
+			t.Errorf("EPS doesn't contain %q: %s", part, eps)
+		}
+	}
+	if !strings.HasSuffix(eps, "grestore\n%%EOF\n") {
+		t.Errorf("EPS isn't finished")
+	}
+
+	opts.Caption = "HI"
+	eps, _ = EPS(modules, opts)
+	if !strings.Contains(eps, "%%BoundingBox: 0 0 58 68\n") || !strings.Contains(eps, " rectfill\ngrestore") {
+		t.Errorf("Caption isn't drawn: %s", eps)
+	}
+
+	buf := bytes.Buffer{}
+	if err := WriteEPS(&buf, modules, opts); err != nil || buf.String() != eps {
+		t.Errorf("WriteEPS differs from EPS with %v", err)
+	}
+	if _, err := EPS(modules, Options{}); err != WrongScaleError {
+		t.Errorf("Zero scale is accepted with %v", err)
+	}
+	if _, err := EPS(modules[1:], opts); err != WrongSizeError {
+		t.Errorf("Wrong matrix is written with %v", err)
+	}
+}
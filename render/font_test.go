@@ -0,0 +1,57 @@
+package render
+
+import "testing"
+
+func TestGlyph(t *testing.T) {
+	if glyph('a') != glyphs['A'] {
+		t.Errorf("Lowercase letter isn't drawn as uppercase")
+	}
+	if glyph('ж') != glyphs['?'] {
+		t.Errorf("Unknown character isn't drawn as '?'")
+	}
+}
+
+func TestCaptionPrimitives(t *testing.T) {
+	cases := []struct {
+		caption string
+		width   float64
+		unit    float64
+		height  int
+	}{
+		{"", 29, 0.5, 0},
+		{"HELLO", 29, 0.5, 5},
+		// 40 characters are 239 font pixels wide, they are squeezed into 27 modules
+		{"0123456789012345678901234567890123456789", 29, 27.0 / 239, 2},
+	}
+
+	for _, c := range cases {
+		if got := captionHeight(c.caption, c.width); got != c.height {
+			t.Errorf("Caption %q is %d modules high instead of %d", c.caption, got, c.height)
+		}
+		if c.caption == "" {
+			if p := captionPrimitives(c.caption, 0, 0, c.width); len(p) != 0 {
+				t.Errorf("Empty caption has %d primitives", len(p))
+			}
+			continue
+		}
+		if got := captionUnit(c.caption, c.width); got != c.unit {
+			t.Errorf("Font pixel of caption %q is %v instead of %v", c.caption, got, c.unit)
+		}
+
+		minX, maxX := c.width, 0.0
+		for _, p := range captionPrimitives(c.caption, 0, 0, c.width) {
+			minX, maxX = min(minX, p.x), max(maxX, p.x+p.w)
+			if !p.caption {
+				t.Errorf("Primitive of caption %q isn't marked as caption", c.caption)
+			}
+		}
+		if minX < 1-1e-9 || maxX > c.width-1+1e-9 {
+			t.Errorf("Caption %q goes from %v to %v out of the margins", c.caption, minX, maxX)
+		}
+	}
+
+	// top row of T is merged into a single primitive
+	if p := captionPrimitives("T", 0, 0, 29); len(p) != 7 || p[0].w != 2.5 {
+		t.Errorf("T is drawn with %v instead of 7 rows", p)
+	}
+}
@@ -0,0 +1,51 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+)
+
+var (
+	NoFramesError = errors.New("there are no frames to animate")
+)
+
+// GIF returns the matrices drawn by Image as frames of animated GIF that loops forever,
+// delay is the time every frame is shown in hundredths of a second
+// all the matrices must be of the same version, colors are mapped to Plan 9 palette
+func GIF(matrices [][][]bool, opts Options, delay int) (*gif.GIF, error) {
+	if len(matrices) == 0 {
+		return nil, NoFramesError
+	}
+
+	anim := &gif.GIF{}
+	for _, modules := range matrices {
+		if len(modules) != len(matrices[0]) {
+			return nil, WrongSizeError
+		}
+		img, err := Image(modules, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		frame := image.NewPaletted(img.Bounds(), palette.Plan9)
+		draw.Draw(frame, frame.Bounds(), img, image.Point{}, draw.Src)
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, delay)
+	}
+
+	return anim, nil
+}
+
+// WriteGIF draws the matrices and writes them as animated GIF
+func WriteGIF(w io.Writer, matrices [][][]bool, opts Options, delay int) error {
+	anim, err := GIF(matrices, opts, delay)
+	if err != nil {
+		return err
+	}
+
+	return gif.EncodeAll(w, anim)
+}
@@ -0,0 +1,47 @@
+package render
+
+import (
+	"bytes"
+	"image/gif"
+	"testing"
+)
+
+func TestWriteGIF(t *testing.T) {
+	matrices := [][][]bool{
+		testCode(t, 1, "frame 1"),
+		testCode(t, 1, "frame 2"),
+		testCode(t, 1, "frame 3"),
+	}
+
+	buf := bytes.Buffer{}
+	if err := WriteGIF(&buf, matrices, DefaultOptions(), 20); err != nil {
+		t.Fatalf("Failed to write GIF: %v", err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("Failed to decode GIF: %v", err)
+	}
+	if len(anim.Image) != len(matrices) || anim.Delay[1] != 20 {
+		t.Fatalf("GIF has %d frames with delay %v", len(anim.Image), anim.Delay)
+	}
+
+	// every frame shows its own matrix
+	s := DefaultOptions().Scale
+	for i, modules := range matrices {
+		for y := range modules {
+			for x := range modules {
+				c := anim.Image[i].At((x+MinQuietZone)*s+s/2, (y+MinQuietZone)*s+s/2)
+				if r, _, _, _ := c.RGBA(); (r == 0) != modules[y][x] {
+					t.Fatalf("Module (%d, %d) of frame %d is %v", x, y, i, c)
+				}
+			}
+		}
+	}
+
+	if _, err := GIF(nil, DefaultOptions(), 20); err != NoFramesError {
+		t.Errorf("Empty animation is made with %v", err)
+	}
+	if _, err := GIF(append(matrices, testCode(t, 2, "frame 4")), DefaultOptions(), 20); err != WrongSizeError {
+		t.Errorf("Frames of different versions are animated with %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+var (
+	HalftoneScaleError = errors.New("scale of halftone must be a multiple of 3")
+)
+
+// finderDark tells if module (x, y) is dark in canonical finder pattern
+// it's used instead of the matrix, so that finder patterns are always whole
+func finderDark(x, y, size int) bool {
+	for _, corner := range [3][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}} {
+		dx, dy := x-corner[0]-3, y-corner[1]-3
+		if d := max(abs(dx), abs(dy)); d <= 3 {
+			return d != 2
+		}
+	}
+	return false
+}
+
+// ditherPicture scales the picture to n × n cells and dithers it with Floyd–Steinberg
+// in linear luminance, so that printed dots give the same brightness as the picture
+// true stands for dark cell
+func ditherPicture(picture image.Image, n int) [][]bool {
+	bounds := picture.Bounds()
+	levels := make([][]float64, n)
+	for y := range levels {
+		levels[y] = make([]float64, n)
+		for x := range levels[y] {
+			// box average of the picture area that falls into the cell
+			x0, x1 := bounds.Min.X+x*bounds.Dx()/n, bounds.Min.X+max((x+1)*bounds.Dx()/n, x*bounds.Dx()/n+1)
+			y0, y1 := bounds.Min.Y+y*bounds.Dy()/n, bounds.Min.Y+max((y+1)*bounds.Dy()/n, y*bounds.Dy()/n+1)
+
+			sum := 0.0
+			for py := y0; py < y1; py++ {
+				for px := x0; px < x1; px++ {
+					sum += luminance(color.RGBAModel.Convert(picture.At(px, py)).(color.RGBA))
+				}
+			}
+			levels[y][x] = sum / float64((x1-x0)*(y1-y0))
+		}
+	}
+
+	dark := make([][]bool, n)
+	for y := range dark {
+		dark[y] = make([]bool, n)
+		for x := range dark[y] {
+			old := levels[y][x]
+			value := 0.0
+			if old >= 0.5 {
+				value = 1
+			}
+			dark[y][x] = value == 0
+
+			// error is spread to the right and to the next row
+			e := old - value
+			spread := func(x, y int, weight float64) {
+				if x >= 0 && x < n && y < n {
+					levels[y][x] += e * weight
+				}
+			}
+			spread(x+1, y, 7.0/16)
+			spread(x-1, y+1, 3.0/16)
+			spread(x, y+1, 5.0/16)
+			spread(x+1, y+1, 1.0/16)
+		}
+	}
+
+	return dark
+}
+
+// Halftone draws the matrix over the picture: every module is split into 3 × 3 sub-modules,
+// the center one carries the module and the others follow dithered picture;
+// function patterns are drawn whole, so that the code can still be found
+//
+// Scale must be a multiple of 3, foreground and background are taken from Style.Foreground.From and Style.Background;
+// overlay and caption are drawn the way Image does it; the result is read and decoded the way Inspect does it
+// and UnreadableError is returned if any module is read wrong or the payload isn't restored;
+// HalftoneSegments also biases data modules towards the picture
+func Halftone(modules [][]bool, picture image.Image, opts Options) (*image.RGBA, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+	if opts.Scale%3 != 0 {
+		return nil, HalftoneScaleError
+	}
+	ver, err := version(modules)
+	if err != nil {
+		return nil, err
+	}
+
+	return halftone(modules, ver, ditherPicture(picture, 3*len(modules)), opts)
+}
+
+// HalftoneSegments encodes segments with chosen version and ErrorCorrectionLevel, so that data modules follow
+// the centers of dithered picture as much as free pad codewords and the choice of mask allow it,
+// and draws the matrix over the picture the way Halftone does it
+// throws the errors of qr_tools.EncodeBiased
+func HalftoneSegments(segments []qr_tools.Segment, lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion,
+	picture image.Image, opts Options) (*image.RGBA, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+	if opts.Scale%3 != 0 {
+		return nil, HalftoneScaleError
+	}
+	kinds, err := qr_tools.FunctionPatterns(ver)
+	if err != nil {
+		return nil, err
+	}
+
+	size := len(kinds)
+	dithered := ditherPicture(picture, 3*size)
+	target := make([][]bool, size)
+	for y := range target {
+		target[y] = make([]bool, size)
+		for x := range target[y] {
+			target[y][x] = dithered[3*y+1][3*x+1]
+		}
+	}
+
+	modules, err := qr_tools.EncodeBiased(segments, lvl, ver, target)
+	if err != nil {
+		return nil, err
+	}
+	return halftone(modules, ver, dithered, opts)
+}
+
+// halftone draws the matrix of chosen version over dithered picture with 3 × 3 cells in a module
+func halftone(modules [][]bool, ver qr_tools.QRVersion, dithered [][]bool, opts Options) (*image.RGBA, error) {
+	kinds, err := qr_tools.FunctionPatterns(ver)
+	if err != nil {
+		return nil, err
+	}
+
+	size := len(modules)
+	sub := opts.Scale / 3
+
+	quietZone := opts.QuietZone
+	width, height := opts.bounds(size)
+	img := image.NewRGBA(image.Rect(0, 0, width*opts.Scale, height*opts.Scale))
+	draw.Draw(img, img.Bounds(), image.NewUniform(opts.Style.Background), image.Point{}, draw.Src)
+	fg := image.NewUniform(opts.Style.Foreground.From)
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			for sy := 0; sy < 3; sy++ {
+				for sx := 0; sx < 3; sx++ {
+					var dark bool
+					switch {
+					case kinds[y][x] == qr_tools.FinderModule:
+						dark = finderDark(x, y, size)
+					case kinds[y][x].IsFunction():
+						dark = modules[y][x]
+					case sx == 1 && sy == 1:
+						dark = modules[y][x]
+					default:
+						dark = dithered[3*y+sy][3*x+sx]
+					}
+					if !dark {
+						continue
+					}
+
+					px := (x+quietZone)*opts.Scale + sx*sub
+					py := (y+quietZone)*opts.Scale + sy*sub
+					draw.Draw(img, image.Rect(px, py, px+sub, py+sub), fg, image.Point{}, draw.Src)
+				}
+			}
+		}
+	}
+
+	overlay := opts.overlayPrimitives(size)
+	for _, p := range overlay {
+		drawPrimitive(img, p, opts, size)
+	}
+	if opts.Logo != nil && opts.Logo.Area != nil {
+		opts.Logo.drawPicture(img, opts, size)
+	}
+	for _, p := range opts.captionPrimitives(size) {
+		drawPrimitive(img, p, opts, size)
+	}
+
+	read, _, mismatches := sample(img, modules, kinds, overlay, opts.Scale, quietZone)
+	decodeErr, err := roundTrip(read, modules)
+	if err != nil {
+		return nil, err
+	}
+	if mismatches != 0 || decodeErr != nil {
+		return img, UnreadableError
+	}
+	return img, nil
+}
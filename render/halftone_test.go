@@ -0,0 +1,179 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+	"github.com/rinnothing/qr-tools/logo"
+)
+
+// gradientPicture returns picture going from black on the left to white on the right
+func gradientPicture(w, h int) image.Image {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8(x * 255 / (w - 1))})
+		}
+	}
+	return img
+}
+
+func TestDitherPicture(t *testing.T) {
+	dark := ditherPicture(gradientPicture(300, 100), 30)
+
+	// share of dark cells follows linear luminance, so mid gray gets more dark cells than light ones
+	for _, c := range []struct{ from, to, min, max int }{{0, 6, 25, 30}, {12, 18, 18, 27}, {24, 30, 0, 9}} {
+		count := 0
+		for y := 0; y < 30; y++ {
+			for x := c.from; x < c.to; x++ {
+				if dark[y][x] {
+					count++
+				}
+			}
+		}
+		if share := count * 30 / (30 * (c.to - c.from)); share < c.min || share > c.max {
+			t.Errorf("Columns %d-%d have %d/30 dark cells", c.from, c.to, share)
+		}
+	}
+}
+
+// readHalftone reads module centers of the halftone picture and decodes its payload
+func readHalftone(t *testing.T, img *image.RGBA, size int, opts Options) string {
+	t.Helper()
+	modules := make([][]bool, size)
+	for y := range modules {
+		modules[y] = make([]bool, size)
+	}
+	ver, _ := version(modules)
+	kinds, _ := qr_tools.FunctionPatterns(ver)
+
+	read, _, _ := sample(img, modules, kinds, nil, opts.Scale, opts.QuietZone)
+	got, err := payload(read)
+	if err != nil {
+		t.Fatalf("Failed to decode halftone: %v", err)
+	}
+	return string(got)
+}
+
+func TestHalftone(t *testing.T) {
+	modules := testCode(t, 3, "https://example.com/halftone")
+	opts := DefaultOptions()
+	opts.Scale = 9
+
+	img, err := Halftone(modules, gradientPicture(200, 200), opts)
+	if err != nil {
+		t.Fatalf("Failed to render halftone: %v", err)
+	}
+
+	sub := func(x, y, sx, sy int) color.RGBA {
+		return img.RGBAAt((x+opts.QuietZone)*9+sx*3+1, (y+opts.QuietZone)*9+sy*3+1)
+	}
+	kinds, _ := qr_tools.FunctionPatterns(3)
+	for y := range modules {
+		for x := range modules {
+			if kinds[y][x] != qr_tools.FinderModule && (sub(x, y, 1, 1) == black) != modules[y][x] {
+				t.Fatalf("Center of module (%d, %d) doesn't match the matrix", x, y)
+			}
+		}
+	}
+
+	// data module at the left of the code is surrounded by dark picture, at the right by light one
+	if sub(9, 15, 0, 0) != black || sub(27, 15, 2, 2) != white {
+		t.Errorf("Sub-modules don't follow the picture")
+	}
+	// finder pattern is whole
+	if sub(1, 1, 0, 0) != white || sub(0, 0, 2, 2) != black {
+		t.Errorf("Finder pattern is broken")
+	}
+	if got := readHalftone(t, img, len(modules), opts); got != "https://example.com/halftone" {
+		t.Errorf("Halftone holds %q", got)
+	}
+
+	opts.Scale = 8
+	if _, err := Halftone(modules, gradientPicture(10, 10), opts); err != HalftoneScaleError {
+		t.Errorf("Scale 8 is accepted with %v", err)
+	}
+}
+
+func TestHalftoneSegments(t *testing.T) {
+	text := "https://example.com/halftone"
+	segments := []qr_tools.Segment{qr_tools.NewByteSegment([]byte(text))}
+	picture := gradientPicture(200, 200)
+	opts := DefaultOptions()
+	opts.Scale = 9
+
+	img, err := HalftoneSegments(segments, qr_tools.L, 5, picture, opts)
+	if err != nil {
+		t.Fatalf("Failed to render halftone: %v", err)
+	}
+	if got := readHalftone(t, img, 37, opts); got != text {
+		t.Errorf("Halftone holds %q instead of %q", got, text)
+	}
+
+	// biased data modules follow the picture closer than the plain ones
+	plain, _ := qr_tools.Encode(segments, qr_tools.L, 5)
+	biased := make([][]bool, 37)
+	for y := range biased {
+		biased[y] = make([]bool, 37)
+		for x := range biased[y] {
+			biased[y][x] = img.RGBAAt((x+opts.QuietZone)*9+4, (y+opts.QuietZone)*9+4) == black
+		}
+	}
+	dithered := ditherPicture(picture, 3*37)
+	kinds, _ := qr_tools.FunctionPatterns(5)
+	mismatches := func(modules [][]bool) int {
+		count := 0
+		for y := range modules {
+			for x := range modules {
+				if kinds[y][x] == qr_tools.DataModule && modules[y][x] != dithered[3*y+1][3*x+1] {
+					count++
+				}
+			}
+		}
+		return count
+	}
+	if mismatches(biased) >= mismatches(plain) {
+		t.Errorf("Biased modules have %d mismatches with the picture, plain ones %d", mismatches(biased), mismatches(plain))
+	}
+
+	if _, err := HalftoneSegments(segments, qr_tools.L, 1, picture, opts); err == nil {
+		t.Errorf("Too long segments are drawn")
+	}
+}
+
+func TestHalftone_Overlay(t *testing.T) {
+	modules := testCode(t, 10, "SPC\n0200\n1\nCH4431999123000889012")
+	opts := DefaultOptions()
+	opts.Scale, opts.SwissCross = 9, true
+
+	img, err := Halftone(modules, gradientPicture(200, 200), opts)
+	if err != nil {
+		t.Fatalf("Failed to render halftone with Swiss cross: %v", err)
+	}
+	at := func(x, y float64) color.RGBA {
+		return img.RGBAAt(int((x+float64(opts.QuietZone))*9), int((y+float64(opts.QuietZone))*9))
+	}
+	if at(28.5, 28.5) != white || at(25.9, 25.9) != black {
+		t.Errorf("Swiss cross isn't drawn over halftone")
+	}
+
+	modules = testCode(t, 5, "https://example.com/logo")
+	picture := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	picture.Set(0, 0, red)
+	opts.SwissCross, opts.Logo = false, &Logo{Area: logo.CenteredRect(0.2, 0.2), Picture: picture}
+	img, err = Halftone(modules, gradientPicture(200, 200), opts)
+	if err != nil {
+		t.Fatalf("Failed to render halftone with logo: %v", err)
+	}
+	if c := img.RGBAAt((18+opts.QuietZone)*9+1, (18+opts.QuietZone)*9+1); c != red {
+		t.Errorf("Logo is %v instead of %v", c, red)
+	}
+
+	// error correction can't restore the half of the code
+	opts.Logo = &Logo{Area: logo.CenteredRect(0.5, 0.5)}
+	if _, err := Halftone(modules, gradientPicture(200, 200), opts); err != UnreadableError {
+		t.Errorf("Halftone under big logo is drawn with %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"html/template"
+	"image/png"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// HTMLKind is enum that
+// shows which markup the matrix is drawn with
+type HTMLKind int
+
+const (
+	// TableHTML draws the matrix as a table, it's shown by old mail clients
+	TableHTML HTMLKind = iota
+	// GridHTML draws dark runs as cells of CSS grid
+	GridHTML
+)
+
+// writeTable writes the matrix as table, runs of equal modules are merged into cells with colspan
+func writeTable(sb *strings.Builder, modules [][]bool, opts Options, width int) {
+	fg, bg := hexColor(opts.Style.Foreground.From), hexColor(opts.Style.Background)
+	fmt.Fprintf(sb, `<table cellpadding="0" cellspacing="0" border="0" bgcolor="%s" style="border-collapse:collapse;background:%s">`, bg, bg)
+
+	cell := func(n int, dark bool, height int) {
+		c := bg
+		if dark {
+			c = fg
+		}
+		fmt.Fprintf(sb, `<td colspan="%d" width="%d" height="%d" bgcolor="%s" style="padding:0;width:%dpx;height:%dpx;background:%s"></td>`,
+			n, n*opts.Scale, height*opts.Scale, c, n*opts.Scale, height*opts.Scale, c)
+	}
+
+	if opts.QuietZone > 0 {
+		sb.WriteString("<tr>")
+		cell(width, false, opts.QuietZone)
+		sb.WriteString("</tr>")
+	}
+	for _, row := range modules {
+		sb.WriteString("<tr>")
+		if opts.QuietZone > 0 {
+			cell(opts.QuietZone, false, 1)
+		}
+		for x := 0; x < len(row); {
+			start := x
+			for x < len(row) && row[x] == row[start] {
+				x++
+			}
+			cell(x-start, row[start], 1)
+		}
+		if opts.QuietZone > 0 {
+			cell(opts.QuietZone, false, 1)
+		}
+		sb.WriteString("</tr>")
+	}
+	if opts.QuietZone > 0 {
+		sb.WriteString("<tr>")
+		cell(width, false, opts.QuietZone)
+		sb.WriteString("</tr>")
+	}
+	if opts.Caption != "" {
+		fmt.Fprintf(sb, `<tr><td colspan="%d" align="center" style="padding:0 0 %dpx;color:%s;font-family:monospace;text-align:center">%s</td></tr>`,
+			width, opts.Scale, fg, html.EscapeString(opts.Caption))
+	}
+
+	sb.WriteString("</table>")
+}
+
+// writeGrid writes the matrix as CSS grid, only runs of dark modules are cells
+func writeGrid(sb *strings.Builder, modules [][]bool, opts Options, width int) {
+	fg, bg := hexColor(opts.Style.Foreground.From), hexColor(opts.Style.Background)
+	fmt.Fprintf(sb, `<div style="display:inline-grid;grid-template-columns:repeat(%d,%dpx);grid-template-rows:repeat(%d,%dpx);background:%s">`,
+		width, opts.Scale, width, opts.Scale, bg)
+	// grid lines are counted from 1
+	for _, r := range runs(modules) {
+		fmt.Fprintf(sb, `<div style="grid-area:%d/%d/span 1/span %d;background:%s"></div>`,
+			r.y+opts.QuietZone+1, r.x+opts.QuietZone+1, r.n, fg)
+	}
+	if opts.Caption != "" {
+		fmt.Fprintf(sb, `<div style="grid-area:%d/1/span 1/-1;padding-bottom:%dpx;background:%s;color:%s;font-family:monospace;text-align:center">%s</div>`,
+			width+1, opts.Scale, bg, fg, html.EscapeString(opts.Caption))
+	}
+	sb.WriteString("</div>")
+}
+
+// HTML returns the matrix with quiet zone drawn with markup of chosen kind, Scale is the size of module in CSS pixels
+// modules are drawn with Style.Foreground.From color on Style.Background, caption is HTML text under the quiet zone
+func HTML(modules [][]bool, opts Options, kind HTMLKind) (string, error) {
+	if err := opts.normalize(); err != nil {
+		return "", err
+	}
+	if _, err := version(modules); err != nil {
+		return "", err
+	}
+
+	width := len(modules) + 2*opts.QuietZone
+	sb := strings.Builder{}
+	if kind == GridHTML {
+		writeGrid(&sb, modules, opts, width)
+	} else {
+		writeTable(&sb, modules, opts, width)
+	}
+
+	return sb.String(), nil
+}
+
+// WriteHTML draws the matrix and writes it as HTML
+func WriteHTML(w io.Writer, modules [][]bool, opts Options, kind HTMLKind) error {
+	markup, err := HTML(modules, opts, kind)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, markup)
+	return err
+}
+
+// PNGDataURI returns the matrix drawn by Image as base64 data URI
+func PNGDataURI(modules [][]bool, opts Options) (string, error) {
+	img, err := Image(modules, opts)
+	if err != nil {
+		return "", err
+	}
+
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// SVGDataURI returns the matrix drawn by SVG as percent-encoded data URI
+func SVGDataURI(modules [][]bool, opts Options) (string, error) {
+	svg, err := SVG(modules, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return "data:image/svg+xml," + url.PathEscape(svg), nil
+}
+
+// TemplateFuncs returns html/template functions that draw matrices with opts:
+//
+//	qrSVG returns inline SVG element
+//	qrDataURI returns PNG data URI for src attribute of img
+func TemplateFuncs(opts Options) template.FuncMap {
+	return template.FuncMap{
+		"qrSVG": func(modules [][]bool) (template.HTML, error) {
+			svg, err := SVG(modules, opts)
+			// markup is built from numbers and colors only, so it's safe
+			return template.HTML(svg), err
+		},
+		"qrDataURI": func(modules [][]bool) (template.URL, error) {
+			uri, err := PNGDataURI(modules, opts)
+			return template.URL(uri), err
+		},
+	}
+}
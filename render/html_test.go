@@ -0,0 +1,127 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"image/png"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHTML_Table(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	opts := DefaultOptions()
+	opts.Scale, opts.Caption = 2, "<Box 1>"
+
+	markup, err := HTML(modules, opts, TableHTML)
+	if err != nil {
+		t.Fatalf("Failed to write HTML: %v", err)
+	}
+	if n := strings.Count(markup, "<tr>"); n != 21+2+1 {
+		t.Errorf("Table has %d rows instead of 24", n)
+	}
+	for _, part := range []string{
+		`bgcolor="#ffffff"`,
+		// quiet zone rows
+		`<tr><td colspan="29" width="58" height="8" bgcolor="#ffffff"`,
+		// the first row: quiet zone, eye frame, separator
+		`<tr><td colspan="4" width="8" height="2" bgcolor="#ffffff" style="padding:0;width:8px;height:2px;background:#ffffff"></td>` +
+			`<td colspan="7" width="14" height="2" bgcolor="#000000" style="padding:0;width:14px;height:2px;background:#000000"></td>` +
+			`<td colspan="1" width="2" height="2" bgcolor="#ffffff"`,
+		`>&lt;Box 1&gt;</td></tr></table>`,
+	} {
+		if !strings.Contains(markup, part) {
+			t.Errorf("Table doesn't contain %s: %s", part, markup)
+		}
+	}
+
+	opts.NoQuietZone = true
+	markup, _ = HTML(modules, opts, TableHTML)
+	if n := strings.Count(markup, "<tr>"); n != 21+1 {
+		t.Errorf("Table without quiet zone has %d rows instead of 22", n)
+	}
+}
+
+func TestHTML_Grid(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	x, y := findModule(t, modules, pair)
+	opts := DefaultOptions()
+	opts.Caption = "A&B"
+
+	markup, err := HTML(modules, opts, GridHTML)
+	if err != nil {
+		t.Fatalf("Failed to write HTML: %v", err)
+	}
+	for _, part := range []string{
+		`grid-template-columns:repeat(29,8px);grid-template-rows:repeat(29,8px);background:#ffffff`,
+		`<div style="grid-area:5/5/span 1/span 7;background:#000000"></div>`,
+		fmt.Sprintf(`<div style="grid-area:%d/%d/span 1/span 2;background:#000000"></div>`, y+5, x+5),
+		`grid-area:30/1/span 1/-1;`, `>A&amp;B</div></div>`,
+	} {
+		if !strings.Contains(markup, part) {
+			t.Errorf("Grid doesn't contain %s: %s", part, markup)
+		}
+	}
+
+	buf := bytes.Buffer{}
+	if err := WriteHTML(&buf, modules, opts, GridHTML); err != nil || buf.String() != markup {
+		t.Errorf("WriteHTML differs from HTML with %v", err)
+	}
+	if _, err := HTML(modules[1:], opts, GridHTML); err != WrongSizeError {
+		t.Errorf("Wrong matrix is written with %v", err)
+	}
+}
+
+func TestDataURI(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	opts := DefaultOptions()
+
+	uri, err := PNGDataURI(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to make PNG URI: %v", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(uri, "data:image/png;base64,"))
+	if err != nil || !strings.HasPrefix(uri, "data:image/png;base64,") {
+		t.Fatalf("PNG URI is malformed: %v", err)
+	}
+	if img, err := png.Decode(bytes.NewReader(data)); err != nil || img.Bounds().Dx() != 29*opts.Scale {
+		t.Errorf("PNG URI doesn't hold the image: %v", err)
+	}
+
+	uri, err = SVGDataURI(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to make SVG URI: %v", err)
+	}
+	svg, _ := SVG(modules, opts)
+	if decoded, err := url.PathUnescape(strings.TrimPrefix(uri, "data:image/svg+xml,")); err != nil || decoded != svg {
+		t.Errorf("SVG URI doesn't hold the image: %v", err)
+	}
+	if strings.ContainsAny(uri, `<>"# `) {
+		t.Errorf("SVG URI isn't escaped: %s", uri)
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	tmpl := template.Must(template.New("page").Funcs(TemplateFuncs(DefaultOptions())).
+		Parse(`<p>{{qrSVG .}}</p><img src="{{qrDataURI .}}">`))
+
+	buf := bytes.Buffer{}
+	if err := tmpl.Execute(&buf, modules); err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+	page := buf.String()
+	if !strings.Contains(page, `<p><svg xmlns="http://www.w3.org/2000/svg"`) {
+		t.Errorf("SVG is escaped: %s", page)
+	}
+	if !strings.Contains(page, `<img src="data:image/png;base64,`) {
+		t.Errorf("Data URI is filtered: %s", page)
+	}
+
+	if err := tmpl.Execute(&bytes.Buffer{}, modules[1:]); err == nil {
+		t.Errorf("Template with wrong matrix is executed")
+	}
+}
@@ -0,0 +1,249 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Toolpath is enum that
+// shows how the laser goes over dark modules
+type Toolpath int
+
+const (
+	// RasterToolpath fills dark modules with horizontal lines going back and forth
+	RasterToolpath Toolpath = iota
+	// ContourToolpath goes around the borders of dark areas
+	ContourToolpath
+)
+
+// LaserOptions are the options of engraving toolpaths, sizes are in millimetres
+type LaserOptions struct {
+	Toolpath Toolpath
+	// Module is the side of module
+	Module float64
+	// QuietZone is the width of border in modules, origin is at the bottom left corner of it
+	QuietZone int
+	// Lines is the number of raster lines per row of modules
+	Lines int
+	// Feed is the speed of engraving in millimetres per minute and Power is the spindle value of G-code
+	Feed  float64
+	Power int
+}
+
+// DefaultLaserOptions returns options for raster engraving of 1 mm modules with 10 lines per module
+func DefaultLaserOptions() LaserOptions {
+	return LaserOptions{
+		Toolpath:  RasterToolpath,
+		Module:    1,
+		QuietZone: MinQuietZone,
+		Lines:     10,
+		Feed:      1000,
+		Power:     1000,
+	}
+}
+
+// Point is a point of toolpath in millimetres, y goes up
+type Point struct {
+	X, Y float64
+}
+
+// Path is a polyline the laser goes along while it's on, contours end with their first point
+type Path []Point
+
+// rasterPaths returns a line for every run of dark modules, lines go back and forth
+func rasterPaths(modules [][]bool, opts LaserOptions, full float64) []Path {
+	byRow := make([][]run, len(modules))
+	for _, r := range runs(modules) {
+		byRow[r.y] = append(byRow[r.y], r)
+	}
+
+	paths := make([]Path, 0)
+	line := 0
+	for y, row := range byRow {
+		for l := 0; l < opts.Lines; l++ {
+			py := (full - float64(y+opts.QuietZone) - (float64(l)+0.5)/float64(opts.Lines)) * opts.Module
+			backwards := line%2 == 1
+			line++
+
+			for k := range row {
+				r := row[k]
+				if backwards {
+					r = row[len(row)-1-k]
+				}
+				x0, x1 := float64(r.x+opts.QuietZone)*opts.Module, float64(r.x+r.n+opts.QuietZone)*opts.Module
+				if backwards {
+					x0, x1 = x1, x0
+				}
+				paths = append(paths, Path{{X: x0, Y: py}, {X: x1, Y: py}})
+			}
+		}
+	}
+
+	return paths
+}
+
+// contourPaths returns the borders of dark areas, every area is on the right of its border,
+// areas that touch only diagonally are kept apart
+func contourPaths(modules [][]bool, opts LaserOptions, full float64) []Path {
+	size := len(modules)
+	dark := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < size && y < size && modules[y][x]
+	}
+
+	// edges go clockwise around dark modules on the grid, only the borders are kept
+	type vertex struct{ x, y int }
+	type edge struct{ from, to vertex }
+	outgoing := make(map[vertex][]edge)
+	order := make([]vertex, 0)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if !dark(x, y) {
+				continue
+			}
+			corners := [4]vertex{{x, y}, {x + 1, y}, {x + 1, y + 1}, {x, y + 1}}
+			neighbours := [4][2]int{{x, y - 1}, {x + 1, y}, {x, y + 1}, {x - 1, y}}
+			for k, n := range neighbours {
+				if dark(n[0], n[1]) {
+					continue
+				}
+				e := edge{corners[k], corners[(k+1)%4]}
+				if len(outgoing[e.from]) == 0 {
+					order = append(order, e.from)
+				}
+				outgoing[e.from] = append(outgoing[e.from], e)
+			}
+		}
+	}
+
+	at := func(v vertex) Point {
+		return Point{X: float64(v.x+opts.QuietZone) * opts.Module, Y: (full - float64(v.y+opts.QuietZone)) * opts.Module}
+	}
+
+	paths := make([]Path, 0)
+	for _, start := range order {
+		for len(outgoing[start]) != 0 {
+			e := outgoing[start][0]
+			outgoing[start] = outgoing[start][1:]
+			path := Path{at(e.from)}
+
+			for e.to != start {
+				next := outgoing[e.to]
+				// right turn is taken at pinches, so that the area on the right isn't left
+				chosen := 0
+				dx, dy := e.to.x-e.from.x, e.to.y-e.from.y
+				for k, n := range next {
+					if n.to.x-n.from.x == -dy && n.to.y-n.from.y == dx {
+						chosen = k
+					}
+				}
+				n := next[chosen]
+				outgoing[e.to] = append(next[:chosen:chosen], next[chosen+1:]...)
+
+				// collinear edges are merged
+				if n.to.x-n.from.x != dx || n.to.y-n.from.y != dy {
+					path = append(path, at(e.to))
+				}
+				e = n
+			}
+			paths = append(paths, append(path, path[0]))
+		}
+	}
+
+	return paths
+}
+
+// Toolpaths returns the paths the laser goes along while it's on
+func Toolpaths(modules [][]bool, opts LaserOptions) ([]Path, error) {
+	if opts.Module <= 0 || opts.Feed <= 0 || opts.Toolpath == RasterToolpath && opts.Lines <= 0 {
+		return nil, WrongDimensionsError
+	}
+	if opts.QuietZone < 0 {
+		return nil, WrongQuietZoneError
+	}
+	if _, err := version(modules); err != nil {
+		return nil, err
+	}
+
+	full := float64(len(modules) + 2*opts.QuietZone)
+	if opts.Toolpath == ContourToolpath {
+		return contourPaths(modules, opts, full), nil
+	}
+	return rasterPaths(modules, opts, full), nil
+}
+
+// GCode returns the toolpaths as G-code for laser engraver in GRBL laser mode:
+// laser is turned on with M4, rapid moves go with it off
+func GCode(modules [][]bool, opts LaserOptions) (string, error) {
+	paths, err := Toolpaths(modules, opts)
+	if err != nil {
+		return "", err
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("; qr-tools laser toolpath\nG21\nG90\nM4 S0\n")
+	for _, path := range paths {
+		fmt.Fprintf(&sb, "G0 X%s Y%s\n", num(path[0].X), num(path[0].Y))
+		for k, p := range path[1:] {
+			if k == 0 {
+				fmt.Fprintf(&sb, "G1 X%s Y%s F%s S%d\n", num(p.X), num(p.Y), num(opts.Feed), opts.Power)
+			} else {
+				fmt.Fprintf(&sb, "G1 X%s Y%s\n", num(p.X), num(p.Y))
+			}
+		}
+	}
+	sb.WriteString("M5\nG0 X0 Y0\n")
+
+	return sb.String(), nil
+}
+
+// LaserSVG returns the toolpaths as hairline strokes of SVG document in millimetres,
+// it's meant for laser cutter software that engraves along the strokes
+func LaserSVG(modules [][]bool, opts LaserOptions) (string, error) {
+	paths, err := Toolpaths(modules, opts)
+	if err != nil {
+		return "", err
+	}
+
+	full := float64(len(modules)+2*opts.QuietZone) * opts.Module
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" viewBox="0 0 %s %s" width="%smm" height="%smm">`,
+		num(full), num(full), num(full), num(full))
+	sb.WriteString(`<path fill="none" stroke="#ff0000" stroke-width="0.01" d="`)
+	for _, path := range paths {
+		// y goes down in SVG
+		for k, p := range path {
+			if k == 0 {
+				sb.WriteString("M")
+			} else {
+				sb.WriteString("L")
+			}
+			sb.WriteString(num(p.X) + " " + num(full-p.Y))
+		}
+	}
+	sb.WriteString(`"/></svg>`)
+
+	return sb.String(), nil
+}
+
+// WriteGCode builds the toolpaths and writes them as G-code
+func WriteGCode(w io.Writer, modules [][]bool, opts LaserOptions) error {
+	gcode, err := GCode(modules, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, gcode)
+	return err
+}
+
+// WriteLaserSVG builds the toolpaths and writes them as SVG
+func WriteLaserSVG(w io.Writer, modules [][]bool, opts LaserOptions) error {
+	svg, err := LaserSVG(modules, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, svg)
+	return err
+}
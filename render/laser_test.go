@@ -0,0 +1,144 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// hasPath tells if paths contain path
+func hasPath(paths []Path, path Path) bool {
+	for _, p := range paths {
+		if len(p) != len(path) {
+			continue
+		}
+		equal := true
+		for i := range p {
+			equal = equal && p[i] == path[i]
+		}
+		if equal {
+			return true
+		}
+	}
+	return false
+}
+
+func TestToolpaths_Raster(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	x, y := findModule(t, modules, pair)
+	opts := DefaultLaserOptions()
+	opts.Lines = 2
+
+	paths, err := Toolpaths(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to build toolpaths: %v", err)
+	}
+	if len(paths) != 2*len(runs(modules)) {
+		t.Errorf("Got %d paths instead of two for each of %d runs", len(paths), len(runs(modules)))
+	}
+
+	// row of the pair is from top to top - 1 mm, lines go back and forth
+	left, right, top := float64(x+4), float64(x+6), float64(29-4-y)
+	for _, expected := range []Path{{{left, top - 0.25}, {right, top - 0.25}}, {{right, top - 0.75}, {left, top - 0.75}}} {
+		if !hasPath(paths, expected) {
+			t.Errorf("Paths don't contain %v: %v", expected, paths)
+		}
+	}
+}
+
+func TestToolpaths_Contour(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	opts := DefaultLaserOptions()
+	opts.Toolpath = ContourToolpath
+
+	paths, err := Toolpaths(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to build toolpaths: %v", err)
+	}
+
+	// contours are closed, go clockwise around dark areas and counterclockwise around holes,
+	// so together they enclose exactly the dark modules
+	area, dark := 0.0, 0
+	for i, p := range paths {
+		if len(p) < 5 || p[0] != p[len(p)-1] {
+			t.Errorf("Contour %d is %v", i, p)
+		}
+		for k := 0; k+1 < len(p); k++ {
+			area += p[k].X*p[k+1].Y - p[k+1].X*p[k].Y
+		}
+	}
+	for _, row := range modules {
+		for _, d := range row {
+			if d {
+				dark++
+			}
+		}
+	}
+	if -area/2 != float64(dark) {
+		t.Errorf("Contours enclose %v square millimetres instead of %d", -area/2, dark)
+	}
+
+	x, y := findModule(t, modules, func(dark func(x, y int) bool, x, y int) bool {
+		return single(dark, x, y) && !dark(x-1, y-1) && !dark(x+1, y-1) && !dark(x-1, y+1) && !dark(x+1, y+1)
+	})
+	left, top := float64(x+4), float64(29-4-y)
+	expected := Path{{left, top}, {left + 1, top}, {left + 1, top - 1}, {left, top - 1}, {left, top}}
+	if !hasPath(paths, expected) {
+		t.Errorf("Contour of single module %v isn't found: %v", expected, paths)
+	}
+}
+
+func TestGCode(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	x, y := findModule(t, modules, single)
+	left, top := float64(x+4), float64(29-4-y)
+	opts := DefaultLaserOptions()
+	opts.Lines = 2
+
+	gcode, err := GCode(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to write G-code: %v", err)
+	}
+	for _, part := range []string{"G21\nG90\nM4 S0\n",
+		fmt.Sprintf("G0 X%s Y%s\nG1 X%s Y%s F1000 S1000\n", num(left), num(top-0.25), num(left+1), num(top-0.25)),
+		fmt.Sprintf("G0 X%s Y%s\nG1 X%s Y%s F1000 S1000\n", num(left+1), num(top-0.75), num(left), num(top-0.75))} {
+		if !strings.Contains(gcode, part) {
+			t.Errorf("G-code doesn't contain %q: %s", part, gcode)
+		}
+	}
+	if !strings.HasSuffix(gcode, "M5\nG0 X0 Y0\n") {
+		t.Errorf("G-code doesn't turn the laser off: %s", gcode)
+	}
+
+	buf := bytes.Buffer{}
+	if err := WriteGCode(&buf, modules, opts); err != nil || buf.String() != gcode {
+		t.Errorf("WriteGCode differs from GCode with %v", err)
+	}
+
+	opts.Feed = 0
+	if _, err := GCode(modules, opts); err != WrongDimensionsError {
+		t.Errorf("Zero feed is accepted with %v", err)
+	}
+}
+
+func TestLaserSVG(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	x, y := findModule(t, modules, func(dark func(x, y int) bool, x, y int) bool {
+		return single(dark, x, y) && !dark(x-1, y-1) && !dark(x+1, y-1) && !dark(x-1, y+1) && !dark(x+1, y+1)
+	})
+	left, top := 2*(x+4), 2*(y+4)
+	opts := DefaultLaserOptions()
+	opts.Toolpath, opts.Module = ContourToolpath, 2
+
+	svg, err := LaserSVG(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to write SVG: %v", err)
+	}
+	for _, part := range []string{`viewBox="0 0 58 58" width="58mm" height="58mm"`, `stroke="#ff0000"`,
+		fmt.Sprintf(`M%d %dL%d %dL%d %dL%d %dL%d %d`, left, top, left+2, top, left+2, top+2, left, top+2, left, top)} {
+		if !strings.Contains(svg, part) {
+			t.Errorf("SVG doesn't contain %s: %s", part, svg)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"github.com/rinnothing/qr-tools/logo"
+)
+
+// Logo is put over the center of the matrix: modules covered by Area are cleared and Picture is drawn there,
+// logo.Choose gives version and level that leave enough error correction for the area
+type Logo struct {
+	Area logo.Area
+	// Picture is scaled into the bounds of cleared modules keeping its proportions,
+	// it's drawn by Image and SVG; nil leaves the area empty
+	Picture image.Image
+}
+
+// primitives returns light runs of modules covered by the area in the matrix with size modules in a side
+func (l *Logo) primitives(size int) []primitive {
+	shapes := make([]primitive, 0)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; {
+			if !l.Area.Covers(x, y, size) {
+				x++
+				continue
+			}
+			start := x
+			for x < size && l.Area.Covers(x, y, size) {
+				x++
+			}
+			shapes = append(shapes, primitive{x: float64(start), y: float64(y), w: float64(x - start), h: 1, overlay: true, light: true})
+		}
+	}
+	return shapes
+}
+
+// bounds returns the place of the picture in module units: the bounds of covered modules
+// with the proportions of the picture, it's empty if there's nothing to draw
+func (l *Logo) bounds(size int) (x, y, w, h float64) {
+	if l.Picture == nil || l.Picture.Bounds().Empty() {
+		return 0, 0, 0, 0
+	}
+
+	x0, y0, x1, y1 := size, size, 0, 0
+	for my := 0; my < size; my++ {
+		for mx := 0; mx < size; mx++ {
+			if l.Area.Covers(mx, my, size) {
+				x0, y0, x1, y1 = min(x0, mx), min(y0, my), max(x1, mx+1), max(y1, my+1)
+			}
+		}
+	}
+	if x0 >= x1 || y0 >= y1 {
+		return 0, 0, 0, 0
+	}
+
+	pw, ph := float64(l.Picture.Bounds().Dx()), float64(l.Picture.Bounds().Dy())
+	scale := min(float64(x1-x0)/pw, float64(y1-y0)/ph)
+	w, h = pw*scale, ph*scale
+	return float64(x0+x1)/2 - w/2, float64(y0+y1)/2 - h/2, w, h
+}
+
+// drawPicture draws the picture of logo on the picture of matrix with size modules in a side,
+// it's scaled with nearest neighbour
+func (l *Logo) drawPicture(img *image.RGBA, opts Options, size int) {
+	x, y, w, h := l.bounds(size)
+	if w == 0 {
+		return
+	}
+
+	quietZone, scale := float64(opts.QuietZone), float64(opts.Scale)
+	dst := image.Rect(int((x+quietZone)*scale), int((y+quietZone)*scale), int((x+w+quietZone)*scale), int((y+h+quietZone)*scale))
+	src := l.Picture.Bounds()
+	scaled := image.NewRGBA(dst)
+	for py := dst.Min.Y; py < dst.Max.Y; py++ {
+		for px := dst.Min.X; px < dst.Max.X; px++ {
+			sx := src.Min.X + (px-dst.Min.X)*src.Dx()/dst.Dx()
+			sy := src.Min.Y + (py-dst.Min.Y)*src.Dy()/dst.Dy()
+			scaled.Set(px, py, l.Picture.At(sx, sy))
+		}
+	}
+	draw.Draw(img, dst, scaled, dst.Min, draw.Over)
+}
+
+// writeSVGPicture writes the picture of logo as PNG image element in module units
+func (l *Logo) writeSVGPicture(sb *strings.Builder, size int) error {
+	x, y, w, h := l.bounds(size)
+	if w == 0 {
+		return nil
+	}
+
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, l.Picture); err != nil {
+		return err
+	}
+	fmt.Fprintf(sb, `<image x="%s" y="%s" width="%s" height="%s" href="data:image/png;base64,%s"/>`,
+		num(x), num(y), num(w), num(h), base64.StdEncoding.EncodeToString(buf.Bytes()))
+	return nil
+}
+
+// Swiss cross is 7 mm in a side on 46 mm code, its black square has 0.5 mm white border
+// and the cross in it has the proportions of the Swiss flag: arms are 6 and the cross is 20 of 32 units
+const (
+	swissCrossSide   = 7.0 / 46
+	swissCrossBorder = 0.5 / 7
+)
+
+// swissCross returns the cross of Swiss QR-bill in the center of matrix with size modules in a side:
+// light square that clears the modules under it, dark square and light cross
+func swissCross(size int) []primitive {
+	side := swissCrossSide * float64(size)
+	corner := (float64(size) - side) / 2
+	inset := side * swissCrossBorder
+	unit := (side - 2*inset) / 32
+	center := float64(size) / 2
+
+	return []primitive{
+		{x: corner, y: corner, w: side, h: side, overlay: true, light: true},
+		{x: corner + inset, y: corner + inset, w: side - 2*inset, h: side - 2*inset, overlay: true},
+		{x: center - 3*unit, y: center - 10*unit, w: 6 * unit, h: 20 * unit, overlay: true, light: true},
+		{x: center - 10*unit, y: center - 3*unit, w: 20 * unit, h: 6 * unit, overlay: true, light: true},
+	}
+}
+
+// overlayPrimitives returns the shapes drawn over the matrix with size modules in a side, in drawing order
+func (o Options) overlayPrimitives(size int) []primitive {
+	shapes := make([]primitive, 0)
+	if o.Logo != nil && o.Logo.Area != nil {
+		shapes = append(shapes, o.Logo.primitives(size)...)
+	}
+	if o.SwissCross {
+		shapes = append(shapes, swissCross(size)...)
+	}
+	return shapes
+}
+
+// covered tells if the center of module (x, y) is hidden by the overlay
+func covered(overlay []primitive, x, y int) bool {
+	for _, p := range overlay {
+		if p.contains(float64(x)+0.5, float64(y)+0.5) {
+			return true
+		}
+	}
+	return false
+}
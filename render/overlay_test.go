@@ -0,0 +1,104 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/rinnothing/qr-tools/logo"
+)
+
+func TestSwissCross(t *testing.T) {
+	modules := testCode(t, 10, "SPC\n0200\n1\nCH4431999123000889012")
+	opts := DefaultOptions()
+	opts.Scale, opts.SwissCross = 10, true
+
+	img, err := Image(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	at := func(x, y float64) bool {
+		s := float64(opts.Scale)
+		return img.RGBAAt(int((x+float64(opts.QuietZone))*s), int((y+float64(opts.QuietZone))*s)) == black
+	}
+
+	cases := []struct {
+		name     string
+		x, y     float64
+		expected bool
+	}{
+		{"cross", 28.5, 28.5, false},
+		{"arm", 28.5, 26.5, false},
+		{"square", 25.9, 25.9, true},
+		{"border", 24.4, 28.5, false},
+		{"module outside", 22.5, 28.5, modules[28][22]},
+	}
+	for _, c := range cases {
+		if dark := at(c.x, c.y); dark != c.expected {
+			t.Errorf("%s of Swiss cross is dark: %v instead of %v", c.name, dark, c.expected)
+		}
+	}
+
+	// modules under the cross are left to error correction
+	if err := Validate(modules, opts); err != nil {
+		t.Errorf("Swiss cross is validated with %v", err)
+	}
+
+	svg, err := SVG(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if !strings.Contains(svg, `<path fill="#ffffff" d="M24.163 24.163H32.837V32.837H24.163V24.163Z"/>`) {
+		t.Errorf("SVG doesn't clear the area of Swiss cross: %s", svg)
+	}
+
+	pdf, err := PDF([]Label{{Modules: modules}}, PDFOptions{Options: opts, Sheet: SinglePage(A4, 50)})
+	if err != nil || len(pdf) == 0 {
+		t.Errorf("Failed to print Swiss cross: %v", err)
+	}
+}
+
+func TestLogo(t *testing.T) {
+	modules := testCode(t, 5, "https://example.com/logo")
+	opts := DefaultOptions()
+	opts.Logo = &Logo{Area: logo.CenteredRect(0.2, 0.2)}
+
+	// modules 14..22 are cleared, the rest is left
+	if c := module(t, modules, opts, 18, 15, 0.5, 0.5); c != white {
+		t.Errorf("Module under the logo is %v", c)
+	}
+	if c := module(t, modules, opts, 13, 18, 0.5, 0.5); (c == black) != modules[18][13] {
+		t.Errorf("Module beside the logo is %v", c)
+	}
+	if err := Validate(modules, opts); err != nil {
+		t.Errorf("Cleared logo area is validated with %v", err)
+	}
+
+	// 2 × 1 picture is fitted into 9 × 9 modules, so it's 4.5 modules high
+	picture := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	picture.Set(0, 0, red)
+	picture.Set(1, 0, color.RGBA{G: 255, A: 255})
+	opts.Logo.Picture = picture
+	cases := []struct {
+		x, y     int
+		expected color.RGBA
+	}{
+		{15, 18, red},
+		{21, 18, color.RGBA{G: 255, A: 255}},
+		{18, 15, white},
+	}
+	for _, c := range cases {
+		if got := module(t, modules, opts, c.x, c.y, 0.5, 0.5); got != c.expected {
+			t.Errorf("Module (%d, %d) of the logo is %v instead of %v", c.x, c.y, got, c.expected)
+		}
+	}
+
+	svg, err := SVG(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if !strings.Contains(svg, `<image x="14" y="16.25" width="9" height="4.5" href="data:image/png;base64,`) {
+		t.Errorf("SVG doesn't contain the picture: %s", svg)
+	}
+}
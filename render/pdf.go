@@ -0,0 +1,268 @@
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+var (
+	NoLabelsError = errors.New("there are no labels to print")
+)
+
+// ptPerMM is the number of PDF points in a millimetre
+const ptPerMM = 72 / mmPerInch
+
+// kappa is the distance of Bézier control points for a quarter of unit circle
+const kappa = 0.5522847498
+
+// crop marks are drawn this far from the label and are this long, in millimetres
+const (
+	cropOffset = 1
+	cropLength = 4
+)
+
+// Label is a single matrix printed on the sheet
+type Label struct {
+	Modules [][]bool
+	// Caption is drawn under the matrix, it's used instead of Options.Caption
+	Caption string
+}
+
+// PDFOptions are the options of PDF document
+type PDFOptions struct {
+	// Options give Style, QuietZone, SwissCross and Logo area of the matrices, Scale, Caption and Logo picture are ignored;
+	// gradients are drawn with their From color and transparency is ignored
+	Options
+	Sheet Sheet
+	// CropMarks are drawn around every label
+	CropMarks bool
+}
+
+// DefaultPDFOptions returns options with DefaultOptions and a single 50 mm code on A4 page
+func DefaultPDFOptions() PDFOptions {
+	return PDFOptions{Options: DefaultOptions(), Sheet: SinglePage(A4, 50)}
+}
+
+// writePDFPath appends subpath of the primitive (and of its hole) to sb
+func (p primitive) writePDFPath(sb *strings.Builder) {
+	r := p.radii
+	if r == [4]float64{} {
+		fmt.Fprintf(sb, "%s %s %s %s re\n", num(p.x), num(p.y), num(p.w), num(p.h))
+	} else {
+		curve := func(x1, y1, x2, y2, x3, y3 float64) {
+			fmt.Fprintf(sb, "%s %s %s %s %s %s c\n", num(x1), num(y1), num(x2), num(y2), num(x3), num(y3))
+		}
+		line := func(x, y float64) {
+			fmt.Fprintf(sb, "%s %s l\n", num(x), num(y))
+		}
+
+		fmt.Fprintf(sb, "%s %s m\n", num(p.x+r[0]), num(p.y))
+		line(p.x+p.w-r[1], p.y)
+		if r[1] > 0 {
+			curve(p.x+p.w-r[1]+kappa*r[1], p.y, p.x+p.w, p.y+r[1]-kappa*r[1], p.x+p.w, p.y+r[1])
+		}
+		line(p.x+p.w, p.y+p.h-r[2])
+		if r[2] > 0 {
+			curve(p.x+p.w, p.y+p.h-r[2]+kappa*r[2], p.x+p.w-r[2]+kappa*r[2], p.y+p.h, p.x+p.w-r[2], p.y+p.h)
+		}
+		line(p.x+r[3], p.y+p.h)
+		if r[3] > 0 {
+			curve(p.x+r[3]-kappa*r[3], p.y+p.h, p.x, p.y+p.h-r[3]+kappa*r[3], p.x, p.y+p.h-r[3])
+		}
+		line(p.x, p.y+r[0])
+		if r[0] > 0 {
+			curve(p.x, p.y+r[0]-kappa*r[0], p.x+r[0]-kappa*r[0], p.y, p.x+r[0], p.y)
+		}
+		sb.WriteString("h\n")
+	}
+
+	if p.hole != nil {
+		p.hole.writePDFPath(sb)
+	}
+}
+
+// pdfColor returns fill color operator of c
+func pdfColor(c color.RGBA) string {
+	return fmt.Sprintf("%s %s %s rg\n", num(float64(c.R)/255), num(float64(c.G)/255), num(float64(c.B)/255))
+}
+
+// writeLabel draws the label fitted into the box with top left corner at (x, y) in millimetres
+func writeLabel(sb *strings.Builder, label Label, opts PDFOptions, x, y float64) error {
+	shapes, err := primitives(label.Modules, opts.Style)
+	if err != nil {
+		return err
+	}
+
+	size := len(label.Modules)
+	o := opts.Options
+	o.Caption = label.Caption
+	width, height := o.bounds(size)
+
+	// matrix is drawn in module units with its top left corner at the origin, like in SVG
+	module := min(opts.Sheet.Width/float64(width), opts.Sheet.Height/float64(height))
+	x += (opts.Sheet.Width-module*float64(width))/2 + module*float64(o.QuietZone)
+	y += (opts.Sheet.Height-module*float64(height))/2 + module*float64(o.QuietZone)
+	fmt.Fprintf(sb, "q\n%s 0 0 %s %s %s cm\n", num(module), num(module), num(x), num(y))
+
+	if bg := opts.Style.Background; bg.A != 0 {
+		sb.WriteString(pdfColor(bg))
+		fmt.Fprintf(sb, "%d %d %d %d re\nf\n", -o.QuietZone, -o.QuietZone, width, height)
+	}
+
+	eyes, rest := strings.Builder{}, strings.Builder{}
+	for _, p := range shapes {
+		if p.eye {
+			p.writePDFPath(&eyes)
+		} else {
+			p.writePDFPath(&rest)
+		}
+	}
+	sb.WriteString(pdfColor(opts.Style.fillOf(primitive{eye: true}).From))
+	sb.WriteString(eyes.String() + "f*\n")
+
+	sb.WriteString(pdfColor(opts.Style.Foreground.From))
+	if rest.Len() != 0 {
+		sb.WriteString(rest.String() + "f\n")
+	}
+	for _, p := range o.overlayPrimitives(size) {
+		sb.WriteString(pdfColor(opts.Style.fillOf(p).From))
+		p.writePDFPath(sb)
+		sb.WriteString("f\n")
+	}
+	sb.WriteString(pdfColor(opts.Style.Foreground.From))
+	if caption := o.captionPrimitives(size); len(caption) != 0 {
+		for _, p := range caption {
+			p.writePDFPath(sb)
+		}
+		sb.WriteString("f\n")
+	}
+
+	sb.WriteString("Q\n")
+	return nil
+}
+
+// writeCropMarks draws short lines at the corners of the label with top left corner at (x, y)
+func writeCropMarks(sb *strings.Builder, x, y, w, h float64) {
+	line := func(x0, y0, x1, y1 float64) {
+		fmt.Fprintf(sb, "%s %s m\n%s %s l\n", num(x0), num(y0), num(x1), num(y1))
+	}
+	for _, cx := range []float64{x, x + w} {
+		for _, cy := range []float64{y, y + h} {
+			// marks go outwards from the corner
+			dx, dy := 1.0, 1.0
+			if cx == x {
+				dx = -1
+			}
+			if cy == y {
+				dy = -1
+			}
+			line(cx+dx*cropOffset, cy, cx+dx*(cropOffset+cropLength), cy)
+			line(cx, cy+dy*cropOffset, cx, cy+dy*(cropOffset+cropLength))
+		}
+	}
+}
+
+// pdfPage returns content stream of a single page with labels put into sheet from the start
+func pdfPage(labels []Label, opts PDFOptions) (string, error) {
+	sb := strings.Builder{}
+	// content is drawn in millimetres from the top left corner of the page
+	fmt.Fprintf(&sb, "%s 0 0 %s 0 %s cm\n", num(ptPerMM), num(-ptPerMM), num(opts.Sheet.Page.Height*ptPerMM))
+
+	for i, label := range labels {
+		x, y := opts.Sheet.Label(i)
+		if err := writeLabel(&sb, label, opts, x, y); err != nil {
+			return "", err
+		}
+	}
+
+	if opts.CropMarks {
+		sb.WriteString("0 0 0 RG\n0.1 w\n")
+		for i := range labels {
+			x, y := opts.Sheet.Label(i)
+			writeCropMarks(&sb, x, y, opts.Sheet.Width, opts.Sheet.Height)
+		}
+		sb.WriteString("S\n")
+	}
+
+	return sb.String(), nil
+}
+
+// PDF returns document with labels put into the sheet row by row,
+// new page is started when the sheet is full
+func PDF(labels []Label, opts PDFOptions) ([]byte, error) {
+	if len(labels) == 0 {
+		return nil, NoLabelsError
+	}
+	if err := opts.Sheet.check(); err != nil {
+		return nil, err
+	}
+	opts.Scale = 1
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+
+	perPage := opts.Sheet.Labels()
+	pages := (len(labels) + perPage - 1) / perPage
+
+	buf := bytes.Buffer{}
+	offsets := make([]int, 0, 2+2*pages)
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// catalog and page tree go first, every page is followed by its content
+	kids := make([]string, pages)
+	for i := range kids {
+		kids[i] = fmt.Sprintf("%d 0 R", 3+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pages))
+
+	for i := 0; i < pages; i++ {
+		content, err := pdfPage(labels[i*perPage:min(len(labels), (i+1)*perPage)], opts)
+		if err != nil {
+			return nil, err
+		}
+
+		compressed := bytes.Buffer{}
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write([]byte(content)); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << >> /Contents %d 0 R >>",
+			num(opts.Sheet.Page.Width*ptPerMM), num(opts.Sheet.Page.Height*ptPerMM), 4+2*i))
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes(), nil
+}
+
+// WritePDF draws the labels and writes them as PDF
+func WritePDF(w io.Writer, labels []Label, opts PDFOptions) error {
+	pdf, err := PDF(labels, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(pdf)
+	return err
+}
@@ -0,0 +1,136 @@
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// pdfContents checks cross-reference table of the document and returns its decompressed content streams
+func pdfContents(t *testing.T, pdf []byte) []string {
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf("Document isn't framed as PDF")
+	}
+
+	start := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	if start == nil {
+		t.Fatalf("Document has no startxref")
+	}
+	xref, _ := strconv.Atoi(string(start[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref doesn't point to xref table")
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1)
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(string(e[1]))
+		if !bytes.HasPrefix(pdf[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))) {
+			t.Errorf("Object %d isn't at offset %d", i+1, offset)
+		}
+	}
+
+	contents := make([]string, 0)
+	for _, m := range regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n`).FindAllSubmatchIndex(pdf, -1) {
+		length, _ := strconv.Atoi(string(pdf[m[2]:m[3]]))
+		stream := pdf[m[1] : m[1]+length]
+		if !bytes.HasPrefix(pdf[m[1]+length:], []byte("\nendstream")) {
+			t.Errorf("Stream length %d is wrong", length)
+		}
+
+		r, err := zlib.NewReader(bytes.NewReader(stream))
+		if err != nil {
+			t.Fatalf("Failed to decompress content: %v", err)
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Failed to decompress content: %v", err)
+		}
+		contents = append(contents, string(content))
+	}
+	return contents
+}
+
+func TestPDF(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	x, y := findModule(t, modules, single)
+	opts := DefaultPDFOptions()
+
+	pdf, err := PDF([]Label{{Modules: modules}}, opts)
+	if err != nil {
+		t.Fatalf("Failed to write PDF: %v", err)
+	}
+	contents := pdfContents(t, pdf)
+	if len(contents) != 1 || !bytes.Contains(pdf, []byte("/Count 1")) {
+		t.Fatalf("Single code document has %d pages", len(contents))
+	}
+	if !bytes.Contains(pdf, []byte("/MediaBox [0 0 595.276 841.89]")) {
+		t.Errorf("Page isn't A4")
+	}
+
+	// 50 mm are 29 modules, the code is in the center of the page
+	module := 50.0 / 29
+	for _, part := range []string{
+		fmt.Sprintf("%s 0 0 %s %s %s cm\n", num(module), num(module), num(80+4*module), num(123.5+4*module)),
+		"1 1 1 rg\n-4 -4 29 29 re\nf\n",
+		"0 0 0 rg\n0 0 7 7 re\n1 1 5 5 re\n",
+		fmt.Sprintf("\n%d %d 1 1 re\n", x, y),
+	} {
+		if !strings.Contains(contents[0], part) {
+			t.Errorf("Content doesn't contain %q: %s", part, contents[0])
+		}
+	}
+	if strings.Contains(contents[0], " S\n") || strings.Contains(contents[0], "RG") {
+		t.Errorf("Crop marks are drawn without CropMarks")
+	}
+
+	opts.Style.Shape = DotShape
+	opts.CropMarks = true
+	pdf, _ = PDF([]Label{{Modules: modules, Caption: "A1"}}, opts)
+	contents = pdfContents(t, pdf)
+	for _, part := range []string{" c\n", "h\n", "0.1 w\n", "79 123.5 m\n75 123.5 l\n", "S\n"} {
+		if !strings.Contains(contents[0], part) {
+			t.Errorf("Content doesn't contain %q: %s", part, contents[0])
+		}
+	}
+}
+
+func TestPDF_Sheet(t *testing.T) {
+	modules := testCode(t, 2, "https://example.com/qr")
+	opts := DefaultPDFOptions()
+	opts.Sheet = AveryL7160
+
+	labels := make([]Label, 25)
+	for i := range labels {
+		labels[i] = Label{Modules: modules, Caption: fmt.Sprintf("BOX %d", i)}
+	}
+	pdf, err := PDF(labels, opts)
+	if err != nil {
+		t.Fatalf("Failed to write PDF: %v", err)
+	}
+	contents := pdfContents(t, pdf)
+	if len(contents) != 2 || !bytes.Contains(pdf, []byte("/Count 2")) {
+		t.Fatalf("25 labels are put on %d pages instead of 2", len(contents))
+	}
+	if n := strings.Count(contents[0], "q\n"); n != 21 {
+		t.Errorf("First page has %d labels instead of 21", n)
+	}
+	if n := strings.Count(contents[1], "q\n"); n != 4 {
+		t.Errorf("Second page has %d labels instead of 4", n)
+	}
+
+	if _, err := PDF(nil, opts); err != NoLabelsError {
+		t.Errorf("Empty document is written with %v", err)
+	}
+	opts.Sheet.Columns = 4
+	if _, err := PDF(labels, opts); err != WrongSheetError {
+		t.Errorf("Sheet wider than page is accepted with %v", err)
+	}
+	opts.Sheet = AveryL7160
+	if _, err := PDF([]Label{{Modules: modules[1:]}}, opts); err != WrongSizeError {
+		t.Errorf("Wrong matrix is written with %v", err)
+	}
+}
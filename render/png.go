@@ -0,0 +1,68 @@
+package render
+
+import (
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+)
+
+// drawPrimitive draws the primitive on the picture of matrix with size modules in a side
+// shapes don't leave their bounding boxes, so every one of them is drawn only there
+func drawPrimitive(img *image.RGBA, p primitive, opts Options, size int) {
+	fill := opts.Style.fillOf(p)
+	quietZone := float64(opts.QuietZone)
+
+	x0 := int(math.Floor((p.x + quietZone) * float64(opts.Scale)))
+	y0 := int(math.Floor((p.y + quietZone) * float64(opts.Scale)))
+	x1 := int(math.Ceil((p.x + p.w + quietZone) * float64(opts.Scale)))
+	y1 := int(math.Ceil((p.y + p.h + quietZone) * float64(opts.Scale)))
+	for py := y0; py < y1; py++ {
+		for px := x0; px < x1; px++ {
+			// pixel center in module units
+			mx := (float64(px)+0.5)/float64(opts.Scale) - quietZone
+			my := (float64(py)+0.5)/float64(opts.Scale) - quietZone
+			if p.contains(mx, my) {
+				img.SetRGBA(px, py, fill.at(mx, my, float64(size)))
+			}
+		}
+	}
+}
+
+// Image draws the matrix with quiet zone, overlay and caption
+func Image(modules [][]bool, opts Options) (*image.RGBA, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+	shapes, err := primitives(modules, opts.Style)
+	if err != nil {
+		return nil, err
+	}
+
+	size := len(modules)
+	shapes = append(shapes, opts.overlayPrimitives(size)...)
+	shapes = append(shapes, opts.captionPrimitives(size)...)
+	width, height := opts.bounds(size)
+	img := image.NewRGBA(image.Rect(0, 0, width*opts.Scale, height*opts.Scale))
+
+	draw.Draw(img, img.Bounds(), image.NewUniform(opts.Style.Background), image.Point{}, draw.Src)
+	for _, p := range shapes {
+		drawPrimitive(img, p, opts, size)
+	}
+	if opts.Logo != nil && opts.Logo.Area != nil {
+		opts.Logo.drawPicture(img, opts, size)
+	}
+
+	return img, nil
+}
+
+// WritePNG draws the matrix and writes it as PNG
+func WritePNG(w io.Writer, modules [][]bool, opts Options) error {
+	img, err := Image(modules, opts)
+	if err != nil {
+		return err
+	}
+
+	return png.Encode(w, img)
+}
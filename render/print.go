@@ -0,0 +1,83 @@
+package render
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	WrongDPIError    = errors.New("dpi must be positive")
+	WrongWidthError  = errors.New("physical width must be positive")
+	TooSmallError    = errors.New("physical width is less than a pixel per module")
+	SmallModuleError = errors.New("module is too small for the scanning distance")
+)
+
+const mmPerInch = 25.4
+
+// DistanceRatio is the ratio of scanning distance to the width of the code with quiet zone
+// that phone cameras still read reliably
+const DistanceRatio = 10
+
+// MinPrintModule is the smallest module size in millimetres that is printed reliably
+const MinPrintModule = 0.25
+
+// Layout is the physical size of the rendered matrix
+type Layout struct {
+	// Scale is the integral size of module in pixels, it's meant to be used as Options.Scale
+	Scale int
+	// Module is the actual size of module in millimetres
+	Module float64
+	// Width and Height are the actual sizes of the picture in millimetres, quiet zone and caption included
+	Width, Height float64
+	// Modules is the number of modules in the width of the picture
+	Modules int
+}
+
+// PrintLayout chooses the largest integral module size in pixels that keeps the picture
+// (quiet zone included) not wider than width millimetres at chosen dpi, Scale of opts is ignored
+// throws TooSmallError if even a pixel per module is too wide
+func PrintLayout(modules [][]bool, opts Options, width float64, dpi int) (*Layout, error) {
+	if dpi <= 0 {
+		return nil, WrongDPIError
+	}
+	if width <= 0 {
+		return nil, WrongWidthError
+	}
+	opts.Scale = 1
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+	if _, err := version(modules); err != nil {
+		return nil, err
+	}
+
+	wide, high := opts.bounds(len(modules))
+	scale := int(math.Floor(width / mmPerInch * float64(dpi) / float64(wide)))
+	if scale == 0 {
+		return nil, TooSmallError
+	}
+
+	module := float64(scale) * mmPerInch / float64(dpi)
+	return &Layout{
+		Scale:   scale,
+		Module:  module,
+		Width:   module * float64(wide),
+		Height:  module * float64(high),
+		Modules: wide,
+	}, nil
+}
+
+// MinModuleSize returns the smallest module size in millimetres for the code that is modules wide
+// (quiet zone included) to be read from distance millimetres away, it's never less than MinPrintModule
+func MinModuleSize(distance float64, modules int) float64 {
+	return math.Max(MinPrintModule, distance/DistanceRatio/float64(modules))
+}
+
+// CheckDistance returns SmallModuleError if the code can't be reliably read from distance millimetres away
+// or if its modules are smaller than MinPrintModule
+func (l *Layout) CheckDistance(distance float64) error {
+	if l.Module < MinModuleSize(distance, l.Modules) {
+		return SmallModuleError
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package render
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPrintLayout(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	opts := DefaultOptions()
+
+	cases := []struct {
+		width    float64
+		dpi      int
+		scale    int
+		expected error
+	}{
+		// 29 modules in 1 inch at 300 dpi are 10.34 pixels each
+		{25.4, 300, 10, nil},
+		{25.4, 600, 20, nil},
+		{50, 72, 4, nil},
+		{10, 72, 0, TooSmallError},
+		{25.4, 0, 0, WrongDPIError},
+		{-1, 300, 0, WrongWidthError},
+	}
+
+	for _, c := range cases {
+		l, err := PrintLayout(modules, opts, c.width, c.dpi)
+		if err != c.expected {
+			t.Errorf("Layout of %vmm at %d dpi gives %v instead of %v", c.width, c.dpi, err, c.expected)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if l.Scale != c.scale {
+			t.Errorf("Layout of %vmm at %d dpi has scale %d instead of %d", c.width, c.dpi, l.Scale, c.scale)
+		}
+		if module := float64(c.scale) * 25.4 / float64(c.dpi); math.Abs(l.Module-module) > 1e-9 {
+			t.Errorf("Module of %vmm at %d dpi is %vmm instead of %vmm", c.width, c.dpi, l.Module, module)
+		}
+		if l.Width > c.width || l.Modules != 29 {
+			t.Errorf("Layout of %vmm at %d dpi is %vmm and %d modules wide", c.width, c.dpi, l.Width, l.Modules)
+		}
+	}
+
+	opts.Caption = "HELLO"
+	l, err := PrintLayout(modules, opts, 25.4, 300)
+	if err != nil {
+		t.Fatalf("Failed to lay out: %v", err)
+	}
+	if math.Abs(l.Height-l.Module*34) > 1e-9 {
+		t.Errorf("Layout with caption is %vmm high instead of %vmm", l.Height, l.Module*34)
+	}
+}
+
+func TestLayout_CheckDistance(t *testing.T) {
+	// 29 modules of 1mm
+	l := &Layout{Scale: 12, Module: 1, Width: 29, Height: 29, Modules: 29}
+
+	cases := []struct {
+		distance float64
+		expected error
+	}{
+		{0, nil},
+		{290, nil},
+		{300, SmallModuleError},
+	}
+	for _, c := range cases {
+		if err := l.CheckDistance(c.distance); err != c.expected {
+			t.Errorf("Check of %vmm distance gives %v instead of %v", c.distance, err, c.expected)
+		}
+	}
+
+	if err := (&Layout{Module: 0.2, Modules: 29}).CheckDistance(0); err != SmallModuleError {
+		t.Errorf("Module smaller than MinPrintModule is accepted with %v", err)
+	}
+	if got := MinModuleSize(1000, 25); got != 4 {
+		t.Errorf("Minimum module for 1m and 25 modules is %v instead of 4", got)
+	}
+}
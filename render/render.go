@@ -0,0 +1,342 @@
+// Package render draws QR matrices as PNG, animated GIF, SVG, PDF, EPS, TikZ and HTML documents,
+// STL models and laser toolpaths
+//
+// matrix is given as modules indexed by row and column, true stands for dark module;
+// function patterns are found with qr_tools.FunctionPatterns, so they can be styled separately from data
+package render
+
+import (
+	"errors"
+	"image/color"
+	"math"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+var (
+	WrongSizeError  = errors.New("matrix size doesn't match any qr version")
+	WrongScaleError = errors.New("scale must be positive")
+	// WrongQuietZoneError is returned when quiet zone is negative
+	WrongQuietZoneError = errors.New("quiet zone can't be negative")
+)
+
+// MinQuietZone is the width of light border around the matrix in modules required by the standard
+const MinQuietZone = 4
+
+// Shape is enum that
+// shows how a single dark module is drawn
+type Shape int
+
+const (
+	SquareShape Shape = iota
+	// RoundedShape is a square with rounded corners
+	RoundedShape
+	// DotShape is a circle slightly smaller than the module
+	DotShape
+	// ConnectedShape rounds only the corners that don't touch other dark modules,
+	// so neighbouring modules merge into smooth blobs
+	ConnectedShape
+)
+
+// EyeShape is enum that
+// shows how frames and pupils of finder patterns ("eyes") are drawn
+type EyeShape int
+
+const (
+	SquareEye EyeShape = iota
+	RoundedEye
+	CircleEye
+)
+
+// FillKind is enum that
+// shows how dark modules are colored
+type FillKind int
+
+const (
+	SolidFill FillKind = iota
+	// LinearFill goes from From to To along the Angle
+	LinearFill
+	// RadialFill goes from From in the center to To in the corners
+	RadialFill
+)
+
+// Fill is the color of dark modules
+type Fill struct {
+	Kind FillKind
+	From color.RGBA
+	To   color.RGBA
+	// Angle of LinearFill in degrees, 0 goes from left to right, 90 from top to bottom
+	Angle float64
+}
+
+// Solid returns SolidFill of color c
+func Solid(c color.RGBA) Fill {
+	return Fill{Kind: SolidFill, From: c, To: c}
+}
+
+// Style describes how the matrix is drawn
+type Style struct {
+	// Shape is used for data modules
+	Shape Shape
+	// FunctionShape is used for timing and alignment patterns, format and version information
+	FunctionShape Shape
+	EyeFrame      EyeShape
+	EyePupil      EyeShape
+
+	Foreground Fill
+	// EyeFill is used for finder patterns, Foreground is used when it's nil
+	EyeFill    *Fill
+	Background color.RGBA
+}
+
+// DefaultStyle returns plain black on white style
+func DefaultStyle() Style {
+	return Style{
+		Foreground: Solid(color.RGBA{A: 255}),
+		Background: color.RGBA{R: 255, G: 255, B: 255, A: 255},
+	}
+}
+
+// Options are the options of rendering
+type Options struct {
+	// Scale is the size of module in pixels, PrintLayout may be used to get it from physical size
+	Scale int
+	// QuietZone is the width of light border around the matrix in modules, zero stands for MinQuietZone;
+	// narrower than MinQuietZone is allowed, but Validate reports it
+	QuietZone int
+	// NoQuietZone drops the light border, QuietZone is ignored then
+	NoQuietZone bool
+	Style       Style
+	// Caption is drawn under the quiet zone with built-in bitmap font if it isn't empty
+	Caption string
+	// SwissCross draws the cross of Swiss QR-bill over the center of the matrix,
+	// it's drawn by Image, SVG and PDF and the modules under it are left to error correction
+	SwissCross bool
+	// Logo clears the modules under its area, nil draws none
+	Logo *Logo
+}
+
+// DefaultOptions returns options with DefaultStyle, 8 pixels per module and MinQuietZone
+func DefaultOptions() Options {
+	return Options{Scale: 8, QuietZone: MinQuietZone, Style: DefaultStyle()}
+}
+
+// normalize validates scale and quiet zone and replaces QuietZone with the width that is drawn
+func (o *Options) normalize() error {
+	if o.Scale <= 0 {
+		return WrongScaleError
+	}
+	if o.QuietZone < 0 {
+		return WrongQuietZoneError
+	}
+
+	switch {
+	case o.NoQuietZone:
+		o.QuietZone = 0
+	case o.QuietZone == 0:
+		o.QuietZone = MinQuietZone
+	}
+	return nil
+}
+
+// bounds returns width and height of the picture in modules for matrix with size modules in a side,
+// picture starts at (-QuietZone, -QuietZone) in module units
+func (o Options) bounds(size int) (width, height int) {
+	width = size + 2*o.QuietZone
+	return width, width + captionHeight(o.Caption, float64(width))
+}
+
+// captionPrimitives returns the caption under the quiet zone of matrix with size modules in a side
+func (o Options) captionPrimitives(size int) []primitive {
+	width, _ := o.bounds(size)
+	return captionPrimitives(o.Caption, float64(-o.QuietZone), float64(size+o.QuietZone), float64(width))
+}
+
+// version returns the version of matrix and validates it's square
+func version(modules [][]bool) (qr_tools.QRVersion, error) {
+	size := len(modules)
+	if size < 21 || (size-17)%4 != 0 || size > 177 {
+		return 0, WrongSizeError
+	}
+	for _, row := range modules {
+		if len(row) != size {
+			return 0, WrongSizeError
+		}
+	}
+
+	return qr_tools.QRVersion((size - 17) / 4), nil
+}
+
+// primitive is a rectangle with rounded corners in module units (matrix starts at 0, 0)
+// circles are rectangles with all the radii equal to half of the side
+type primitive struct {
+	x, y, w, h float64
+	// radii of top left, top right, bottom right and bottom left corners
+	radii [4]float64
+	// hole is cut from the primitive, it's used for eye frames
+	hole *primitive
+	eye  bool
+	// caption primitives are always drawn with solid Foreground.From
+	caption bool
+	// overlay primitives are drawn over the matrix with solid Foreground.From or with Background if they're light
+	overlay, light bool
+}
+
+func roundedRect(x, y, w, h, r float64) primitive {
+	return primitive{x: x, y: y, w: w, h: h, radii: [4]float64{r, r, r, r}}
+}
+
+func circle(x, y, d float64) primitive {
+	return roundedRect(x, y, d, d, d/2)
+}
+
+// contains tells if point (px, py) is inside the primitive
+func (p primitive) contains(px, py float64) bool {
+	if px < p.x || px >= p.x+p.w || py < p.y || py >= p.y+p.h {
+		return false
+	}
+
+	// corners are checked against their circles
+	corners := [4][2]float64{
+		{p.x + p.radii[0], p.y + p.radii[0]},
+		{p.x + p.w - p.radii[1], p.y + p.radii[1]},
+		{p.x + p.w - p.radii[2], p.y + p.h - p.radii[2]},
+		{p.x + p.radii[3], p.y + p.h - p.radii[3]},
+	}
+	signs := [4][2]float64{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}
+	for i, c := range corners {
+		r := p.radii[i]
+		dx, dy := px-c[0], py-c[1]
+		if r > 0 && dx*signs[i][0] > 0 && dy*signs[i][1] > 0 && dx*dx+dy*dy > r*r {
+			return false
+		}
+	}
+
+	return p.hole == nil || !p.hole.contains(px, py)
+}
+
+// modulePrimitive returns primitive of dark module (x, y) drawn with shape
+func modulePrimitive(modules [][]bool, x, y int, shape Shape) primitive {
+	fx, fy := float64(x), float64(y)
+	switch shape {
+	case RoundedShape:
+		return roundedRect(fx, fy, 1, 1, 0.3)
+	case DotShape:
+		return circle(fx+0.05, fy+0.05, 0.9)
+	case ConnectedShape:
+		dark := func(x, y int) bool {
+			return y >= 0 && y < len(modules) && x >= 0 && x < len(modules) && modules[y][x]
+		}
+		up, down, left, right := dark(x, y-1), dark(x, y+1), dark(x-1, y), dark(x+1, y)
+
+		p := primitive{x: fx, y: fy, w: 1, h: 1}
+		for i, neighbours := range [4]bool{up || left, up || right, down || right, down || left} {
+			if !neighbours {
+				p.radii[i] = 0.5
+			}
+		}
+		return p
+	default:
+		return primitive{x: fx, y: fy, w: 1, h: 1}
+	}
+}
+
+// eyePrimitives returns frame and pupil of finder pattern with top left corner at (x, y)
+func eyePrimitives(x, y float64, style Style) []primitive {
+	var frame, inner, pupil primitive
+	switch style.EyeFrame {
+	case RoundedEye:
+		frame, inner = roundedRect(x, y, 7, 7, 2), roundedRect(x+1, y+1, 5, 5, 1)
+	case CircleEye:
+		frame, inner = circle(x, y, 7), circle(x+1, y+1, 5)
+	default:
+		frame, inner = roundedRect(x, y, 7, 7, 0), roundedRect(x+1, y+1, 5, 5, 0)
+	}
+	frame.hole = &inner
+
+	switch style.EyePupil {
+	case RoundedEye:
+		pupil = roundedRect(x+2, y+2, 3, 3, 0.8)
+	case CircleEye:
+		pupil = circle(x+2, y+2, 3)
+	default:
+		pupil = roundedRect(x+2, y+2, 3, 3, 0)
+	}
+
+	frame.eye, pupil.eye = true, true
+	return []primitive{frame, pupil}
+}
+
+// primitives returns all the shapes of the matrix: eyes first and modules after them
+func primitives(modules [][]bool, style Style) ([]primitive, error) {
+	ver, err := version(modules)
+	if err != nil {
+		return nil, err
+	}
+	kinds, err := qr_tools.FunctionPatterns(ver)
+	if err != nil {
+		return nil, err
+	}
+
+	size := float64(len(modules))
+	shapes := make([]primitive, 0)
+	shapes = append(shapes, eyePrimitives(0, 0, style)...)
+	shapes = append(shapes, eyePrimitives(size-7, 0, style)...)
+	shapes = append(shapes, eyePrimitives(0, size-7, style)...)
+
+	for y, row := range modules {
+		for x, dark := range row {
+			// finder patterns are drawn as eyes, separators are always light
+			if !dark || kinds[y][x] == qr_tools.FinderModule {
+				continue
+			}
+
+			shape := style.Shape
+			if kinds[y][x].IsFunction() {
+				shape = style.FunctionShape
+			}
+			shapes = append(shapes, modulePrimitive(modules, x, y, shape))
+		}
+	}
+
+	return shapes, nil
+}
+
+// lerp mixes colors a and b, t is from 0 to 1
+func lerp(a, b color.RGBA, t float64) color.RGBA {
+	t = math.Max(0, math.Min(1, t))
+	mix := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+	}
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: mix(a.A, b.A)}
+}
+
+// at returns the color of fill at point (px, py) of matrix with size modules in a side
+func (f Fill) at(px, py, size float64) color.RGBA {
+	switch f.Kind {
+	case LinearFill:
+		// projection on the direction, scaled so that the whole matrix goes from 0 to 1
+		sin, cos := math.Sincos(f.Angle * math.Pi / 180)
+		extent := (math.Abs(cos) + math.Abs(sin)) * size
+		proj := (px-size/2)*cos + (py-size/2)*sin
+		return lerp(f.From, f.To, proj/extent+0.5)
+	case RadialFill:
+		return lerp(f.From, f.To, math.Hypot(px-size/2, py-size/2)/(size/2*math.Sqrt2))
+	default:
+		return f.From
+	}
+}
+
+// fillOf returns fill of the primitive
+func (s Style) fillOf(p primitive) Fill {
+	if p.light {
+		return Solid(s.Background)
+	}
+	if p.caption || p.overlay {
+		return Solid(s.Foreground.From)
+	}
+	if p.eye && s.EyeFill != nil {
+		return *s.EyeFill
+	}
+	return s.Foreground
+}
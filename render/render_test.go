@@ -0,0 +1,266 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+var (
+	black = color.RGBA{A: 255}
+	white = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red   = color.RGBA{R: 255, A: 255}
+	blue  = color.RGBA{B: 255, A: 255}
+)
+
+// testCode returns the matrix of text encoded into chosen version with level M
+func testCode(t *testing.T, ver qr_tools.QRVersion, text string) [][]bool {
+	t.Helper()
+	modules, err := qr_tools.Encode([]qr_tools.Segment{qr_tools.NewByteSegment([]byte(text))}, qr_tools.M, ver)
+	if err != nil {
+		t.Fatalf("Failed to encode %q: %v", text, err)
+	}
+	return modules
+}
+
+// findModule returns the first data module of the matrix, row by row, that match accepts,
+// dark tells if module (x, y) is dark and it's false outside the matrix
+func findModule(t *testing.T, modules [][]bool, match func(dark func(x, y int) bool, x, y int) bool) (int, int) {
+	t.Helper()
+	ver, _ := version(modules)
+	kinds, _ := qr_tools.FunctionPatterns(ver)
+	dark := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < len(modules) && y < len(modules) && modules[y][x]
+	}
+
+	for y := range modules {
+		for x := range modules {
+			if kinds[y][x] == qr_tools.DataModule && match(dark, x, y) {
+				return x, y
+			}
+		}
+	}
+	t.Fatalf("Matrix has no module to test")
+	return 0, 0
+}
+
+// single is dark module without dark neighbours
+func single(dark func(x, y int) bool, x, y int) bool {
+	return dark(x, y) && !dark(x-1, y) && !dark(x+1, y) && !dark(x, y-1) && !dark(x, y+1)
+}
+
+// pair is a run of two dark modules, the left one has no dark neighbours above and below
+func pair(dark func(x, y int) bool, x, y int) bool {
+	return dark(x, y) && dark(x+1, y) && !dark(x-1, y) && !dark(x+2, y) && !dark(x, y-1) && !dark(x, y+1)
+}
+
+// module returns color of the pixel at (dx, dy) fraction of module (x, y)
+func module(t *testing.T, modules [][]bool, opts Options, x, y int, dx, dy float64) color.RGBA {
+	img, err := Image(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	s := float64(opts.Scale)
+	return img.RGBAAt(int((float64(x+opts.QuietZone)+dx)*s), int((float64(y+opts.QuietZone)+dy)*s))
+}
+
+func TestImage(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	x, y := findModule(t, modules, single)
+	opts := DefaultOptions()
+
+	img, err := Image(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if img.Bounds().Dx() != 29*opts.Scale {
+		t.Errorf("Image is %d pixels wide instead of %d", img.Bounds().Dx(), 29*opts.Scale)
+	}
+
+	cases := []struct {
+		x, y     int
+		dx, dy   float64
+		expected color.RGBA
+	}{
+		// eye frame, gap, pupil and separator
+		{0, 0, 0.5, 0.5, black},
+		{1, 1, 0.5, 0.5, white},
+		{3, 3, 0.5, 0.5, black},
+		{7, 3, 0.5, 0.5, white},
+		{x, y, 0.5, 0.5, black},
+		{x, y, 0.05, 0.05, black},
+		{x + 1, y, 0.5, 0.5, white},
+		{-1, -1, 0.5, 0.5, white},
+	}
+	for _, c := range cases {
+		if got := module(t, modules, opts, c.x, c.y, c.dx, c.dy); got != c.expected {
+			t.Errorf("Module (%d, %d) is %v instead of %v", c.x, c.y, got, c.expected)
+		}
+	}
+	s := opts.Scale
+	for y := range modules {
+		for x := range modules {
+			if got := img.RGBAAt((x+opts.QuietZone)*s+s/2, (y+opts.QuietZone)*s+s/2) == black; got != modules[y][x] {
+				t.Errorf("Module (%d, %d) is dark: %v instead of %v", x, y, got, modules[y][x])
+			}
+		}
+	}
+
+	if _, err := Image(modules[1:], opts); err != WrongSizeError {
+		t.Errorf("Wrong matrix is rendered with %v", err)
+	}
+	if _, err := Image(modules, Options{}); err != WrongScaleError {
+		t.Errorf("Zero scale is accepted with %v", err)
+	}
+}
+
+func TestImage_Caption(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	opts := DefaultOptions()
+	opts.QuietZone = 2
+
+	img, err := Image(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if img.Bounds().Dx() != 25*opts.Scale || img.Bounds().Dy() != 25*opts.Scale {
+		t.Errorf("Image without caption is %v instead of 25 modules square", img.Bounds())
+	}
+
+	opts.Caption = "T"
+	img, err = Image(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if img.Bounds().Dx() != 25*opts.Scale || img.Bounds().Dy() != 30*opts.Scale {
+		t.Errorf("Image with caption is %v instead of 25 × 30 modules", img.Bounds())
+	}
+
+	// stem of T is in the middle of caption area, its sides are empty
+	center := img.RGBAAt(img.Bounds().Dx()/2, 25*opts.Scale+opts.Scale*3/2)
+	side := img.RGBAAt(opts.Scale, 25*opts.Scale+opts.Scale*3/2)
+	if center != black || side != white {
+		t.Errorf("Caption is drawn wrong: center %v, side %v", center, side)
+	}
+
+	// zero quiet zone stands for MinQuietZone, NoQuietZone drops it
+	if img, _ := Image(modules, Options{Scale: 1}); img.Bounds().Dx() != 21+2*MinQuietZone {
+		t.Errorf("Image with zero quiet zone is %v", img.Bounds())
+	}
+	if img, _ := Image(modules, Options{Scale: 1, QuietZone: 2, NoQuietZone: true}); img.Bounds().Dx() != 21 {
+		t.Errorf("Image without quiet zone is %v", img.Bounds())
+	}
+	if _, err := Image(modules, Options{Scale: 1, QuietZone: -1}); err != WrongQuietZoneError {
+		t.Errorf("Negative quiet zone is accepted with %v", err)
+	}
+}
+
+func TestImage_Shapes(t *testing.T) {
+	// two modules side by side and a single one
+	modules := testCode(t, 1, "HELLO WORLD")
+	sx, sy := findModule(t, modules, single)
+	px, py := findModule(t, modules, pair)
+	opts := DefaultOptions()
+	opts.Scale = 20
+
+	opts.Style.Shape = DotShape
+	if module(t, modules, opts, sx, sy, 0.05, 0.05) != white || module(t, modules, opts, sx, sy, 0.5, 0.5) != black {
+		t.Errorf("Dot isn't round")
+	}
+
+	opts.Style.Shape = ConnectedShape
+	if module(t, modules, opts, sx, sy, 0.05, 0.95) != white {
+		t.Errorf("Single connected module has sharp corner")
+	}
+	if module(t, modules, opts, px, py, 0.97, 0.03) != black || module(t, modules, opts, px, py, 0.03, 0.03) != white {
+		t.Errorf("Connected modules aren't merged")
+	}
+
+	opts.Style.EyeFrame, opts.Style.EyePupil = CircleEye, RoundedEye
+	if module(t, modules, opts, 0, 0, 0.1, 0.1) != white || module(t, modules, opts, 3, 0, 0.5, 0.5) != black {
+		t.Errorf("Circle eye frame is drawn wrong")
+	}
+	if module(t, modules, opts, 2, 2, 0.05, 0.05) != white || module(t, modules, opts, 3, 3, 0.5, 0.5) != black {
+		t.Errorf("Rounded eye pupil is drawn wrong")
+	}
+}
+
+func TestImage_Fills(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	// dark modules at the left side, at the right side and in the center of the matrix
+	lx, ly := findModule(t, modules, func(dark func(x, y int) bool, x, y int) bool { return dark(x, y) && x < 4 })
+	rx, ry := findModule(t, modules, func(dark func(x, y int) bool, x, y int) bool { return dark(x, y) && x > 17 })
+	cx, cy := findModule(t, modules, func(dark func(x, y int) bool, x, y int) bool {
+		return dark(x, y) && x >= 9 && x <= 11 && y >= 9 && y <= 11
+	})
+	opts := DefaultOptions()
+	opts.Style.EyeFill = &Fill{Kind: SolidFill, From: red}
+
+	opts.Style.Foreground = Fill{Kind: LinearFill, From: black, To: blue}
+	if c := module(t, modules, opts, 0, 0, 0.5, 0.5); c != red {
+		t.Errorf("Eye is %v instead of %v", c, red)
+	}
+	left, right := module(t, modules, opts, lx, ly, 0.5, 0.5), module(t, modules, opts, rx, ry, 0.5, 0.5)
+	if left.B >= right.B {
+		t.Errorf("Linear gradient doesn't go from left to right: %v, %v", left, right)
+	}
+
+	opts.Style.Foreground = Fill{Kind: RadialFill, From: black, To: blue}
+	center, edge := module(t, modules, opts, cx, cy, 0.5, 0.5), module(t, modules, opts, rx, ry, 0.5, 0.5)
+	if center.B >= edge.B || center.B > 25 {
+		t.Errorf("Radial gradient doesn't go from the center: %v, %v", center, edge)
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	modules := testCode(t, 2, "https://example.com/qr")
+
+	buf := bytes.Buffer{}
+	if err := WritePNG(&buf, modules, DefaultOptions()); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil || img.Bounds().Dx() != 33*8 {
+		t.Errorf("Written PNG is wrong: %v", err)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	x, y := findModule(t, modules, single)
+
+	svg, err := SVG(modules, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	for _, part := range []string{`viewBox="-4 -4 29 29"`, `width="232"`, `fill="#ffffff"`,
+		`fill-rule="evenodd" fill="#000000" d="M0 0H7V7H0V0ZM1 1H6V6H1V1Z`, fmt.Sprintf("M%d %dH%dV%dH%dV%dZ", x, y, x+1, y+1, x, y)} {
+		if !strings.Contains(svg, part) {
+			t.Errorf("SVG doesn't contain %s: %s", part, svg)
+		}
+	}
+
+	opts := DefaultOptions()
+	opts.Style.Shape = DotShape
+	opts.Style.Foreground = Fill{Kind: LinearFill, From: black, To: color.RGBA{B: 255, A: 128}}
+	svg, _ = SVG(modules, opts)
+	for _, part := range []string{`<linearGradient id="fg" gradientUnits="userSpaceOnUse" x1="0" y1="10.5" x2="21" y2="10.5">`,
+		`stop-opacity="0.502"`, `fill="url(#fg)"`, `A0.45 0.45 0 0 1`} {
+		if !strings.Contains(svg, part) {
+			t.Errorf("SVG doesn't contain %s: %s", part, svg)
+		}
+	}
+
+	opts.QuietZone, opts.Caption = 2, "T"
+	svg, _ = SVG(modules, opts)
+	for _, part := range []string{`viewBox="-2 -2 25 30"`, `height="240"`, `fill="#000000" d="M9.25 23.5H11.75V24H9.25V23.5Z`} {
+		if !strings.Contains(svg, part) {
+			t.Errorf("SVG doesn't contain %s: %s", part, svg)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package render
+
+import "errors"
+
+var (
+	WrongSheetError = errors.New("sheet has no labels or its labels don't fit the page")
+)
+
+// Page is the size of page in millimetres
+type Page struct {
+	Width, Height float64
+}
+
+var (
+	A4     = Page{Width: 210, Height: 297}
+	Letter = Page{Width: 215.9, Height: 279.4}
+)
+
+// Sheet is a grid of equal labels on a page, all the sizes are in millimetres
+type Sheet struct {
+	Page Page
+	// Left and Top are the margins before the first column and row
+	Left, Top float64
+	// Width and Height are the sizes of a single label
+	Width, Height float64
+	// PitchX and PitchY are the distances between starts of neighbouring columns and rows
+	PitchX, PitchY float64
+	Columns, Rows  int
+}
+
+var (
+	// AveryL7160 is A4 sheet of 21 labels 63.5 × 38.1 mm
+	AveryL7160 = Sheet{
+		Page: A4, Left: 7.2, Top: 15.15,
+		Width: 63.5, Height: 38.1, PitchX: 66, PitchY: 38.1,
+		Columns: 3, Rows: 7,
+	}
+	// AveryL7651 is A4 sheet of 65 labels 38.1 × 21.2 mm
+	AveryL7651 = Sheet{
+		Page: A4, Left: 4.75, Top: 10.7,
+		Width: 38.1, Height: 21.2, PitchX: 40.6, PitchY: 21.2,
+		Columns: 5, Rows: 13,
+	}
+	// Avery5160 is Letter sheet of 30 labels 1 × 2⅝ inches
+	Avery5160 = Sheet{
+		Page: Letter, Left: 4.7625, Top: 12.7,
+		Width: 66.675, Height: 25.4, PitchX: 69.85, PitchY: 25.4,
+		Columns: 3, Rows: 10,
+	}
+)
+
+// SinglePage returns sheet with a single size × size label in the center of the page
+func SinglePage(page Page, size float64) Sheet {
+	return Sheet{
+		Page: page, Left: (page.Width - size) / 2, Top: (page.Height - size) / 2,
+		Width: size, Height: size, PitchX: size, PitchY: size,
+		Columns: 1, Rows: 1,
+	}
+}
+
+// Labels returns the number of labels on a single sheet
+func (s Sheet) Labels() int {
+	return s.Columns * s.Rows
+}
+
+// Label returns top left corner of label i, labels go row by row
+func (s Sheet) Label(i int) (x, y float64) {
+	i %= s.Labels()
+	return s.Left + float64(i%s.Columns)*s.PitchX, s.Top + float64(i/s.Columns)*s.PitchY
+}
+
+// check validates the sheet, a small tolerance is given for rounded templates
+func (s Sheet) check() error {
+	const eps = 0.01
+	switch {
+	case s.Columns <= 0 || s.Rows <= 0 || s.Width <= 0 || s.Height <= 0:
+		return WrongSheetError
+	case s.Columns > 1 && s.PitchX < s.Width-eps || s.Rows > 1 && s.PitchY < s.Height-eps:
+		return WrongSheetError
+	case s.Left < -eps || s.Top < -eps:
+		return WrongSheetError
+	}
+
+	right, bottom := s.Label(s.Labels() - 1)
+	if right+s.Width > s.Page.Width+eps || bottom+s.Height > s.Page.Height+eps {
+		return WrongSheetError
+	}
+	return nil
+}
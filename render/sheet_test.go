@@ -0,0 +1,49 @@
+package render
+
+import "testing"
+
+func TestSheet_Check(t *testing.T) {
+	cases := []struct {
+		name     string
+		sheet    Sheet
+		expected error
+	}{
+		{"L7160", AveryL7160, nil},
+		{"L7651", AveryL7651, nil},
+		{"5160", Avery5160, nil},
+		{"single", SinglePage(A4, 50), nil},
+		{"too big single", SinglePage(A4, 250), WrongSheetError},
+		{"empty", Sheet{Page: A4, Width: 10, Height: 10}, WrongSheetError},
+		{"overlapping", Sheet{Page: A4, Width: 10, Height: 10, PitchX: 5, PitchY: 10, Columns: 2, Rows: 1}, WrongSheetError},
+		{"off the page", Sheet{Page: A4, Left: 150, Width: 40, Height: 40, PitchX: 40, PitchY: 40, Columns: 2, Rows: 1}, WrongSheetError},
+	}
+
+	for _, c := range cases {
+		if err := c.sheet.check(); err != c.expected {
+			t.Errorf("Sheet %s gives %v instead of %v", c.name, err, c.expected)
+		}
+	}
+}
+
+func TestSheet_Label(t *testing.T) {
+	s := AveryL7160
+	if s.Labels() != 21 {
+		t.Errorf("L7160 has %d labels instead of 21", s.Labels())
+	}
+
+	cases := []struct {
+		i    int
+		x, y float64
+	}{
+		{0, 7.2, 15.15},
+		{2, 139.2, 15.15},
+		{4, 73.2, 53.25},
+		// labels of the next sheet are at the same places
+		{21, 7.2, 15.15},
+	}
+	for _, c := range cases {
+		if x, y := s.Label(c.i); x != c.x || y != c.y {
+			t.Errorf("Label %d is at (%v, %v) instead of (%v, %v)", c.i, x, y, c.x, c.y)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+var (
+	WrongDimensionsError = errors.New("physical dimensions must be positive")
+)
+
+// pinchInset is the distance in modules by which corners of dark modules that touch only diagonally
+// are moved inside the modules, so that every edge of the mesh belongs to exactly two triangles
+const pinchInset = 0.01
+
+// STLOptions are the options of STL model, all the sizes are in millimetres
+type STLOptions struct {
+	// Module is the side of module
+	Module float64
+	// Base is the thickness of plate under the whole code, Height is the height of dark modules above it
+	Base, Height float64
+	// QuietZone is the width of plate border around the matrix in modules
+	QuietZone int
+}
+
+// DefaultSTLOptions returns options for 2 mm modules raised 1 mm above 2 mm plate
+func DefaultSTLOptions() STLOptions {
+	return STLOptions{Module: 2, Base: 2, Height: 1, QuietZone: MinQuietZone}
+}
+
+type vec3 [3]float64
+
+func (a vec3) sub(b vec3) vec3 {
+	return vec3{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func (a vec3) cross(b vec3) vec3 {
+	return vec3{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+func (a vec3) dot(b vec3) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+// facet is a triangle with its outward normal, vertices go counterclockwise seen from outside
+type facet struct {
+	normal  vec3
+	a, b, c vec3
+}
+
+// polygon splits convex polygon into a fan of facets that face normal
+func polygon(facets []facet, normal vec3, points ...vec3) []facet {
+	for i := 1; i+1 < len(points); i++ {
+		a, b, c := points[0], points[i], points[i+1]
+		if b.sub(a).cross(c.sub(a)).dot(normal) < 0 {
+			b, c = c, b
+		}
+		facets = append(facets, facet{normal: normal, a: a, b: b, c: c})
+	}
+	return facets
+}
+
+// gridPoint is a point of module grid, y goes down
+type gridPoint struct {
+	x, y float64
+}
+
+// mesh returns facets of the extruded matrix, it's a heightfield over the module grid with quiet zone:
+// every cell has flat bottom, flat top at Base or Base+Height and walls where heights differ
+func mesh(modules [][]bool, opts STLOptions) []facet {
+	size := len(modules)
+	full := size + 2*opts.QuietZone
+	dark := func(i, j int) bool {
+		x, y := i-opts.QuietZone, j-opts.QuietZone
+		return x >= 0 && y >= 0 && x < size && y < size && modules[y][x]
+	}
+	inside := func(i, j int) bool {
+		return i >= 0 && j >= 0 && i < full && j < full
+	}
+	// pinch tells if the cells around vertex (x, y) are colored like a chessboard
+	pinch := func(x, y int) bool {
+		if x <= 0 || y <= 0 || x >= full || y >= full {
+			return false
+		}
+		return dark(x-1, y-1) == dark(x, y) && dark(x, y-1) == dark(x-1, y) && dark(x, y) != dark(x-1, y)
+	}
+	// point returns vertex (x, y) of dark cell (i, j), it's moved inside the cell if the vertex is a pinch
+	point := func(i, j, x, y int) gridPoint {
+		p := gridPoint{float64(x), float64(y)}
+		if pinch(x, y) {
+			p.x += pinchInset * math.Copysign(1, float64(i-x)+0.5)
+			p.y += pinchInset * math.Copysign(1, float64(j-y)+0.5)
+		}
+		return p
+	}
+	at := func(p gridPoint, z float64) vec3 {
+		// y goes up in the model, so that the code isn't mirrored when seen from above
+		return vec3{p.x * opts.Module, (float64(full) - p.y) * opts.Module, z}
+	}
+
+	up, down := vec3{0, 0, 1}, vec3{0, 0, -1}
+	facets := make([]facet, 0)
+	for j := 0; j < full; j++ {
+		for i := 0; i < full; i++ {
+			top := opts.Base
+			if dark(i, j) {
+				top += opts.Height
+			}
+
+			// corners go clockwise on the grid, every one with the neighbours before and after it
+			corners := [4][2]int{{i, j}, {i + 1, j}, {i + 1, j + 1}, {i, j + 1}}
+			sides := [4][2]int{{i - 1, j}, {i, j - 1}, {i + 1, j}, {i, j + 1}}
+
+			bottom := make([]vec3, 0, 4)
+			surface := make([]vec3, 0, 8)
+			for k, c := range corners {
+				bottom = append(bottom, at(gridPoint{float64(c[0]), float64(c[1])}, 0))
+				switch {
+				case dark(i, j):
+					surface = append(surface, at(point(i, j, c[0], c[1]), top))
+				case pinch(c[0], c[1]):
+					// light cell follows both of its dark neighbours around the pinch
+					before, after := sides[k], sides[(k+1)%4]
+					surface = append(surface,
+						at(point(before[0], before[1], c[0], c[1]), top),
+						at(point(after[0], after[1], c[0], c[1]), top))
+				default:
+					surface = append(surface, at(gridPoint{float64(c[0]), float64(c[1])}, top))
+				}
+			}
+			facets = polygon(facets, down, bottom...)
+			facets = polygon(facets, up, surface...)
+
+			// walls go along the side between corners k and k+1 towards neighbour sides[(k+1)%4]
+			for k := range corners {
+				n := sides[(k+1)%4]
+				c0, c1 := corners[k], corners[(k+1)%4]
+				normal := vec3{float64(n[0] - i), float64(j - n[1]), 0}
+				wall := func(p0, p1 gridPoint, z0, z1 float64) {
+					facets = polygon(facets, normal, at(p0, z0), at(p1, z0), at(p1, z1), at(p0, z1))
+				}
+
+				switch {
+				case !inside(n[0], n[1]):
+					p0, p1 := gridPoint{float64(c0[0]), float64(c0[1])}, gridPoint{float64(c1[0]), float64(c1[1])}
+					wall(p0, p1, 0, opts.Base)
+					if dark(i, j) {
+						wall(p0, p1, opts.Base, top)
+					}
+				case dark(i, j) && !dark(n[0], n[1]):
+					wall(point(i, j, c0[0], c0[1]), point(i, j, c1[0], c1[1]), opts.Base, top)
+				}
+			}
+		}
+	}
+
+	return facets
+}
+
+// STL returns the matrix as binary STL model: a plate with dark modules extruded from it
+// the mesh is closed and manifold, so it can be sliced without repairs
+func STL(modules [][]bool, opts STLOptions) ([]byte, error) {
+	if opts.Module <= 0 || opts.Base <= 0 || opts.Height <= 0 {
+		return nil, WrongDimensionsError
+	}
+	if opts.QuietZone < 0 {
+		return nil, WrongQuietZoneError
+	}
+	if _, err := version(modules); err != nil {
+		return nil, err
+	}
+
+	facets := mesh(modules, opts)
+
+	buf := bytes.Buffer{}
+	header := [80]byte{}
+	copy(header[:], "qr-tools binary STL")
+	buf.Write(header[:])
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(facets)))
+	for _, f := range facets {
+		for _, v := range [4]vec3{f.normal, f.a, f.b, f.c} {
+			_ = binary.Write(&buf, binary.LittleEndian, [3]float32{float32(v[0]), float32(v[1]), float32(v[2])})
+		}
+		// attribute byte count
+		_ = binary.Write(&buf, binary.LittleEndian, uint16(0))
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteSTL builds the model and writes it as binary STL
+func WriteSTL(w io.Writer, modules [][]bool, opts STLOptions) error {
+	stl, err := STL(modules, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(stl)
+	return err
+}
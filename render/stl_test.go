@@ -0,0 +1,82 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// key rounds vertex, so that equal vertices of different facets match
+func key(v vec3) [3]int64 {
+	return [3]int64{int64(math.Round(v[0] * 1e6)), int64(math.Round(v[1] * 1e6)), int64(math.Round(v[2] * 1e6))}
+}
+
+func TestMesh_Manifold(t *testing.T) {
+	// real code has pinches where dark modules touch diagonally
+	modules := testCode(t, 1, "HELLO WORLD")
+	opts := DefaultSTLOptions()
+
+	facets := mesh(modules, opts)
+	edges := make(map[[2][3]int64]int)
+	volume := 0.0
+	for _, f := range facets {
+		if f.b.sub(f.a).cross(f.c.sub(f.a)).dot(f.normal) <= 0 {
+			t.Fatalf("Facet %v doesn't face its normal", f)
+		}
+		for _, e := range [3][2]vec3{{f.a, f.b}, {f.b, f.c}, {f.c, f.a}} {
+			edges[[2][3]int64{key(e[0]), key(e[1])}]++
+		}
+		// divergence theorem
+		volume += f.a.dot(f.b.cross(f.c)) / 6
+	}
+
+	// every edge is passed once in each direction
+	for e, n := range edges {
+		if n != 1 || edges[[2][3]int64{e[1], e[0]}] != 1 {
+			t.Fatalf("Edge %v is used %d times and %d times backwards", e, n, edges[[2][3]int64{e[1], e[0]}])
+		}
+	}
+
+	dark := 0
+	for _, row := range modules {
+		for _, d := range row {
+			if d {
+				dark++
+			}
+		}
+	}
+	side := 29 * opts.Module
+	expected := side*side*opts.Base + float64(dark)*opts.Module*opts.Module*opts.Height
+	if math.Abs(volume-expected)/expected > 0.01 {
+		t.Errorf("Volume is %v instead of %v", volume, expected)
+	}
+}
+
+func TestSTL(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	opts := DefaultSTLOptions()
+
+	stl, err := STL(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to write STL: %v", err)
+	}
+	n := binary.LittleEndian.Uint32(stl[80:84])
+	// 29 × 29 cells with bottom and top and 4 × 29 outer walls at least, walls of dark modules come on top
+	if int(n) != len(mesh(modules, opts)) || n <= 29*29*4+4*29*2 || len(stl) != 84+50*int(n) {
+		t.Errorf("STL has %d facets in %d bytes", n, len(stl))
+	}
+
+	buf := bytes.Buffer{}
+	if err := WriteSTL(&buf, modules, opts); err != nil || !bytes.Equal(buf.Bytes(), stl) {
+		t.Errorf("WriteSTL differs from STL with %v", err)
+	}
+
+	opts.Height = 0
+	if _, err := STL(modules, opts); err != WrongDimensionsError {
+		t.Errorf("Zero height is accepted with %v", err)
+	}
+	if _, err := STL(modules[1:], DefaultSTLOptions()); err != WrongSizeError {
+		t.Errorf("Wrong matrix is written with %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// num formats coordinate with at most 3 decimals
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+// hexColor returns #rrggbb representation of c, alpha is written separately
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// opacity returns SVG opacity attribute for alpha of c or empty string if it's opaque
+func opacity(attr string, c color.RGBA) string {
+	if c.A == 255 {
+		return ""
+	}
+	return fmt.Sprintf(` %s="%s"`, attr, num(float64(c.A)/255))
+}
+
+// writePath appends subpath of the primitive (and of its hole) to sb
+func (p primitive) writePath(sb *strings.Builder) {
+	r := p.radii
+	arc := func(r, x, y float64) {
+		if r > 0 {
+			sb.WriteString("A" + num(r) + " " + num(r) + " 0 0 1 " + num(x) + " " + num(y))
+		}
+	}
+
+	sb.WriteString("M" + num(p.x+r[0]) + " " + num(p.y))
+	sb.WriteString("H" + num(p.x+p.w-r[1]))
+	arc(r[1], p.x+p.w, p.y+r[1])
+	sb.WriteString("V" + num(p.y+p.h-r[2]))
+	arc(r[2], p.x+p.w-r[2], p.y+p.h)
+	sb.WriteString("H" + num(p.x+r[3]))
+	arc(r[3], p.x, p.y+p.h-r[3])
+	sb.WriteString("V" + num(p.y+r[0]))
+	arc(r[0], p.x+r[0], p.y)
+	sb.WriteString("Z")
+
+	if p.hole != nil {
+		p.hole.writePath(sb)
+	}
+}
+
+// writeGradient writes gradient definition with chosen id
+// coordinates are the same as the ones Fill.at uses
+func writeGradient(sb *strings.Builder, id string, f Fill, size float64) {
+	stops := fmt.Sprintf(`<stop offset="0" stop-color="%s"%s/><stop offset="1" stop-color="%s"%s/>`,
+		hexColor(f.From), opacity("stop-opacity", f.From), hexColor(f.To), opacity("stop-opacity", f.To))
+
+	switch f.Kind {
+	case LinearFill:
+		sin, cos := math.Sincos(f.Angle * math.Pi / 180)
+		half := (math.Abs(cos) + math.Abs(sin)) * size / 2
+		fmt.Fprintf(sb, `<linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="%s" y1="%s" x2="%s" y2="%s">%s</linearGradient>`,
+			id, num(size/2-cos*half), num(size/2-sin*half), num(size/2+cos*half), num(size/2+sin*half), stops)
+	case RadialFill:
+		fmt.Fprintf(sb, `<radialGradient id="%s" gradientUnits="userSpaceOnUse" cx="%s" cy="%s" r="%s">%s</radialGradient>`,
+			id, num(size/2), num(size/2), num(size/2*math.Sqrt2), stops)
+	}
+}
+
+// fillAttr returns fill attributes, gradients are referenced by id
+func fillAttr(id string, f Fill) string {
+	if f.Kind == SolidFill {
+		return fmt.Sprintf(`fill="%s"%s`, hexColor(f.From), opacity("fill-opacity", f.From))
+	}
+	return fmt.Sprintf(`fill="url(#%s)"`, id)
+}
+
+// SVG returns the matrix drawn with quiet zone, overlay and caption as SVG document
+// matrix is drawn in module units, Scale sets only width and height of the document
+func SVG(modules [][]bool, opts Options) (string, error) {
+	if err := opts.normalize(); err != nil {
+		return "", err
+	}
+	shapes, err := primitives(modules, opts.Style)
+	if err != nil {
+		return "", err
+	}
+
+	size := float64(len(modules))
+	width, height := opts.bounds(len(modules))
+	quietZone := float64(opts.QuietZone)
+	eyeFill := opts.Style.fillOf(primitive{eye: true})
+
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" viewBox="%s %s %d %d" width="%d" height="%d">`,
+		num(-quietZone), num(-quietZone), width, height, width*opts.Scale, height*opts.Scale)
+
+	if opts.Style.Foreground.Kind != SolidFill || eyeFill.Kind != SolidFill {
+		sb.WriteString("<defs>")
+		writeGradient(&sb, "fg", opts.Style.Foreground, size)
+		writeGradient(&sb, "eye", eyeFill, size)
+		sb.WriteString("</defs>")
+	}
+
+	bg := opts.Style.Background
+	fmt.Fprintf(&sb, `<rect x="%s" y="%s" width="%d" height="%d" fill="%s"%s/>`,
+		num(-quietZone), num(-quietZone), width, height, hexColor(bg), opacity("fill-opacity", bg))
+
+	eyes, rest := strings.Builder{}, strings.Builder{}
+	for _, p := range shapes {
+		if p.eye {
+			p.writePath(&eyes)
+		} else {
+			p.writePath(&rest)
+		}
+	}
+	fmt.Fprintf(&sb, `<path fill-rule="evenodd" %s d="%s"/>`, fillAttr("eye", eyeFill), eyes.String())
+	if rest.Len() != 0 {
+		fmt.Fprintf(&sb, `<path %s d="%s"/>`, fillAttr("fg", opts.Style.Foreground), rest.String())
+	}
+	// overlay primitives cover each other, so a new path is started whenever the fill changes
+	overlay := opts.overlayPrimitives(len(modules))
+	for i := 0; i < len(overlay); {
+		path := strings.Builder{}
+		start := i
+		for ; i < len(overlay) && overlay[i].light == overlay[start].light; i++ {
+			overlay[i].writePath(&path)
+		}
+		fmt.Fprintf(&sb, `<path %s d="%s"/>`, fillAttr("", opts.Style.fillOf(overlay[start])), path.String())
+	}
+	if opts.Logo != nil && opts.Logo.Area != nil {
+		if err := opts.Logo.writeSVGPicture(&sb, len(modules)); err != nil {
+			return "", err
+		}
+	}
+	if opts.Caption != "" {
+		caption := strings.Builder{}
+		for _, p := range opts.captionPrimitives(len(modules)) {
+			p.writePath(&caption)
+		}
+		fmt.Fprintf(&sb, `<path %s d="%s"/>`, fillAttr("", Solid(opts.Style.Foreground.From)), caption.String())
+	}
+	sb.WriteString("</svg>")
+
+	return sb.String(), nil
+}
+
+// WriteSVG draws the matrix and writes it as SVG
+func WriteSVG(w io.Writer, modules [][]bool, opts Options) error {
+	svg, err := SVG(modules, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, svg)
+	return err
+}
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// tikzColor returns xcolor specification of c, transparency is ignored
+func tikzColor(c color.RGBA) string {
+	return fmt.Sprintf("{rgb,255:red,%d;green,%d;blue,%d}", c.R, c.G, c.B)
+}
+
+// latexEscape escapes the characters that are special in LaTeX text
+func latexEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\textbackslash{}`, `{`, `\{`, `}`, `\}`,
+		`#`, `\#`, `$`, `\$`, `%`, `\%`, `&`, `\&`, `_`, `\_`,
+		`~`, `\textasciitilde{}`, `^`, `\textasciicircum{}`,
+	).Replace(s)
+}
+
+// TikZ returns the matrix with quiet zone as tikzpicture environment, module is unit TeX length (e.g. "0.5mm")
+// modules are drawn as squares with Style.Foreground.From color on Style.Background,
+// caption is typeset by TeX as a node under the quiet zone, Scale is ignored
+func TikZ(modules [][]bool, opts Options, unit string) (string, error) {
+	opts.Scale = 1
+	if err := opts.normalize(); err != nil {
+		return "", err
+	}
+	if _, err := version(modules); err != nil {
+		return "", err
+	}
+
+	size := len(modules)
+	full := size + 2*opts.QuietZone
+
+	sb := strings.Builder{}
+	// y axis goes down, so that rows are drawn like in SVG
+	fmt.Fprintf(&sb, "\\begin{tikzpicture}[x=%s,y=-%s]\n", unit, unit)
+	if bg := opts.Style.Background; bg.A != 0 {
+		fmt.Fprintf(&sb, "\\fill[color=%s] (%d,%d) rectangle (%d,%d);\n",
+			tikzColor(bg), -opts.QuietZone, -opts.QuietZone, full-opts.QuietZone, full-opts.QuietZone)
+	}
+
+	fmt.Fprintf(&sb, "\\fill[color=%s]", tikzColor(opts.Style.Foreground.From))
+	for _, r := range runs(modules) {
+		fmt.Fprintf(&sb, "\n  (%d,%d) rectangle ++(%d,1)", r.x, r.y, r.n)
+	}
+	sb.WriteString(";\n")
+
+	if opts.Caption != "" {
+		fmt.Fprintf(&sb, "\\node[below,color=%s] at (%s,%d) {%s};\n",
+			tikzColor(opts.Style.Foreground.From), num(float64(size)/2), size+opts.QuietZone, latexEscape(opts.Caption))
+	}
+
+	sb.WriteString("\\end{tikzpicture}\n")
+	return sb.String(), nil
+}
+
+// WriteTikZ draws the matrix and writes it as tikzpicture environment
+func WriteTikZ(w io.Writer, modules [][]bool, opts Options, unit string) error {
+	tikz, err := TikZ(modules, opts, unit)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, tikz)
+	return err
+}
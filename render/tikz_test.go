@@ -0,0 +1,53 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLatexEscape(t *testing.T) {
+	cases := []struct {
+		s, expected string
+	}{
+		{"BOX 12", "BOX 12"},
+		{"50% & #1", `50\% \& \#1`},
+		{`a_b\c{d}`, `a\_b\textbackslash{}c\{d\}`},
+	}
+	for _, c := range cases {
+		if got := latexEscape(c.s); got != c.expected {
+			t.Errorf("%q is escaped as %q instead of %q", c.s, got, c.expected)
+		}
+	}
+}
+
+func TestTikZ(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	x, y := findModule(t, modules, pair)
+	opts := DefaultOptions()
+	opts.Caption = "Box #7"
+
+	tikz, err := TikZ(modules, opts, "0.5mm")
+	if err != nil {
+		t.Fatalf("Failed to write TikZ: %v", err)
+	}
+	for _, part := range []string{
+		"\\begin{tikzpicture}[x=0.5mm,y=-0.5mm]\n",
+		"\\fill[color={rgb,255:red,255;green,255;blue,255}] (-4,-4) rectangle (25,25);\n",
+		"\\fill[color={rgb,255:red,0;green,0;blue,0}]\n  (0,0) rectangle ++(7,1)\n",
+		fmt.Sprintf("\n  (%d,%d) rectangle ++(2,1)\n", x, y),
+		"\\node[below,color={rgb,255:red,0;green,0;blue,0}] at (10.5,25) {Box \\#7};\n",
+	} {
+		if !strings.Contains(tikz, part) {
+			t.Errorf("TikZ doesn't contain %q: %s", part, tikz)
+		}
+	}
+	if !strings.HasSuffix(tikz, ";\n\\end{tikzpicture}\n") {
+		t.Errorf("TikZ isn't finished: %s", tikz)
+	}
+
+	opts.QuietZone = -1
+	if _, err := TikZ(modules, opts, "1mm"); err != WrongQuietZoneError {
+		t.Errorf("Negative quiet zone is accepted with %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+var (
+	LowContrastError = errors.New("contrast between foreground and background is too low")
+	InvertedError    = errors.New("foreground is lighter than background")
+	QuietZoneError   = errors.New("quiet zone isn't light")
+	NarrowZoneError  = errors.New("quiet zone is narrower than MinQuietZone")
+	UnreadableError  = errors.New("rendered code isn't read as the matrix")
+	// WrongPayloadError is the DecodeError of code that is decoded, but holds another payload
+	WrongPayloadError = errors.New("rendered code holds another payload")
+)
+
+// MinContrast is the lowest contrast ratio between foreground and background that is accepted
+const MinContrast = 4.5
+
+// luminance returns WCAG relative luminance of c put on white paper
+func luminance(c color.RGBA) float64 {
+	channel := func(v uint8) float64 {
+		// alpha is premultiplied, so the rest of the color is white
+		s := (float64(v) + 255 - float64(c.A)) / 255
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*channel(c.R) + 0.7152*channel(c.G) + 0.0722*channel(c.B)
+}
+
+// ContrastRatio returns WCAG contrast ratio of two colors, it's from 1 to 21
+func ContrastRatio(a, b color.RGBA) float64 {
+	la, lb := luminance(a), luminance(b)
+	return (math.Max(la, lb) + 0.05) / (math.Min(la, lb) + 0.05)
+}
+
+// Report shows how readable the rendered matrix is
+type Report struct {
+	// Contrast is the lowest contrast ratio between any foreground color and background
+	Contrast float64
+	// Inverted tells if any foreground color is lighter than background
+	Inverted bool
+	// QuietZone tells if the quiet zone is entirely light after binarization
+	QuietZone bool
+	// NarrowQuietZone tells if the quiet zone is narrower than MinQuietZone
+	NarrowQuietZone bool
+	// Mismatches is the number of modules whose centers are read wrong after binarization,
+	// modules hidden by overlay aren't counted
+	Mismatches int
+	// DecodeError is why the code read from the picture isn't decoded to the payload of the matrix,
+	// it's nil if it is; error correction restores modules hidden by overlay
+	DecodeError error
+}
+
+// Err returns the first problem of the report or nil if there are none
+func (r *Report) Err() error {
+	switch {
+	case r.Inverted:
+		return InvertedError
+	case r.Contrast < MinContrast:
+		return LowContrastError
+	case r.NarrowQuietZone:
+		return NarrowZoneError
+	case !r.QuietZone:
+		return QuietZoneError
+	case r.Mismatches != 0 || r.DecodeError != nil:
+		return UnreadableError
+	}
+	return nil
+}
+
+// otsuThreshold returns luminance threshold that separates dark and light pixels the best
+func otsuThreshold(gray *image.Gray) uint8 {
+	var histogram [256]int
+	for _, v := range gray.Pix {
+		histogram[v]++
+	}
+
+	total, sum := len(gray.Pix), 0
+	for v, n := range histogram {
+		sum += v * n
+	}
+
+	best, threshold := -1.0, 0
+	darkCount, darkSum := 0, 0
+	for v, n := range histogram {
+		darkCount += n
+		darkSum += v * n
+		lightCount := total - darkCount
+		if darkCount == 0 || lightCount == 0 {
+			continue
+		}
+
+		meanDark := float64(darkSum) / float64(darkCount)
+		meanLight := float64(sum-darkSum) / float64(lightCount)
+		variance := float64(darkCount) * float64(lightCount) * (meanDark - meanLight) * (meanDark - meanLight)
+		if variance > best {
+			best, threshold = variance, v
+		}
+	}
+
+	return uint8(threshold)
+}
+
+// binarize returns dark pixels of the image the way a scanner sees them
+func binarize(img *image.RGBA) (dark func(x, y int) bool) {
+	gray := image.NewGray(img.Bounds())
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			gray.SetGray(x, y, color.Gray{Y: uint8(math.Round(luminance(img.RGBAAt(x, y)) * 255))})
+		}
+	}
+
+	threshold := otsuThreshold(gray)
+	return func(x, y int) bool {
+		return gray.GrayAt(x, y).Y <= threshold
+	}
+}
+
+// expectedFinder tells if module (x, y) of finder pattern with top left corner at (x0, y0)
+// is dark, only the middle row and column are checked, since eye shapes change the corners
+func expectedFinder(x, y, x0, y0 int) (dark, checked bool) {
+	dx, dy := x-x0-3, y-y0-3
+	if dx != 0 && dy != 0 || dx < -3 || dx > 3 || dy < -3 || dy > 3 {
+		return false, false
+	}
+
+	d := max(abs(dx), abs(dy))
+	return d != 2, true
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// sample binarizes the image and reads every module at its center
+// returns modules as they are read, if the quiet zone is entirely light
+// and the number of modules that don't match the matrix, modules hidden by overlay aren't counted
+func sample(img *image.RGBA, modules [][]bool, kinds [][]qr_tools.ModuleKind, overlay []primitive, scale, quietZone int) (read [][]bool, light bool, mismatches int) {
+	dark := binarize(img)
+	size := len(modules)
+	full := size + 2*quietZone
+	read = make([][]bool, size)
+	for y := range read {
+		read[y] = make([]bool, size)
+	}
+	light = true
+	for my := 0; my < full; my++ {
+		for mx := 0; mx < full; mx++ {
+			px, py := mx*scale+scale/2, my*scale+scale/2
+			x, y := mx-quietZone, my-quietZone
+
+			if x < 0 || y < 0 || x >= size || y >= size {
+				if dark(px, py) {
+					light = false
+				}
+				continue
+			}
+
+			read[y][x] = dark(px, py)
+			expected, checked := modules[y][x], !covered(overlay, x, y)
+			if checked && kinds[y][x] == qr_tools.FinderModule {
+				expected, checked = false, false
+				for _, corner := range [3][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}} {
+					if d, c := expectedFinder(x, y, corner[0], corner[1]); c {
+						expected, checked = d, true
+					}
+				}
+			}
+			if checked && read[y][x] != expected {
+				mismatches++
+			}
+		}
+	}
+
+	return read, light, mismatches
+}
+
+// payload decodes the matrix and unmarshals its data
+func payload(modules [][]bool) ([]byte, error) {
+	data, _, ver, err := qr_tools.Decode(modules)
+	if err != nil {
+		return nil, err
+	}
+
+	return qr_tools.NewQRUnmarshaler(ver).UnmarshalToBytes(data)
+}
+
+// roundTrip decodes modules read from the picture and compares their payload with the payload of the matrix
+// returns the error of decoding read modules, or WrongPayloadError
+// throws the errors of qr_tools.Decode if the matrix itself can't be decoded
+func roundTrip(read, modules [][]bool) (decodeErr error, err error) {
+	expected, err := payload(modules)
+	if err != nil {
+		return nil, err
+	}
+
+	got, err := payload(read)
+	switch {
+	case err != nil:
+		return err, nil
+	case !bytes.Equal(got, expected):
+		return WrongPayloadError, nil
+	}
+	return nil, nil
+}
+
+// Inspect renders the matrix, binarizes it and checks its contrast, colors, quiet zone and modules
+//
+// every module is read at its center, the modules read are decoded with error correction
+// and their payload is compared with the payload of the matrix, the code is read where it's drawn
+// throws the errors of qr_tools.Decode if the matrix isn't a readable code
+func Inspect(modules [][]bool, opts Options) (*Report, error) {
+	if err := opts.normalize(); err != nil {
+		return nil, err
+	}
+	img, err := Image(modules, opts)
+	if err != nil {
+		return nil, err
+	}
+	ver, err := version(modules)
+	if err != nil {
+		return nil, err
+	}
+	kinds, err := qr_tools.FunctionPatterns(ver)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{Contrast: math.Inf(1), NarrowQuietZone: opts.QuietZone < MinQuietZone}
+	style := opts.Style
+	for _, c := range []color.RGBA{style.Foreground.From, style.Foreground.To, style.fillOf(primitive{eye: true}).From, style.fillOf(primitive{eye: true}).To} {
+		r.Contrast = math.Min(r.Contrast, ContrastRatio(c, style.Background))
+		if luminance(c) > luminance(style.Background) {
+			r.Inverted = true
+		}
+	}
+
+	var read [][]bool
+	read, r.QuietZone, r.Mismatches = sample(img, modules, kinds, opts.overlayPrimitives(len(modules)), opts.Scale, opts.QuietZone)
+	if r.DecodeError, err = roundTrip(read, modules); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Validate returns the first readability problem of rendered matrix or nil if there are none
+func Validate(modules [][]bool, opts Options) error {
+	r, err := Inspect(modules, opts)
+	if err != nil {
+		return err
+	}
+
+	return r.Err()
+}
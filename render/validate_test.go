@@ -0,0 +1,166 @@
+package render
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/rinnothing/qr-tools/logo"
+)
+
+func TestContrastRatio(t *testing.T) {
+	cases := []struct {
+		a, b     color.RGBA
+		expected float64
+	}{
+		{black, white, 21},
+		{white, white, 1},
+		{color.RGBA{R: 119, G: 119, B: 119, A: 255}, white, 4.48},
+		// transparent black is white paper
+		{color.RGBA{}, white, 1},
+	}
+
+	for _, c := range cases {
+		if ratio := ContrastRatio(c.a, c.b); math.Abs(ratio-c.expected) > 0.01 {
+			t.Errorf("Contrast of %v and %v is %f instead of %f", c.a, c.b, ratio, c.expected)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	modules := testCode(t, 2, "https://example.com/qr")
+
+	styles := []Style{DefaultStyle()}
+	for _, shape := range []Shape{RoundedShape, DotShape, ConnectedShape} {
+		s := DefaultStyle()
+		s.Shape, s.EyeFrame, s.EyePupil = shape, CircleEye, CircleEye
+		s.Foreground = Fill{Kind: RadialFill, From: blue, To: black}
+		styles = append(styles, s)
+	}
+
+	for _, s := range styles {
+		r, err := Inspect(modules, Options{Scale: 6, Style: s})
+		if err != nil {
+			t.Fatalf("Failed to inspect: %v", err)
+		}
+		if r.Err() != nil || r.Mismatches != 0 || !r.QuietZone || r.DecodeError != nil {
+			t.Errorf("Style %v is reported as %v", s, r)
+		}
+	}
+
+	cases := []struct {
+		fg, bg   color.RGBA
+		expected error
+	}{
+		{white, black, InvertedError},
+		{color.RGBA{R: 150, G: 150, B: 150, A: 255}, white, LowContrastError},
+		{black, white, nil},
+	}
+	for _, c := range cases {
+		s := DefaultStyle()
+		s.Foreground, s.Background = Solid(c.fg), c.bg
+		if err := Validate(modules, Options{Scale: 4, Style: s}); err != c.expected {
+			t.Errorf("Colors %v on %v are validated with %v instead of %v", c.fg, c.bg, err, c.expected)
+		}
+	}
+
+	// light eyes are checked too
+	s := DefaultStyle()
+	s.EyeFill = &Fill{Kind: SolidFill, From: color.RGBA{R: 200, G: 200, B: 200, A: 255}}
+	if err := Validate(modules, Options{Scale: 4, Style: s}); err != LowContrastError {
+		t.Errorf("Light eyes are validated with %v", err)
+	}
+}
+
+func TestReport_Err(t *testing.T) {
+	cases := []struct {
+		r        Report
+		expected error
+	}{
+		{Report{Contrast: 21, QuietZone: true}, nil},
+		{Report{Contrast: 21, QuietZone: false}, QuietZoneError},
+		{Report{Contrast: 21, QuietZone: true, NarrowQuietZone: true}, NarrowZoneError},
+		{Report{Contrast: 21, QuietZone: true, Mismatches: 3}, UnreadableError},
+		{Report{Contrast: 21, QuietZone: true, DecodeError: WrongPayloadError}, UnreadableError},
+		{Report{Contrast: 2, QuietZone: true, Inverted: true}, InvertedError},
+	}
+
+	for _, c := range cases {
+		if err := c.r.Err(); err != c.expected {
+			t.Errorf("Report %v gives %v instead of %v", c.r, err, c.expected)
+		}
+	}
+}
+
+func TestValidate_QuietZone(t *testing.T) {
+	modules := testCode(t, 1, "HELLO WORLD")
+	opts := DefaultOptions()
+
+	opts.QuietZone = 1
+	if err := Validate(modules, opts); err != NarrowZoneError {
+		t.Errorf("Validate of 1 module quiet zone gives %v instead of %v", err, NarrowZoneError)
+	}
+	opts.QuietZone = 6
+	if err := Validate(modules, opts); err != nil {
+		t.Errorf("Validate of 6 modules quiet zone gives %v instead of nil", err)
+	}
+	opts.QuietZone = 0
+	if err := Validate(modules, opts); err != nil {
+		t.Errorf("Validate of default quiet zone gives %v instead of nil", err)
+	}
+	opts.NoQuietZone = true
+	if err := Validate(modules, opts); err != NarrowZoneError {
+		t.Errorf("Validate without quiet zone gives %v instead of %v", err, NarrowZoneError)
+	}
+	opts.NoQuietZone = false
+	opts.QuietZone = -1
+	if err := Validate(modules, opts); err != WrongQuietZoneError {
+		t.Errorf("Validate of negative quiet zone gives %v instead of %v", err, WrongQuietZoneError)
+	}
+}
+
+func TestInspect_Mismatches(t *testing.T) {
+	modules := testCode(t, 2, "https://example.com/qr")
+
+	// right part of the code fades into background
+	s := DefaultStyle()
+	s.Foreground = Fill{Kind: LinearFill, From: black, To: white}
+	r, err := Inspect(modules, Options{Scale: 4, Style: s})
+	if err != nil {
+		t.Fatalf("Failed to inspect: %v", err)
+	}
+	if r.Mismatches == 0 {
+		t.Errorf("Faded modules aren't reported")
+	}
+}
+
+func TestInspect_RoundTrip(t *testing.T) {
+	modules := testCode(t, 5, "https://example.com/logo")
+	opts := DefaultOptions()
+
+	// logo of a fifth of the side is restored by error correction, a half of the side is too much
+	opts.Logo = &Logo{Area: logo.CenteredRect(0.2, 0.2)}
+	r, err := Inspect(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to inspect: %v", err)
+	}
+	if r.DecodeError != nil || r.Err() != nil {
+		t.Errorf("Small logo is reported as %v", r)
+	}
+	opts.Logo = &Logo{Area: logo.CenteredRect(0.5, 0.5)}
+	if r, _ := Inspect(modules, opts); r.DecodeError == nil || r.Err() != UnreadableError {
+		t.Errorf("Big logo is reported as %v", r)
+	}
+
+	// matrix that isn't a code can't be inspected
+	broken := testCode(t, 5, "https://example.com/logo")
+	for x := 0; x < 9; x++ {
+		broken[8][x] = !broken[8][x]
+	}
+	for x := len(broken) - 8; x < len(broken); x++ {
+		broken[8][x] = !broken[8][x]
+	}
+	if _, err := Inspect(broken, DefaultOptions()); err == nil {
+		t.Errorf("Matrix without format information is inspected")
+	}
+}
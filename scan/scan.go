@@ -0,0 +1,350 @@
+// Package scan finds QR codes in images and reads them
+//
+// codes are found by their finder patterns, so they may be scaled and rotated, but not skewed by perspective;
+// the image is binarized, modules are read at their centers and decoded with qr_tools.Decode
+package scan
+
+import (
+	"errors"
+	"image"
+	"math"
+	"sort"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+var (
+	NoCodeError = errors.New("no code is found in the image")
+)
+
+// Code is QR code read from the image
+type Code struct {
+	// Modules are read as they are, Data has their errors corrected
+	Modules [][]bool
+	// Data are data codewords, QRUnmarshaler of Version turns them into payload
+	Data    []byte
+	Level   qr_tools.ErrorCorrectionLevel
+	Version qr_tools.QRVersion
+	// X and Y are the center of the top left finder pattern in pixels
+	X, Y float64
+
+	module float64
+}
+
+// Payload unmarshals data codewords of the code
+func (c Code) Payload() ([]byte, error) {
+	return qr_tools.NewQRUnmarshaler(c.Version).UnmarshalToBytes(c.Data)
+}
+
+// bitmap is binarized image, true stands for dark pixel
+type bitmap struct {
+	w, h int
+	dark []bool
+}
+
+func (b *bitmap) at(x, y int) bool {
+	return x >= 0 && y >= 0 && x < b.w && y < b.h && b.dark[y*b.w+x]
+}
+
+// binarize turns the image into bitmap with Otsu threshold of luminance,
+// transparent pixels are taken as put on white paper
+func binarize(img image.Image) *bitmap {
+	bounds := img.Bounds()
+	b := &bitmap{w: bounds.Dx(), h: bounds.Dy(), dark: make([]bool, bounds.Dx()*bounds.Dy())}
+
+	gray := make([]uint8, len(b.dark))
+	var histogram [256]int
+	for y := 0; y < b.h; y++ {
+		for x := 0; x < b.w; x++ {
+			r, g, bl, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			v := uint8((19595*r+38470*g+7471*bl+1<<15)>>24 + (0xffff-a)>>8)
+			gray[y*b.w+x] = v
+			histogram[v]++
+		}
+	}
+
+	total, sum := len(gray), 0
+	for v, n := range histogram {
+		sum += v * n
+	}
+	best, threshold := -1.0, 0
+	darkCount, darkSum := 0, 0
+	for v, n := range histogram {
+		darkCount += n
+		darkSum += v * n
+		lightCount := total - darkCount
+		if darkCount == 0 || lightCount == 0 {
+			continue
+		}
+
+		meanDark := float64(darkSum) / float64(darkCount)
+		meanLight := float64(sum-darkSum) / float64(lightCount)
+		variance := float64(darkCount) * float64(lightCount) * (meanDark - meanLight) * (meanDark - meanLight)
+		if variance > best {
+			best, threshold = variance, v
+		}
+	}
+
+	for i, v := range gray {
+		b.dark[i] = int(v) <= threshold
+	}
+	return b
+}
+
+// finder is the center of finder pattern candidate and its module size in pixels
+type finder struct {
+	x, y, module float64
+	hits         int
+}
+
+// finderRatio tells if runs of dark, light, dark, light and dark pixels are 1:1:3:1:1
+// and returns the module size
+func finderRatio(runs [5]int) (float64, bool) {
+	total := 0
+	for _, r := range runs {
+		if r == 0 {
+			return 0, false
+		}
+		total += r
+	}
+	if total < 7 {
+		return 0, false
+	}
+
+	module := float64(total) / 7
+	for i, r := range runs {
+		expected := module
+		if i == 2 {
+			expected = 3 * module
+		}
+		if math.Abs(float64(r)-expected) >= expected/2+0.5 {
+			return 0, false
+		}
+	}
+	return module, true
+}
+
+// crossCheck measures the pattern along the line from (x, y) going by (dx, dy) both ways
+// returns the center of its middle run as the offset from (x, y) and the module size
+func (b *bitmap) crossCheck(x, y, dx, dy int) (float64, float64, bool) {
+	if !b.at(x, y) {
+		return 0, 0, false
+	}
+	inside := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < b.w && y < b.h
+	}
+
+	// runs[2] is the middle one, it's measured from both sides
+	var runs [5]int
+	backward := 0
+	for i, dark := 2, true; i >= 0; i, dark = i-1, !dark {
+		for px, py := x-(backward+1)*dx, y-(backward+1)*dy; inside(px, py) && b.at(px, py) == dark; px, py = px-dx, py-dy {
+			backward++
+			runs[i]++
+		}
+	}
+	forward := 0
+	for i, dark := 2, true; i < 5; i, dark = i+1, !dark {
+		for px, py := x+forward*dx, y+forward*dy; inside(px, py) && b.at(px, py) == dark; px, py = px+dx, py+dy {
+			forward++
+			runs[i]++
+		}
+	}
+
+	module, ok := finderRatio(runs)
+	if !ok {
+		return 0, 0, false
+	}
+	middleStart := runs[0] + runs[1] - backward
+	return float64(middleStart) + float64(runs[2])/2, module, true
+}
+
+// run is a row of pixels of the same color
+type run struct {
+	start, length int
+	dark          bool
+}
+
+// findFinders returns finder pattern candidates: 1:1:3:1:1 runs found in rows that are also found in columns,
+// the candidates found close to each other are merged
+func (b *bitmap) findFinders() []finder {
+	finders := make([]finder, 0)
+	add := func(x, y, module float64) {
+		for i := range finders {
+			f := &finders[i]
+			if math.Hypot(f.x-x, f.y-y) <= max(f.module, module) && math.Abs(f.module-module) <= max(f.module, module)/2 {
+				n := float64(f.hits)
+				f.x, f.y, f.module = (f.x*n+x)/(n+1), (f.y*n+y)/(n+1), (f.module*n+module)/(n+1)
+				f.hits++
+				return
+			}
+		}
+		finders = append(finders, finder{x: x, y: y, module: module, hits: 1})
+	}
+
+	for y := 0; y < b.h; y++ {
+		runs := make([]run, 0)
+		for x := 0; x < b.w; x++ {
+			if x == 0 || b.at(x, y) != b.at(x-1, y) {
+				runs = append(runs, run{start: x, dark: b.at(x, y)})
+			}
+			runs[len(runs)-1].length++
+		}
+
+		for i := 0; i+4 < len(runs); i++ {
+			if !runs[i].dark {
+				continue
+			}
+			var lengths [5]int
+			for j := range lengths {
+				lengths[j] = runs[i+j].length
+			}
+			if _, ok := finderRatio(lengths); !ok {
+				continue
+			}
+
+			cx := runs[i+2].start + runs[i+2].length/2
+			dy, vertical, ok := b.crossCheck(cx, y, 0, 1)
+			if !ok {
+				continue
+			}
+			cy := float64(y) + dy
+			dx, horizontal, ok := b.crossCheck(cx, int(cy), 1, 0)
+			if !ok {
+				continue
+			}
+			add(float64(cx)+dx, cy, (vertical+horizontal)/2)
+		}
+	}
+
+	return finders
+}
+
+// corners is the guess of top left, top right and bottom left finder patterns of a code
+type corners struct {
+	topLeft, topRight, bottomLeft int
+	// error is how far the corners are from the right isosceles triangle
+	error float64
+}
+
+// guessCorners returns the triples of finder patterns that can be corners of a code, the most likely go first
+func guessCorners(finders []finder) []corners {
+	guesses := make([]corners, 0)
+	for a := range finders {
+		for b := range finders {
+			for c := b + 1; c < len(finders); c++ {
+				if a == b || a == c {
+					continue
+				}
+				fa, fb, fc := finders[a], finders[b], finders[c]
+				modules := []float64{fa.module, fb.module, fc.module}
+				sort.Float64s(modules)
+				if modules[2] > 1.5*modules[0] {
+					continue
+				}
+
+				abx, aby, acx, acy := fb.x-fa.x, fb.y-fa.y, fc.x-fa.x, fc.y-fa.y
+				ab, ac := math.Hypot(abx, aby), math.Hypot(acx, acy)
+				// the smallest code is 21 modules, so the centers of its finders are 14 modules apart
+				if ab < 10*modules[0] || ac < 10*modules[0] {
+					continue
+				}
+				cos := (abx*acx + aby*acy) / (ab * ac)
+				ratio := math.Abs(math.Log(ab / ac))
+				if math.Abs(cos) > 0.2 || ratio > 0.2 {
+					continue
+				}
+
+				g := corners{topLeft: a, topRight: b, bottomLeft: c, error: math.Abs(cos) + ratio}
+				// y goes down, so going from top right to bottom left is clockwise
+				if abx*acy-aby*acx < 0 {
+					g.topRight, g.bottomLeft = c, b
+				}
+				guesses = append(guesses, g)
+			}
+		}
+	}
+
+	sort.SliceStable(guesses, func(i, j int) bool {
+		return guesses[i].error < guesses[j].error
+	})
+	return guesses
+}
+
+// sample reads the matrix of chosen size with finder patterns centers at the corners
+func (b *bitmap) sample(tl, tr, bl finder, size int) [][]bool {
+	span := float64(size - 7)
+	ux, uy := (tr.x-tl.x)/span, (tr.y-tl.y)/span
+	vx, vy := (bl.x-tl.x)/span, (bl.y-tl.y)/span
+
+	modules := make([][]bool, size)
+	for y := range modules {
+		modules[y] = make([]bool, size)
+		for x := range modules[y] {
+			// finder centers are at the center of module 3, pixel i spans from i to i+1
+			mx, my := float64(x)-3, float64(y)-3
+			px := tl.x + mx*ux + my*vx
+			py := tl.y + mx*uy + my*vy
+			modules[y][x] = b.at(int(math.Floor(px)), int(math.Floor(py)))
+		}
+	}
+	return modules
+}
+
+// read tries versions close to the one the distance between finder patterns gives
+func (b *bitmap) read(tl, tr, bl finder) (Code, bool) {
+	module := (tl.module + tr.module + bl.module) / 3
+	side := (math.Hypot(tr.x-tl.x, tr.y-tl.y) + math.Hypot(bl.x-tl.x, bl.y-tl.y)) / 2
+	guess := int(math.Round((side/module + 7 - 17) / 4))
+
+	for _, delta := range []int{0, -1, 1, -2, 2} {
+		ver := guess + delta
+		if ver < 1 || ver > 40 {
+			continue
+		}
+
+		modules := b.sample(tl, tr, bl, 17+4*ver)
+		data, lvl, decoded, err := qr_tools.Decode(modules)
+		if err == nil {
+			return Code{Modules: modules, Data: data, Level: lvl, Version: decoded, X: tl.x, Y: tl.y, module: module}, true
+		}
+	}
+	return Code{}, false
+}
+
+// Scan returns all the codes in the image that can be decoded, ordered by the position
+// of their top left corners from top to bottom and from left to right
+// throws NoCodeError if there are none
+func Scan(img image.Image) ([]Code, error) {
+	b := binarize(img)
+	finders := b.findFinders()
+
+	codes := make([]Code, 0)
+	used := make([]bool, len(finders))
+	for _, g := range guessCorners(finders) {
+		if used[g.topLeft] || used[g.topRight] || used[g.bottomLeft] {
+			continue
+		}
+		code, ok := b.read(finders[g.topLeft], finders[g.topRight], finders[g.bottomLeft])
+		if !ok {
+			continue
+		}
+
+		used[g.topLeft], used[g.topRight], used[g.bottomLeft] = true, true, true
+		code.X += float64(img.Bounds().Min.X)
+		code.Y += float64(img.Bounds().Min.Y)
+		codes = append(codes, code)
+	}
+	if len(codes) == 0 {
+		return nil, NoCodeError
+	}
+
+	sort.SliceStable(codes, func(i, j int) bool {
+		// codes of the same row may be a bit off
+		if math.Abs(codes[i].Y-codes[j].Y) > 7*max(codes[i].module, codes[j].module) {
+			return codes[i].Y < codes[j].Y
+		}
+		return codes[i].X < codes[j].X
+	})
+	return codes, nil
+}
@@ -0,0 +1,130 @@
+package scan
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+	"github.com/rinnothing/qr-tools/render"
+)
+
+// testImage renders text encoded into chosen version with level M
+func testImage(t *testing.T, ver qr_tools.QRVersion, text string, scale int) *image.RGBA {
+	t.Helper()
+	modules, err := qr_tools.Encode([]qr_tools.Segment{qr_tools.NewByteSegment([]byte(text))}, qr_tools.M, ver)
+	if err != nil {
+		t.Fatalf("Failed to encode %q: %v", text, err)
+	}
+	opts := render.DefaultOptions()
+	opts.Scale = scale
+	img, err := render.Image(modules, opts)
+	if err != nil {
+		t.Fatalf("Failed to render %q: %v", text, err)
+	}
+	return img
+}
+
+// rotate returns the image turned by 90 degrees clockwise
+func rotate(img *image.RGBA) *image.RGBA {
+	b := img.Bounds()
+	rotated := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			rotated.Set(b.Dy()-1-y, x, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return rotated
+}
+
+// payloads scans the image and returns payloads of the codes found
+func payloads(t *testing.T, img image.Image) []string {
+	t.Helper()
+	codes, err := Scan(img)
+	if err != nil {
+		t.Fatalf("Failed to scan: %v", err)
+	}
+
+	result := make([]string, len(codes))
+	for i, c := range codes {
+		p, err := c.Payload()
+		if err != nil {
+			t.Fatalf("Failed to unmarshal code %d: %v", i, err)
+		}
+		result[i] = string(p)
+	}
+	return result
+}
+
+func TestScan(t *testing.T) {
+	cases := []struct {
+		name  string
+		ver   qr_tools.QRVersion
+		scale int
+		turns int
+	}{
+		{"smallest", 1, 1, 0},
+		{"scaled", 2, 5, 0},
+		{"rotated", 3, 3, 1},
+		{"upside down", 4, 2, 2},
+		{"alignment patterns", 10, 3, 3},
+	}
+
+	for _, c := range cases {
+		img := testImage(t, c.ver, "qr-tools", c.scale)
+		for i := 0; i < c.turns; i++ {
+			img = rotate(img)
+		}
+
+		got := payloads(t, img)
+		if len(got) != 1 || got[0] != "qr-tools" {
+			t.Errorf("Case %q is scanned as %q", c.name, got)
+		}
+	}
+}
+
+func TestScan_Version(t *testing.T) {
+	codes, err := Scan(testImage(t, 7, "version", 2))
+	if err != nil {
+		t.Fatalf("Failed to scan: %v", err)
+	}
+	if codes[0].Version != 7 || codes[0].Level != qr_tools.M || len(codes[0].Modules) != 45 {
+		t.Errorf("Got version %d, level %d and %d modules instead of 7, %d and 45",
+			codes[0].Version, codes[0].Level, len(codes[0].Modules), qr_tools.M)
+	}
+}
+
+func TestScan_Many(t *testing.T) {
+	page := image.NewRGBA(image.Rect(0, 0, 400, 400))
+	draw.Draw(page, page.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	texts := []string{"first", "second", "third", "fourth"}
+	for i, text := range texts {
+		img := testImage(t, qr_tools.QRVersion(i+1), text, 4)
+		at := image.Pt(200*(i%2)+10, 200*(i/2)+5)
+		draw.Draw(page, img.Bounds().Add(at), img, image.Point{}, draw.Src)
+	}
+
+	got := payloads(t, page)
+	if len(got) != len(texts) {
+		t.Fatalf("Found %d codes instead of %d", len(got), len(texts))
+	}
+	for i := range texts {
+		if got[i] != texts[i] {
+			t.Errorf("Code %d is %q instead of %q", i, got[i], texts[i])
+		}
+	}
+}
+
+func TestScan_NoCode(t *testing.T) {
+	blank := image.NewGray(image.Rect(0, 0, 100, 100))
+	for i := range blank.Pix {
+		blank.Pix[i] = uint8(i * 7)
+	}
+
+	if _, err := Scan(blank); !errors.Is(err, NoCodeError) {
+		t.Errorf("Got %v instead of NoCodeError", err)
+	}
+}
@@ -0,0 +1,242 @@
+package qr_tools
+
+import (
+	"errors"
+)
+
+var (
+	wrongModeError   = errors.New("unknown segment mode")
+	countTooBigError = errors.New("character count doesn't fit into character count indicator")
+	wrongLevelError  = errors.New("wrong error correction level")
+)
+
+// Mode is enum that
+// shows how the data of the segment is encoded (values are equal to mode indicators)
+type Mode uint
+
+const (
+	NumericMode      Mode = 0b0001
+	AlphanumericMode Mode = 0b0010
+	ByteMode         Mode = 0b0100
+)
+
+// Segment is a piece of data encoded with one Mode
+//
+// Count is the number of characters in Payload, for all the supported modes it's equal to len(Payload)
+type Segment struct {
+	Mode    Mode
+	Payload []byte
+	Count   int
+}
+
+// NewNumericSegment returns numeric Segment
+// throws wrongFormatError if str isn't numeric
+func NewNumericSegment(str string) (Segment, error) {
+	if !isNumeric(str) {
+		return Segment{}, wrongFormatError
+	}
+	return Segment{Mode: NumericMode, Payload: []byte(str), Count: len(str)}, nil
+}
+
+// NewAlphanumericSegment returns alphanumeric Segment
+// throws wrongFormatError if str isn't alphanumeric
+func NewAlphanumericSegment(str string) (Segment, error) {
+	if !isAlphaNumeric(str) {
+		return Segment{}, wrongFormatError
+	}
+	return Segment{Mode: AlphanumericMode, Payload: []byte(str), Count: len(str)}, nil
+}
+
+// NewByteSegment returns byte Segment
+func NewByteSegment(data []byte) Segment {
+	return Segment{Mode: ByteMode, Payload: data, Count: len(data)}
+}
+
+// bitCounts validates segment and returns sizes of character count indicator of its Mode
+func (s Segment) bitCounts() ([3]uint, error) {
+	if s.Count != len(s.Payload) {
+		return [3]uint{}, wrongFormatError
+	}
+
+	switch s.Mode {
+	case NumericMode:
+		if !isNumeric(string(s.Payload)) {
+			return [3]uint{}, wrongFormatError
+		}
+		return numericBitCounts, nil
+	case AlphanumericMode:
+		if !isAlphaNumeric(string(s.Payload)) {
+			return [3]uint{}, wrongFormatError
+		}
+		return alphanumericBitCounts, nil
+	case ByteMode:
+		return byteBitCounts, nil
+	default:
+		return [3]uint{}, wrongModeError
+	}
+}
+
+// BitLength returns the exact number of bits the segment takes in chosen version
+// including mode indicator and character count indicator
+func (s Segment) BitLength(ver QRVersion) (uint, error) {
+	bitCounts, err := s.bitCounts()
+	if err != nil {
+		return 0, err
+	}
+	cntSize, err := characterCountBits(bitCounts, ver)
+	if err != nil {
+		return 0, err
+	}
+	if s.Count >= 1<<cntSize {
+		return 0, countTooBigError
+	}
+
+	n := uint(s.Count)
+	bits := 4 + cntSize
+	switch s.Mode {
+	case NumericMode:
+		// 10 bits for each triplet, 4 or 7 bits for the remainder
+		bits += 10*(n/3) + [3]uint{0, 4, 7}[n%3]
+	case AlphanumericMode:
+		// 11 bits for each duo, 6 bits for the remainder
+		bits += 11*(n/2) + 6*(n%2)
+	case ByteMode:
+		bits += 8 * n
+	}
+
+	return bits, nil
+}
+
+// encode appends the segment to ba
+// segment should be already validated with BitLength
+func (s Segment) encode(ba *BitWriter, ver QRVersion) error {
+	switch s.Mode {
+	case NumericMode:
+		return encodeNumeric(ba, ver, string(s.Payload))
+	case AlphanumericMode:
+		return encodeAlphanumeric(ba, ver, string(s.Payload))
+	case ByteMode:
+		return encodeByte(ba, ver, string(s.Payload))
+	default:
+		return wrongModeError
+	}
+}
+
+// SegmentsBitLength returns the exact number of bits segments take in chosen version (without padding)
+func SegmentsBitLength(segments []Segment, ver QRVersion) (uint, error) {
+	var bits uint
+	for _, s := range segments {
+		n, err := s.BitLength(ver)
+		if err != nil {
+			return 0, err
+		}
+		bits += n
+	}
+
+	return bits, nil
+}
+
+// EncodeSegments encodes segments one after another into a single bitstream
+// and pads it to the capacity of chosen version and ErrorCorrectionLevel
+// throws dataTooLongError if segments don't fit
+func EncodeSegments(segments []Segment, lvl ErrorCorrectionLevel, ver QRVersion) ([]byte, error) {
+	if ver < 1 || ver > 40 {
+		return nil, wrongQRVersionError
+	}
+	if lvl > H {
+		return nil, wrongLevelError
+	}
+
+	bits, err := SegmentsBitLength(segments, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	bitsNum := codewordsCapacities[lvl][ver-1] * 8
+	if bits > bitsNum {
+		return nil, dataTooLongError
+	}
+
+	ba := NewBitWriter()
+	for _, s := range segments {
+		if err := s.encode(ba, ver); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := addPadding(ba, bitsNum); err != nil {
+		return nil, err
+	}
+
+	return ba.getData(), nil
+}
+
+// fits tells if segments fit into chosen version and ErrorCorrectionLevel
+func fits(segments []Segment, lvl ErrorCorrectionLevel, ver QRVersion) (bool, error) {
+	if lvl > H {
+		return false, wrongLevelError
+	}
+
+	bits, err := SegmentsBitLength(segments, ver)
+	if errors.Is(err, countTooBigError) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return bits <= codewordsCapacities[lvl][ver-1]*8, nil
+}
+
+// SmallestVersion returns the smallest QRVersion segments fit into with chosen ErrorCorrectionLevel
+// throws dataTooLongError if they don't fit even into version 40
+func SmallestVersion(segments []Segment, lvl ErrorCorrectionLevel) (QRVersion, error) {
+	if lvl > H {
+		return 0, wrongLevelError
+	}
+
+	for ver := QRVersion(1); ver <= 40; ver++ {
+		ok, err := fits(segments, lvl, ver)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return ver, nil
+		}
+	}
+
+	return 0, dataTooLongError
+}
+
+// BestLevel returns the highest ErrorCorrectionLevel segments still fit into with chosen QRVersion
+// throws dataTooLongError if they don't fit even with level L
+func BestLevel(segments []Segment, ver QRVersion) (ErrorCorrectionLevel, error) {
+	if ver < 1 || ver > 40 {
+		return 0, wrongQRVersionError
+	}
+
+	for lvl := ErrorCorrectionLevel(H); ; lvl-- {
+		ok, err := fits(segments, lvl, ver)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return lvl, nil
+		}
+		if lvl == L {
+			return 0, dataTooLongError
+		}
+	}
+}
+
+// DataCapacity returns the number of data bits chosen version and ErrorCorrectionLevel can hold
+// throws wrongQRVersionError and wrongLevelError
+func DataCapacity(lvl ErrorCorrectionLevel, ver QRVersion) (uint, error) {
+	if ver < 1 || ver > 40 {
+		return 0, wrongQRVersionError
+	}
+	if lvl > H {
+		return 0, wrongLevelError
+	}
+
+	return codewordsCapacities[lvl][ver-1] * 8, nil
+}
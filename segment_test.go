@@ -0,0 +1,180 @@
+package qr_tools
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewSegments(t *testing.T) {
+	if _, err := NewNumericSegment("12a"); !errors.Is(err, wrongFormatError) {
+		t.Errorf("Not numeric segment is created")
+	}
+	if _, err := NewAlphanumericSegment("abc"); !errors.Is(err, wrongFormatError) {
+		t.Errorf("Not alphanumeric segment is created")
+	}
+
+	s, err := NewNumericSegment("0123")
+	if err != nil || s.Mode != NumericMode || s.Count != 4 || string(s.Payload) != "0123" {
+		t.Errorf("Numeric segment is created wrong: %v", s)
+	}
+	s = NewByteSegment([]byte{0, 1, 2})
+	if s.Mode != ByteMode || s.Count != 3 {
+		t.Errorf("Byte segment is created wrong: %v", s)
+	}
+}
+
+func TestSegment_BitLength(t *testing.T) {
+	numeric, _ := NewNumericSegment("01234567")
+	alphanumeric, _ := NewAlphanumericSegment("HELLO WORLD")
+	byteSeg := NewByteSegment([]byte("Hello"))
+
+	cases := []struct {
+		s    Segment
+		ver  QRVersion
+		bits uint
+	}{
+		{numeric, 1, 4 + 10 + 10*2 + 7},
+		{numeric, 10, 4 + 12 + 10*2 + 7},
+		{alphanumeric, 1, 4 + 9 + 11*5 + 6},
+		{alphanumeric, 27, 4 + 13 + 11*5 + 6},
+		{byteSeg, 1, 4 + 8 + 8*5},
+		{byteSeg, 40, 4 + 16 + 8*5},
+	}
+
+	for _, c := range cases {
+		bits, err := c.s.BitLength(c.ver)
+		if err != nil {
+			t.Errorf("Failed to count bits of %s: %v", c.s.Payload, err)
+			continue
+		}
+		if bits != c.bits {
+			t.Errorf("Segment %s takes %d bits in version %d, but %d were counted", c.s.Payload, c.bits, c.ver, bits)
+		}
+
+		ba := NewBitWriter()
+		_ = c.s.encode(ba, c.ver)
+		if ba.n != bits {
+			t.Errorf("Segment %s is encoded with %d bits, but %d were counted", c.s.Payload, ba.n, bits)
+		}
+	}
+
+	if _, err := NewByteSegment(make([]byte, 256)).BitLength(1); !errors.Is(err, countTooBigError) {
+		t.Errorf("Too long segment doesn't give an error")
+	}
+	if _, err := (Segment{Mode: NumericMode, Payload: []byte("12"), Count: 3}).BitLength(1); err == nil {
+		t.Errorf("Segment with wrong count doesn't give an error")
+	}
+	if _, err := (Segment{Mode: 0b1000, Payload: []byte("12"), Count: 2}).BitLength(1); !errors.Is(err, wrongModeError) {
+		t.Errorf("Segment with unknown mode doesn't give an error")
+	}
+}
+
+func TestEncodeSegments(t *testing.T) {
+	lvl := ErrorCorrectionLevel(M)
+	var ver QRVersion = 2
+
+	numeric, _ := NewNumericSegment("8675309")
+	byteSeg := NewByteSegment([]byte("Hello"))
+
+	data, err := EncodeSegments([]Segment{numeric, byteSeg}, lvl, ver)
+	if err != nil {
+		t.Fatalf("Failed to encode segments: %v", err)
+	}
+
+	ba := NewBitWriter()
+	_ = encodeNumeric(ba, ver, "8675309")
+	_ = encodeByte(ba, ver, "Hello")
+	addPadding(ba, codewordsCapacities[lvl][ver-1]*8)
+
+	if !bytes.Equal(data, ba.getData()) {
+		t.Errorf("Segments aren't encoded properly")
+	}
+
+	single, _ := NewNumericMarshaler(lvl, ver).MarshalString("8675309")
+	data, _ = EncodeSegments([]Segment{numeric}, lvl, ver)
+	if !bytes.Equal(data, single) {
+		t.Errorf("Single segment is encoded differently from NumericMarshaler")
+	}
+
+	if _, err := EncodeSegments([]Segment{NewByteSegment(make([]byte, 100))}, lvl, 1); !errors.Is(err, dataTooLongError) {
+		t.Errorf("Too long data doesn't give an error")
+	}
+	if _, err := EncodeSegments(nil, lvl, 41); !errors.Is(err, wrongQRVersionError) {
+		t.Errorf("Wrong version doesn't give an error")
+	}
+	if _, err := EncodeSegments(nil, H+1, ver); !errors.Is(err, wrongLevelError) {
+		t.Errorf("Wrong level doesn't give an error")
+	}
+}
+
+func TestSmallestVersion(t *testing.T) {
+	// 41 digits is the capacity of version 1-L
+	s, _ := NewNumericSegment(strings.Repeat("1", 41))
+	if ver, err := SmallestVersion([]Segment{s}, L); err != nil || ver != 1 {
+		t.Errorf("Smallest version is %d instead of 1: %v", ver, err)
+	}
+
+	s, _ = NewNumericSegment(strings.Repeat("1", 42))
+	if ver, err := SmallestVersion([]Segment{s}, L); err != nil || ver != 2 {
+		t.Errorf("Smallest version is %d instead of 2: %v", ver, err)
+	}
+
+	for lvl := ErrorCorrectionLevel(L); lvl <= H; lvl++ {
+		for ver := QRVersion(1); ver <= 40; ver += 13 {
+			s := NewByteSegment(make([]byte, byteCapacities[lvl][ver-1]))
+			if got, err := SmallestVersion([]Segment{s}, lvl); err != nil || got != ver {
+				t.Errorf("Byte capacity of version %d is %d, but smallest version is %d: %v", ver, byteCapacities[lvl][ver-1], got, err)
+			}
+		}
+	}
+
+	if _, err := SmallestVersion([]Segment{NewByteSegment(make([]byte, 3000))}, L); !errors.Is(err, dataTooLongError) {
+		t.Errorf("Too long data doesn't give an error")
+	}
+	if _, err := SmallestVersion(nil, H+1); !errors.Is(err, wrongLevelError) {
+		t.Errorf("Wrong level doesn't give an error")
+	}
+}
+
+func TestBestLevel(t *testing.T) {
+	for lvl := ErrorCorrectionLevel(L); lvl <= H; lvl++ {
+		s, _ := NewAlphanumericSegment(strings.Repeat("A", int(alphanumericCapacities[lvl][4])))
+		if got, err := BestLevel([]Segment{s}, 5); err != nil || got != lvl {
+			t.Errorf("Best level is %d instead of %d: %v", got, lvl, err)
+		}
+	}
+
+	if _, err := BestLevel([]Segment{NewByteSegment(make([]byte, 18))}, 1); !errors.Is(err, dataTooLongError) {
+		t.Errorf("Too long data doesn't give an error")
+	}
+	if _, err := BestLevel(nil, 0); !errors.Is(err, wrongQRVersionError) {
+		t.Errorf("Wrong version doesn't give an error")
+	}
+}
+
+func TestDataCapacity(t *testing.T) {
+	cases := []struct {
+		lvl      ErrorCorrectionLevel
+		ver      QRVersion
+		expected uint
+	}{
+		{L, 1, 152},
+		{H, 1, 72},
+		{M, 40, 18672},
+	}
+
+	for _, c := range cases {
+		if bits, err := DataCapacity(c.lvl, c.ver); err != nil || bits != c.expected {
+			t.Errorf("Capacity of version %d with level %d is %d instead of %d: %v", c.ver, c.lvl, bits, c.expected, err)
+		}
+	}
+
+	if _, err := DataCapacity(L, 41); err == nil {
+		t.Errorf("Capacity of version 41 is returned without error")
+	}
+	if _, err := DataCapacity(H+1, 1); !errors.Is(err, wrongLevelError) {
+		t.Errorf("Capacity of wrong level gives %v instead of %v", err, wrongLevelError)
+	}
+}
@@ -0,0 +1,206 @@
+// Package shamir splits secrets into shares with Shamir's secret sharing over GF(256)
+// every share is put into its own QR code, any threshold of them restore the secret
+package shamir
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+	"github.com/rinnothing/qr-tools/base45"
+	"github.com/rinnothing/qr-tools/internal/gf256"
+)
+
+var (
+	WrongParametersError = errors.New("wrong number of shares or threshold")
+	WrongFormatError     = errors.New("data is not a share")
+	ChecksumError        = errors.New("share checksum doesn't match")
+	NotEnoughSharesError = errors.New("not enough shares to restore the secret")
+	MixedSharesError     = errors.New("shares belong to different secrets")
+)
+
+// Prefix starts every share in text form
+const Prefix = "SSS1:"
+
+// headerSize is the size of set id, index, threshold and count
+const headerSize = 4 + 3
+
+// Share is a single share of the secret
+// Index is the x coordinate of the share, it starts with 1
+type Share struct {
+	// SetID is random and is the same for all the shares of a secret
+	SetID     [4]byte
+	Index     byte
+	Threshold byte
+	Count     byte
+	Data      []byte
+}
+
+// Label returns human readable caption to print next to the code
+func (s Share) Label() string {
+	return fmt.Sprintf("share %d of %d (%d needed), set %X", s.Index, s.Count, s.Threshold, s.SetID)
+}
+
+// MarshalBinary returns header, data and CRC-32 of them
+func (s Share) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, headerSize+len(s.Data)+4)
+	data = append(data, s.SetID[:]...)
+	data = append(data, s.Index, s.Threshold, s.Count)
+	data = append(data, s.Data...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(data)), nil
+}
+
+// ParseShare parses share made by MarshalBinary
+// throws ChecksumError if share is damaged
+func ParseShare(data []byte) (Share, error) {
+	if len(data) < headerSize+4 {
+		return Share{}, WrongFormatError
+	}
+
+	body := data[:len(data)-4]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(body):]) {
+		return Share{}, ChecksumError
+	}
+
+	s := Share{Index: body[4], Threshold: body[5], Count: body[6], Data: body[headerSize:]}
+	copy(s.SetID[:], body[:4])
+	if s.Index == 0 || s.Threshold == 0 || s.Threshold > s.Count {
+		return Share{}, WrongFormatError
+	}
+	return s, nil
+}
+
+// String returns share encoded with base45 after Prefix, so it fits alphanumeric mode
+func (s Share) String() string {
+	data, _ := s.MarshalBinary()
+	return Prefix + base45.Encode(data)
+}
+
+// ParseShareString parses share made by String
+func ParseShareString(str string) (Share, error) {
+	str, found := strings.CutPrefix(str, Prefix)
+	if !found {
+		return Share{}, WrongFormatError
+	}
+
+	data, err := base45.Decode(str)
+	if err != nil {
+		return Share{}, WrongFormatError
+	}
+	return ParseShare(data)
+}
+
+// Marshal marshals share with AlphanumericMarshaler
+func (s Share) Marshal(lvl qr_tools.ErrorCorrectionLevel, ver qr_tools.QRVersion) ([]byte, error) {
+	return qr_tools.NewAlphanumericMarshaler(lvl, ver).MarshalString(s.String())
+}
+
+// Unmarshal unmarshals share made by Share.Marshal
+func Unmarshal(data []byte, ver qr_tools.QRVersion) (Share, error) {
+	str, err := qr_tools.NewQRUnmarshaler(ver).UnmarshalToString(data)
+	if err != nil {
+		return Share{}, err
+	}
+	return ParseShareString(str)
+}
+
+// Split splits secret into count shares, any threshold of them restore it
+// threshold must be from 1 to count, count must be at most 255
+func Split(secret []byte, count, threshold int) ([]Share, error) {
+	if threshold < 1 || threshold > count || count > 255 {
+		return nil, WrongParametersError
+	}
+
+	var setID [4]byte
+	if _, err := rand.Read(setID[:]); err != nil {
+		return nil, err
+	}
+
+	shares := make([]Share, count)
+	for i := range shares {
+		shares[i] = Share{
+			SetID:     setID,
+			Index:     byte(i + 1),
+			Threshold: byte(threshold),
+			Count:     byte(count),
+			Data:      make([]byte, len(secret)),
+		}
+	}
+
+	// every byte of the secret is the constant term of its own random polynomial
+	coefficients := make([]byte, threshold)
+	for pos, b := range secret {
+		coefficients[0] = b
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+
+		for i := range shares {
+			shares[i].Data[pos] = evaluate(coefficients, shares[i].Index)
+		}
+	}
+
+	return shares, nil
+}
+
+// evaluate evaluates polynomial at x with Horner's method
+func evaluate(coefficients []byte, x byte) byte {
+	var y byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = gf256.Add(gf256.Mul(y, x), coefficients[i])
+	}
+	return y
+}
+
+// Combine restores the secret from at least threshold shares
+// repeated shares are counted once
+// throws WrongFormatError if threshold is 0 or greater than count
+func Combine(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, NotEnoughSharesError
+	}
+
+	first := shares[0]
+	if first.Threshold == 0 || first.Threshold > first.Count {
+		return nil, WrongFormatError
+	}
+	unique := make([]Share, 0, len(shares))
+	seen := make(map[byte]bool)
+	for _, s := range shares {
+		if s.SetID != first.SetID || s.Threshold != first.Threshold || s.Count != first.Count || len(s.Data) != len(first.Data) {
+			return nil, MixedSharesError
+		}
+		if s.Index == 0 {
+			return nil, WrongFormatError
+		}
+		if !seen[s.Index] {
+			seen[s.Index] = true
+			unique = append(unique, s)
+		}
+	}
+	if len(unique) < int(first.Threshold) {
+		return nil, NotEnoughSharesError
+	}
+	unique = unique[:first.Threshold]
+
+	// Lagrange interpolation at x = 0
+	secret := make([]byte, len(first.Data))
+	for i, si := range unique {
+		basis := byte(1)
+		for j, sj := range unique {
+			if i != j {
+				basis = gf256.Mul(basis, gf256.Div(sj.Index, gf256.Add(sj.Index, si.Index)))
+			}
+		}
+
+		for pos := range secret {
+			secret[pos] = gf256.Add(secret[pos], gf256.Mul(si.Data[pos], basis))
+		}
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,111 @@
+package shamir
+
+import (
+	"bytes"
+	"testing"
+
+	qr_tools "github.com/rinnothing/qr-tools"
+)
+
+var testSecret = []byte("root key: 0123456789abcdef")
+
+func TestSplit(t *testing.T) {
+	shares, err := Split(testSecret, 5, 3)
+	if err != nil {
+		t.Fatalf("Failed to split: %v", err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("Secret is split into %d shares instead of 5", len(shares))
+	}
+
+	combinations := [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}, {3, 3, 1, 0}}
+	for _, combination := range combinations {
+		chosen := make([]Share, 0)
+		for _, i := range combination {
+			chosen = append(chosen, shares[i])
+		}
+
+		secret, err := Combine(chosen)
+		if err != nil || !bytes.Equal(secret, testSecret) {
+			t.Errorf("Shares %v are combined into %q: %v", combination, secret, err)
+		}
+	}
+
+	// two shares give nothing, even repeated ones
+	if _, err := Combine([]Share{shares[0], shares[1], shares[1]}); err != NotEnoughSharesError {
+		t.Errorf("Not enough shares are combined with %v", err)
+	}
+
+	other, _ := Split(testSecret, 5, 3)
+	if _, err := Combine([]Share{shares[0], shares[1], other[2]}); err != MixedSharesError {
+		t.Errorf("Shares of different secrets are combined with %v", err)
+	}
+
+	// shares made by hand are checked like parsed ones
+	for _, broken := range []func(s *Share){
+		func(s *Share) { s.Threshold = 0 },
+		func(s *Share) { s.Threshold = s.Count + 1 },
+	} {
+		chosen := []Share{shares[0], shares[1], shares[2]}
+		for i := range chosen {
+			broken(&chosen[i])
+		}
+		if _, err := Combine(chosen); err != WrongFormatError {
+			t.Errorf("Shares with threshold %d of %d are combined with %v", chosen[0].Threshold, chosen[0].Count, err)
+		}
+	}
+
+	for _, p := range [][2]int{{3, 0}, {3, 4}, {256, 2}} {
+		if _, err := Split(testSecret, p[0], p[1]); err != WrongParametersError {
+			t.Errorf("Wrong parameters %v are accepted with %v", p, err)
+		}
+	}
+}
+
+func TestShare_String(t *testing.T) {
+	shares, _ := Split(testSecret, 3, 2)
+
+	for _, s := range shares {
+		str := s.String()
+		if _, err := qr_tools.NewAlphanumericSegment(str); err != nil {
+			t.Errorf("Share %q doesn't fit alphanumeric mode", str)
+		}
+
+		parsed, err := ParseShareString(str)
+		if err != nil || parsed.Index != s.Index || parsed.SetID != s.SetID || !bytes.Equal(parsed.Data, s.Data) {
+			t.Errorf("Parsed %v instead of %v: %v", parsed, s, err)
+		}
+	}
+
+	data, _ := shares[0].MarshalBinary()
+	data[headerSize] ^= 1
+	if _, err := ParseShare(data); err != ChecksumError {
+		t.Errorf("Damaged share is parsed with %v", err)
+	}
+
+	if _, err := ParseShareString("HC1:" + shares[0].String()[len(Prefix):]); err != WrongFormatError {
+		t.Errorf("Share without prefix is parsed with %v", err)
+	}
+}
+
+func TestShare_Marshal(t *testing.T) {
+	shares, _ := Split(testSecret, 3, 2)
+
+	decoded := make([]Share, 0)
+	for _, s := range shares[1:] {
+		data, err := s.Marshal(qr_tools.M, 4)
+		if err != nil {
+			t.Fatalf("Failed to marshal: %v", err)
+		}
+
+		u, err := Unmarshal(data, 4)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal: %v", err)
+		}
+		decoded = append(decoded, u)
+	}
+
+	if secret, err := Combine(decoded); err != nil || !bytes.Equal(secret, testSecret) {
+		t.Errorf("Unmarshaled shares are combined into %q: %v", secret, err)
+	}
+}
@@ -0,0 +1,196 @@
+package qr_tools
+
+import (
+	"errors"
+	"strconv"
+)
+
+var (
+	corruptedDataError = errors.New("data is corrupted")
+)
+
+const (
+	// alphanumeric chars ordered by their codes
+	alphanumericChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+	terminatorMode   = 0b0000
+	fnc1FirstMode    = 0b0101
+	fnc1SecondMode   = 0b1001
+	modeIndicatorLen = 4
+)
+
+// Unmarshaler is the interface implemented by types that
+// can unmarshal a string or raw payload from a sequence of bytes
+type Unmarshaler interface {
+	UnmarshalToString(data []byte) (str string, err error)
+	UnmarshalToBytes(data []byte) (payload []byte, err error)
+}
+
+// A QRUnmarshaler can unmarshal data made by any marshaler
+// of chosen QRVersion
+//
+// in FNC1 data % in alphanumeric segments is turned back into GroupSeparator
+type QRUnmarshaler struct {
+	ver QRVersion
+}
+
+// NewQRUnmarshaler returns QRUnmarshaler
+// with chosen QRVersion
+func NewQRUnmarshaler(ver QRVersion) *QRUnmarshaler {
+	return &QRUnmarshaler{ver: ver}
+}
+
+// UnmarshalToString unmarshals data into string
+func (qu *QRUnmarshaler) UnmarshalToString(data []byte) (string, error) {
+	payload, err := qu.UnmarshalToBytes(data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(payload), nil
+}
+
+// UnmarshalToBytes unmarshals data into payload
+// byte segments are returned unchanged, so any binary data roundtrips
+func (qu *QRUnmarshaler) UnmarshalToBytes(data []byte) ([]byte, error) {
+	br := NewBitReader(data)
+	payload := make([]byte, 0, len(data))
+	fnc1 := false
+
+	// segments go one after another until terminator or the end of data
+	for br.Remaining() >= modeIndicatorLen {
+		mode, _ := br.ReadBits(modeIndicatorLen)
+
+		var err error
+		switch mode {
+		case terminatorMode:
+			return payload, nil
+		case fnc1FirstMode:
+			fnc1 = true
+		case fnc1SecondMode:
+			fnc1 = true
+			// application indicator isn't part of payload
+			_, err = br.ReadBits(8)
+		case uint64(NumericMode):
+			payload, err = decodeNumeric(br, qu.ver, payload)
+		case uint64(AlphanumericMode):
+			payload, err = decodeAlphanumeric(br, qu.ver, payload, fnc1)
+		case uint64(ByteMode):
+			payload, err = decodeByte(br, qu.ver, payload)
+		default:
+			return nil, wrongModeError
+		}
+
+		if errors.Is(err, bitOverrunError) {
+			return nil, corruptedDataError
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	return payload, nil
+}
+
+// readCharacterCount reads character count indicator
+func readCharacterCount(bitCounts [3]uint, br *BitReader, ver QRVersion) (int, error) {
+	cntSize, err := characterCountBits(bitCounts, ver)
+	if err != nil {
+		return 0, err
+	}
+
+	cnt, err := br.ReadBits(cntSize)
+	return int(cnt), err
+}
+
+// decodeNumeric reads numeric segment (after mode indicator) and appends it to payload
+func decodeNumeric(br *BitReader, ver QRVersion, payload []byte) ([]byte, error) {
+	cnt, err := readCharacterCount(numericBitCounts, br, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	for ; cnt > 0; cnt -= 3 {
+		digits := min(cnt, 3)
+		value, err := br.ReadBits(uint(1 + 3*digits))
+		if err != nil {
+			return nil, err
+		}
+
+		piece := strconv.FormatUint(value, 10)
+		if len(piece) > digits {
+			return nil, corruptedDataError
+		}
+		for i := len(piece); i < digits; i++ {
+			payload = append(payload, '0')
+		}
+		payload = append(payload, piece...)
+	}
+
+	return payload, nil
+}
+
+// decodeAlphanumeric reads alphanumeric segment (after mode indicator) and appends it to payload
+// if fnc1 is set % is turned into GroupSeparator and %% into %
+func decodeAlphanumeric(br *BitReader, ver QRVersion, payload []byte, fnc1 bool) ([]byte, error) {
+	cnt, err := readCharacterCount(alphanumericBitCounts, br, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	segment := make([]byte, 0, cnt)
+	for ; cnt > 0; cnt -= 2 {
+		if cnt == 1 {
+			value, err := br.ReadBits(6)
+			if err != nil {
+				return nil, err
+			}
+			if value >= 45 {
+				return nil, corruptedDataError
+			}
+			segment = append(segment, alphanumericChars[value])
+			break
+		}
+
+		value, err := br.ReadBits(11)
+		if err != nil {
+			return nil, err
+		}
+		if value >= 45*45 {
+			return nil, corruptedDataError
+		}
+		segment = append(segment, alphanumericChars[value/45], alphanumericChars[value%45])
+	}
+
+	if !fnc1 {
+		return append(payload, segment...), nil
+	}
+
+	for i := 0; i < len(segment); i++ {
+		switch {
+		case segment[i] == '%' && i+1 < len(segment) && segment[i+1] == '%':
+			payload = append(payload, '%')
+			i++
+		case segment[i] == '%':
+			payload = append(payload, GroupSeparator)
+		default:
+			payload = append(payload, segment[i])
+		}
+	}
+
+	return payload, nil
+}
+
+// decodeByte reads byte segment (after mode indicator) and appends it to payload
+func decodeByte(br *BitReader, ver QRVersion, payload []byte) ([]byte, error) {
+	cnt, err := readCharacterCount(byteBitCounts, br, ver)
+	if err != nil {
+		return nil, err
+	}
+
+	segment, err := br.ReadBytes(cnt)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(payload, segment...), nil
+}
@@ -0,0 +1,123 @@
+package qr_tools
+
+import (
+	"bytes"
+	cryptoRand "crypto/rand"
+	"errors"
+	"testing"
+)
+
+func TestNewQRUnmarshaler(t *testing.T) {
+	var ver QRVersion = 10
+
+	qu := NewQRUnmarshaler(ver)
+	if qu.ver != ver {
+		t.Errorf("NewQRUnmarshaler's arguments are wrong")
+	}
+}
+
+func TestQRUnmarshaler_UnmarshalToString(t *testing.T) {
+	lvl := ErrorCorrectionLevel(M)
+	for _, ver := range []QRVersion{1, 10, 27} {
+		qu := NewQRUnmarshaler(ver)
+
+		for _, s := range []string{"", "8675309", "0012", "007", "HELLO WORLD", "BABA 1234.++", "Hello, world!", "Привет"} {
+			for _, m := range []Marshaler{NewNumericMarshaler(lvl, ver), NewAlphanumericMarshaler(lvl, ver), NewByteMarshaler(lvl, ver), NewQRMarshaler(lvl, ver)} {
+				data, err := m.MarshalString(s)
+				if err != nil {
+					continue
+				}
+
+				unmarshaled, err := qu.UnmarshalToString(data)
+				if err != nil {
+					t.Errorf("Failed to unmarshal %q: %v", s, err)
+				} else if unmarshaled != s {
+					t.Errorf("Unmarshaled %q instead of %q", unmarshaled, s)
+				}
+			}
+		}
+	}
+}
+
+func TestQRUnmarshaler_UnmarshalToBytes(t *testing.T) {
+	lvl := ErrorCorrectionLevel(L)
+	var ver QRVersion = 5
+
+	payload := make([]byte, 100)
+	if _, err := cryptoRand.Read(payload); err != nil {
+		t.Fatalf("Failed to generate random data %v", err)
+	}
+	// definitely not UTF-8
+	payload[0] = 0xff
+
+	qu := NewQRUnmarshaler(ver)
+	for _, m := range []Marshaler{NewByteMarshaler(lvl, ver), NewQRMarshaler(lvl, ver)} {
+		data, err := m.MarshalBytes(payload)
+		if err != nil {
+			t.Fatalf("Failed to marshal binary payload: %v", err)
+		}
+
+		unmarshaled, err := qu.UnmarshalToBytes(data)
+		if err != nil {
+			t.Errorf("Failed to unmarshal binary payload: %v", err)
+		} else if !bytes.Equal(unmarshaled, payload) {
+			t.Errorf("Binary payload changed after roundtrip")
+		}
+	}
+
+	numeric, _ := NewNumericSegment("0123")
+	alphanumeric, _ := NewAlphanumericSegment("AB")
+	data, _ := EncodeSegments([]Segment{numeric, NewByteSegment([]byte{0, 0xff}), alphanumeric}, lvl, ver)
+	if unmarshaled, err := qu.UnmarshalToBytes(data); err != nil || !bytes.Equal(unmarshaled, []byte("0123\x00\xffAB")) {
+		t.Errorf("Segments are unmarshaled wrong: %q, %v", unmarshaled, err)
+	}
+}
+
+func TestQRUnmarshaler_FNC1(t *testing.T) {
+	lvl := ErrorCorrectionLevel(L)
+	var ver QRVersion = 2
+
+	qu := NewQRUnmarshaler(ver)
+	for _, s := range []string{"0109506000134352", "10ABC%1\x1d2112", "10abc\x1d2112"} {
+		for _, fm := range []*FNC1Marshaler{NewFNC1FirstMarshaler(lvl, ver), NewFNC1SecondMarshaler(lvl, ver, 37)} {
+			data, err := fm.MarshalString(s)
+			if err != nil {
+				t.Fatalf("Failed to marshal %q: %v", s, err)
+			}
+
+			unmarshaled, err := qu.UnmarshalToString(data)
+			if err != nil {
+				t.Errorf("Failed to unmarshal %q: %v", s, err)
+			} else if unmarshaled != s {
+				t.Errorf("Unmarshaled %q instead of %q", unmarshaled, s)
+			}
+		}
+	}
+}
+
+func TestQRUnmarshaler_Errors(t *testing.T) {
+	qu := NewQRUnmarshaler(1)
+
+	// kanji mode isn't supported yet
+	if _, err := qu.UnmarshalToBytes([]byte{0b1000_0000}); !errors.Is(err, wrongModeError) {
+		t.Errorf("Unknown mode doesn't give an error")
+	}
+
+	// byte segment of 10 chars with just 1 byte of data
+	if _, err := qu.UnmarshalToBytes([]byte{0b0100_0000, 0b1010_0000, 0}); !errors.Is(err, corruptedDataError) {
+		t.Errorf("Cut data doesn't give an error")
+	}
+
+	// numeric triplet 1023
+	ba := NewBitWriter()
+	ba.WriteBits(uint64(NumericMode), 4)
+	ba.WriteBits(3, 10)
+	ba.WriteBits(1023, 10)
+	if _, err := qu.UnmarshalToBytes(ba.Bytes()); !errors.Is(err, corruptedDataError) {
+		t.Errorf("Wrong numeric data doesn't give an error")
+	}
+
+	if _, err := NewQRUnmarshaler(41).UnmarshalToBytes([]byte{0b0100_0000}); !errors.Is(err, wrongQRVersionError) {
+		t.Errorf("Wrong version doesn't give an error")
+	}
+}